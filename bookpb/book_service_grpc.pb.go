@@ -0,0 +1,261 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: book_service.proto
+
+package bookpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BookService_SearchByAuthor_FullMethodName    = "/booklearn.v1.BookService/SearchByAuthor"
+	BookService_SearchByTitle_FullMethodName     = "/booklearn.v1.BookService/SearchByTitle"
+	BookService_GetVolume_FullMethodName         = "/booklearn.v1.BookService/GetVolume"
+	BookService_StreamAuthorBooks_FullMethodName = "/booklearn.v1.BookService/StreamAuthorBooks"
+)
+
+// BookServiceClient is the client API for BookService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BookService searches Google Books for backend services. Results are
+// filtered, grouped into works and sorted the same way as the REST API.
+type BookServiceClient interface {
+	// SearchByAuthor fetches pages + 1 pages of an author's books and merges
+	// them into one result, newest first unless sort_by says otherwise.
+	SearchByAuthor(ctx context.Context, in *SearchByAuthorRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// SearchByTitle finds books whose title matches closely.
+	SearchByTitle(ctx context.Context, in *SearchByTitleRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// GetVolume looks up a single volume. Unknown IDs are NOT_FOUND.
+	GetVolume(ctx context.Context, in *GetVolumeRequest, opts ...grpc.CallOption) (*Book, error)
+	// StreamAuthorBooks sends an author's books as each page arrives and ends
+	// with a summary. Pages are sent in the order they arrive and a work is
+	// only sent once. If any page fails the stream ends with UNAVAILABLE
+	// after the books that could be fetched.
+	StreamAuthorBooks(ctx context.Context, in *SearchByAuthorRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamAuthorBooksResponse], error)
+}
+
+type bookServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBookServiceClient(cc grpc.ClientConnInterface) BookServiceClient {
+	return &bookServiceClient{cc}
+}
+
+func (c *bookServiceClient) SearchByAuthor(ctx context.Context, in *SearchByAuthorRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, BookService_SearchByAuthor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) SearchByTitle(ctx context.Context, in *SearchByTitleRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, BookService_SearchByTitle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) GetVolume(ctx context.Context, in *GetVolumeRequest, opts ...grpc.CallOption) (*Book, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Book)
+	err := c.cc.Invoke(ctx, BookService_GetVolume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) StreamAuthorBooks(ctx context.Context, in *SearchByAuthorRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamAuthorBooksResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BookService_ServiceDesc.Streams[0], BookService_StreamAuthorBooks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchByAuthorRequest, StreamAuthorBooksResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_StreamAuthorBooksClient = grpc.ServerStreamingClient[StreamAuthorBooksResponse]
+
+// BookServiceServer is the server API for BookService service.
+// All implementations must embed UnimplementedBookServiceServer
+// for forward compatibility.
+//
+// BookService searches Google Books for backend services. Results are
+// filtered, grouped into works and sorted the same way as the REST API.
+type BookServiceServer interface {
+	// SearchByAuthor fetches pages + 1 pages of an author's books and merges
+	// them into one result, newest first unless sort_by says otherwise.
+	SearchByAuthor(context.Context, *SearchByAuthorRequest) (*SearchResponse, error)
+	// SearchByTitle finds books whose title matches closely.
+	SearchByTitle(context.Context, *SearchByTitleRequest) (*SearchResponse, error)
+	// GetVolume looks up a single volume. Unknown IDs are NOT_FOUND.
+	GetVolume(context.Context, *GetVolumeRequest) (*Book, error)
+	// StreamAuthorBooks sends an author's books as each page arrives and ends
+	// with a summary. Pages are sent in the order they arrive and a work is
+	// only sent once. If any page fails the stream ends with UNAVAILABLE
+	// after the books that could be fetched.
+	StreamAuthorBooks(*SearchByAuthorRequest, grpc.ServerStreamingServer[StreamAuthorBooksResponse]) error
+	mustEmbedUnimplementedBookServiceServer()
+}
+
+// UnimplementedBookServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBookServiceServer struct{}
+
+func (UnimplementedBookServiceServer) SearchByAuthor(context.Context, *SearchByAuthorRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchByAuthor not implemented")
+}
+func (UnimplementedBookServiceServer) SearchByTitle(context.Context, *SearchByTitleRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchByTitle not implemented")
+}
+func (UnimplementedBookServiceServer) GetVolume(context.Context, *GetVolumeRequest) (*Book, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVolume not implemented")
+}
+func (UnimplementedBookServiceServer) StreamAuthorBooks(*SearchByAuthorRequest, grpc.ServerStreamingServer[StreamAuthorBooksResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAuthorBooks not implemented")
+}
+func (UnimplementedBookServiceServer) mustEmbedUnimplementedBookServiceServer() {}
+func (UnimplementedBookServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeBookServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BookServiceServer will
+// result in compilation errors.
+type UnsafeBookServiceServer interface {
+	mustEmbedUnimplementedBookServiceServer()
+}
+
+func RegisterBookServiceServer(s grpc.ServiceRegistrar, srv BookServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBookServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BookService_ServiceDesc, srv)
+}
+
+func _BookService_SearchByAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchByAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).SearchByAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_SearchByAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).SearchByAuthor(ctx, req.(*SearchByAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_SearchByTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchByTitleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).SearchByTitle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_SearchByTitle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).SearchByTitle(ctx, req.(*SearchByTitleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_GetVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).GetVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_GetVolume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).GetVolume(ctx, req.(*GetVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_StreamAuthorBooks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchByAuthorRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BookServiceServer).StreamAuthorBooks(m, &grpc.GenericServerStream[SearchByAuthorRequest, StreamAuthorBooksResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_StreamAuthorBooksServer = grpc.ServerStreamingServer[StreamAuthorBooksResponse]
+
+// BookService_ServiceDesc is the grpc.ServiceDesc for BookService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BookService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "booklearn.v1.BookService",
+	HandlerType: (*BookServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchByAuthor",
+			Handler:    _BookService_SearchByAuthor_Handler,
+		},
+		{
+			MethodName: "SearchByTitle",
+			Handler:    _BookService_SearchByTitle_Handler,
+		},
+		{
+			MethodName: "GetVolume",
+			Handler:    _BookService_GetVolume_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamAuthorBooks",
+			Handler:       _BookService_StreamAuthorBooks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "book_service.proto",
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+
+	model "example.com/book-learn/models"
+)
+
+const (
+	coAuthorEdgeWeight = 1.0
+	categoryEdgeWeight = 0.5
+)
+
+// AuthorGraph links authors who wrote books together or write in the same
+// categories. It is built up from volumes as they are seen, typically by
+// registering AddVolumes with Catalog.OnAdd and RemoveVolumes with
+// Catalog.OnEvict, and is safe for concurrent use.
+type AuthorGraph struct {
+	mu    sync.RWMutex
+	names map[string]string
+	// seen counts the volumes of each author. coAuthors counts the works two
+	// authors share, and categories and members the volumes linking authors
+	// and categories in each direction.
+	seen       map[string]int
+	coAuthors  map[string]map[string]int
+	categories map[string]map[string]int
+	members    map[string]map[string]int
+	// volumes is what each volume added, so it can be taken back out again.
+	volumes map[string]graphVolume
+	works   map[string]*graphWork
+}
+
+type graphVolume struct {
+	work       string
+	authors    []string
+	categories []string
+}
+
+// graphWork is a work with the authors its first edition credited and how
+// many of its editions are in the graph.
+type graphWork struct {
+	authors  []string
+	editions int
+}
+
+func NewAuthorGraph() *AuthorGraph {
+	return &AuthorGraph{
+		names:      map[string]string{},
+		seen:       map[string]int{},
+		coAuthors:  map[string]map[string]int{},
+		categories: map[string]map[string]int{},
+		members:    map[string]map[string]int{},
+		volumes:    map[string]graphVolume{},
+		works:      map[string]*graphWork{},
+	}
+}
+
+// AddVolumes records the authors and categories of each volume. Co-authored
+// books are counted once per work however many editions are seen, and a
+// volume ID that is already in the graph is ignored.
+func (g *AuthorGraph) AddVolumes(items []model.GoogleBookItem) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, item := range items {
+		if _, ok := g.volumes[item.ID]; ok && item.ID != "" {
+			continue
+		}
+		volume := graphVolume{work: workKey(item)}
+		for _, author := range item.VolumeInfo.Authors {
+			key := authorKey(author)
+			if key == "" || slices.Contains(volume.authors, key) {
+				continue
+			}
+			volume.authors = append(volume.authors, key)
+			if _, ok := g.names[key]; !ok {
+				g.names[key] = strings.TrimSpace(author)
+			}
+		}
+		for _, category := range item.VolumeInfo.Categories {
+			if category = strings.TrimSpace(category); category != "" {
+				volume.categories = append(volume.categories, category)
+			}
+		}
+
+		work := g.works[volume.work]
+		if work == nil {
+			work = &graphWork{authors: volume.authors}
+			g.works[volume.work] = work
+			forEachPair(work.authors, func(key string, other string) { addCount(g.coAuthors, key, other) })
+		}
+		work.editions++
+		for _, key := range volume.authors {
+			g.seen[key]++
+			for _, category := range volume.categories {
+				addCount(g.categories, key, category)
+				addCount(g.members, category, key)
+			}
+		}
+		if item.ID != "" {
+			g.volumes[item.ID] = volume
+		}
+	}
+}
+
+// RemoveVolumes takes back what AddVolumes recorded for each volume ID.
+// Authors, links and categories no volume supports any more are dropped.
+func (g *AuthorGraph) RemoveVolumes(ids []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, id := range ids {
+		volume, ok := g.volumes[id]
+		if !ok {
+			continue
+		}
+		delete(g.volumes, id)
+		for _, key := range volume.authors {
+			for _, category := range volume.categories {
+				removeCount(g.categories, key, category)
+				removeCount(g.members, category, key)
+			}
+			if g.seen[key]--; g.seen[key] == 0 {
+				delete(g.seen, key)
+				delete(g.names, key)
+			}
+		}
+		work := g.works[volume.work]
+		if work.editions--; work.editions == 0 {
+			delete(g.works, volume.work)
+			forEachPair(work.authors, func(key string, other string) { removeCount(g.coAuthors, key, other) })
+		}
+	}
+}
+
+// Known reports whether the author has been seen.
+func (g *AuthorGraph) Known(name string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.names[authorKey(name)]
+	return ok
+}
+
+// Related returns up to limit authors linked to name, most closely related
+// first. Each co-authored book adds coAuthorEdgeWeight and the overlap of the
+// two authors' categories adds up to categoryEdgeWeight.
+func (g *AuthorGraph) Related(name string, limit int) []model.RelatedAuthor {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	key := authorKey(name)
+
+	candidates := map[string]bool{}
+	for other := range g.coAuthors[key] {
+		candidates[other] = true
+	}
+	for category := range g.categories[key] {
+		for other := range g.members[category] {
+			candidates[other] = true
+		}
+	}
+	delete(candidates, key)
+
+	related := []model.RelatedAuthor{}
+	for other := range candidates {
+		related = append(related, g.relation(key, other))
+	}
+	slices.SortFunc(related, func(a, b model.RelatedAuthor) int {
+		if c := cmp.Compare(b.Weight, a.Weight); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return related[:min(limit, len(related))]
+}
+
+// Relation describes how closely two authors are linked. Unrelated or unknown
+// authors have a zero Weight.
+func (g *AuthorGraph) Relation(name string, other string) model.RelatedAuthor {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.relation(authorKey(name), authorKey(other))
+}
+
+func (g *AuthorGraph) relation(key string, other string) model.RelatedAuthor {
+	shared := []string{}
+	for category := range g.categories[key] {
+		if g.categories[other][category] > 0 {
+			shared = append(shared, category)
+		}
+	}
+	slices.Sort(shared)
+	union := len(g.categories[key]) + len(g.categories[other]) - len(shared)
+	overlap := 0.0
+	if union > 0 {
+		overlap = float64(len(shared)) / float64(union)
+	}
+	books := g.coAuthors[key][other]
+	name := g.names[other]
+	if name == "" {
+		name = other
+	}
+	return model.RelatedAuthor{
+		Name:             name,
+		Weight:           coAuthorEdgeWeight*float64(books) + categoryEdgeWeight*overlap,
+		CoAuthoredBooks:  books,
+		SharedCategories: shared,
+	}
+}
+
+func authorKey(name string) string {
+	return normalizeString(name)
+}
+
+func addCount(counts map[string]map[string]int, key string, other string) {
+	if counts[key] == nil {
+		counts[key] = map[string]int{}
+	}
+	counts[key][other]++
+}
+
+func removeCount(counts map[string]map[string]int, key string, other string) {
+	if counts[key][other]--; counts[key][other] <= 0 {
+		delete(counts[key], other)
+		if len(counts[key]) == 0 {
+			delete(counts, key)
+		}
+	}
+}
+
+// forEachPair calls fn for every ordered pair of distinct keys.
+func forEachPair(keys []string, fn func(key string, other string)) {
+	for _, key := range keys {
+		for _, other := range keys {
+			if other != key {
+				fn(key, other)
+			}
+		}
+	}
+}
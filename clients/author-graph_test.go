@@ -0,0 +1,60 @@
+package client
+
+import (
+	"sync"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorGraph_Related(t *testing.T) {
+	book := func(id string, title string, authors []string, categories []string) model.GoogleBookItem {
+		return model.GoogleBookItem{
+			ID:         id,
+			VolumeInfo: model.GoogleBookVolumeInfo{Title: title, Authors: authors, Categories: categories},
+		}
+	}
+	graph := NewAuthorGraph()
+	graph.AddVolumes([]model.GoogleBookItem{
+		book("1", "The Difference Engine", []string{"William Gibson", "Bruce Sterling"}, []string{"Fiction", "Steampunk"}),
+		book("2", "The Difference Engine", []string{"William Gibson", "Bruce Sterling"}, []string{"Fiction"}),
+		book("3", "Neuromancer", []string{"William Gibson"}, []string{"Fiction", "Cyberpunk"}),
+		book("4", "Snow Crash", []string{"Neal Stephenson"}, []string{"Cyberpunk"}),
+		book("5", "Wuthering Heights", []string{"Emily Brontë"}, []string{"Classics"}),
+	})
+
+	got := graph.Related("william gibson", 10)
+
+	assert.Equal(t, []model.RelatedAuthor{
+		{Name: "Bruce Sterling", Weight: 1 + 0.5*2.0/3.0, CoAuthoredBooks: 1, SharedCategories: []string{"Fiction", "Steampunk"}},
+		{Name: "Neal Stephenson", Weight: 0.5 * 1.0 / 3.0, CoAuthoredBooks: 0, SharedCategories: []string{"Cyberpunk"}},
+	}, got)
+	assert.Len(t, graph.Related("William Gibson", 1), 1)
+	assert.Empty(t, graph.Related("Nobody", 10))
+	assert.True(t, graph.Known("Emily Brontë"))
+	assert.False(t, graph.Known("Nobody"))
+}
+
+func TestAuthorGraph_fromCatalog(t *testing.T) {
+	catalog := NewCatalog()
+	graph := NewAuthorGraph()
+	catalog.OnAdd(graph.AddVolumes)
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"1", "2", "1"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			catalog.Add(model.GoogleBookItem{
+				ID:         id,
+				VolumeInfo: model.GoogleBookVolumeInfo{Title: "Book " + id, Authors: []string{"William Gibson", "Bruce Sterling"}},
+			})
+		}(id)
+	}
+	wg.Wait()
+
+	got := graph.Related("William Gibson", 10)
+	assert.Len(t, got, 1)
+	assert.Equal(t, 2, got[0].CoAuthoredBooks)
+}
@@ -0,0 +1,336 @@
+package client
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+
+	model "example.com/book-learn/models"
+)
+
+// MaxCatalogVolumes bounds the catalog. Once it is full the volumes seen
+// longest ago are dropped to make room for new ones.
+const MaxCatalogVolumes = 50000
+
+// Catalog remembers every volume the client has fetched so that features
+// such as series lookups can work from books already seen without another
+// round trip to Google. It is safe for concurrent use.
+type Catalog struct {
+	mu      sync.RWMutex
+	limit   int
+	volumes map[string]model.GoogleBookItem
+	// order holds volume IDs in the order they were first seen, and sequence
+	// each volume's place in it.
+	order    []string
+	sequence map[string]int
+	seen     int
+	// series is the series detected in each volume when it was added, and
+	// seriesMembers the volumes of each series.
+	series        map[string]*model.Series
+	seriesMembers map[string][]string
+	// workKeys is the work each volume belongs to and works the volumes of
+	// each work. categorized holds the works filed under each category,
+	// counting subcategories towards their parents, and workSlugs the
+	// categories of each work.
+	workKeys    map[string]string
+	works       map[string][]string
+	categorized map[string]map[string]bool
+	workSlugs   map[string][]string
+	version     int
+	// notify keeps listeners hearing about additions and evictions in the
+	// order the catalog made them.
+	notify         sync.Mutex
+	listeners      []func([]model.GoogleBookItem)
+	evictListeners []func([]string)
+}
+
+func NewCatalog() *Catalog {
+	return NewBoundedCatalog(MaxCatalogVolumes)
+}
+
+// NewBoundedCatalog returns a catalog holding at most limit volumes.
+func NewBoundedCatalog(limit int) *Catalog {
+	return &Catalog{
+		limit:         limit,
+		volumes:       map[string]model.GoogleBookItem{},
+		sequence:      map[string]int{},
+		series:        map[string]*model.Series{},
+		seriesMembers: map[string][]string{},
+		workKeys:      map[string]string{},
+		works:         map[string][]string{},
+		categorized:   map[string]map[string]bool{},
+		workSlugs:     map[string][]string{},
+	}
+}
+
+// OnAdd registers fn to be called with the volumes each Add call stored for
+// the first time. Listeners are called after the catalog has been updated.
+func (c *Catalog) OnAdd(fn func([]model.GoogleBookItem)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// OnEvict registers fn to be called with the IDs of the volumes each Add call
+// dropped to keep the catalog within its limit, so indexes built from OnAdd
+// can drop them too. Listeners are called after the OnAdd listeners.
+func (c *Catalog) OnEvict(fn func([]string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictListeners = append(c.evictListeners, fn)
+}
+
+// Add stores volumes by ID, replacing any earlier copy. Nested editions are
+// stored as volumes of their own and per-request relevance scores are dropped.
+func (c *Catalog) Add(items ...model.GoogleBookItem) {
+	c.mu.Lock()
+	added := []model.GoogleBookItem{}
+	for _, item := range flattenEditions(items) {
+		if item.ID == "" {
+			continue
+		}
+		item.Relevance = nil
+		if _, ok := c.volumes[item.ID]; ok {
+			c.unindex(item.ID)
+		} else {
+			c.order = append(c.order, item.ID)
+			c.sequence[item.ID] = c.seen
+			c.seen++
+			c.version++
+			added = append(added, item)
+		}
+		c.volumes[item.ID] = item
+		c.index(item)
+	}
+	evicted := []string{}
+	for len(c.order) > c.limit {
+		evicted = append(evicted, c.order[0])
+		c.evict(c.order[0])
+		c.order = c.order[1:]
+	}
+	listeners, evictListeners := c.listeners, c.evictListeners
+	c.notify.Lock()
+	defer c.notify.Unlock()
+	c.mu.Unlock()
+
+	if len(added) > 0 {
+		for _, listener := range listeners {
+			listener(added)
+		}
+	}
+	if len(evicted) > 0 {
+		for _, listener := range evictListeners {
+			listener(evicted)
+		}
+	}
+}
+
+// index records what is derived from a volume once, so lookups do not work
+// it out again for every request.
+func (c *Catalog) index(item model.GoogleBookItem) {
+	series := DetectSeries(item)
+	c.series[item.ID] = series
+	if series != nil {
+		c.seriesMembers[series.ID] = append(c.seriesMembers[series.ID], item.ID)
+	}
+	key := workKey(item)
+	c.workKeys[item.ID] = key
+	c.works[key] = append(c.works[key], item.ID)
+	c.categorize(key)
+}
+
+func (c *Catalog) unindex(id string) {
+	if series := c.series[id]; series != nil {
+		members := slices.DeleteFunc(c.seriesMembers[series.ID], func(member string) bool { return member == id })
+		if len(members) == 0 {
+			delete(c.seriesMembers, series.ID)
+		} else {
+			c.seriesMembers[series.ID] = members
+		}
+	}
+	delete(c.series, id)
+	if key, ok := c.workKeys[id]; ok {
+		editions := slices.DeleteFunc(c.works[key], func(edition string) bool { return edition == id })
+		if len(editions) == 0 {
+			delete(c.works, key)
+		} else {
+			c.works[key] = editions
+		}
+		delete(c.workKeys, id)
+		c.categorize(key)
+	}
+}
+
+// categorize files a work under the categories of its editions, replacing
+// where it was filed before.
+func (c *Catalog) categorize(key string) {
+	for _, slug := range c.workSlugs[key] {
+		delete(c.categorized[slug], key)
+		if len(c.categorized[slug]) == 0 {
+			delete(c.categorized, slug)
+		}
+	}
+	delete(c.workSlugs, key)
+
+	raw := []string{}
+	for _, id := range c.works[key] {
+		raw = append(raw, c.volumes[id].VolumeInfo.Categories...)
+	}
+	slugs := []string{}
+	for _, category := range NormalizeCategories(raw) {
+		for _, slug := range category.Path {
+			if !slices.Contains(slugs, slug) {
+				slugs = append(slugs, slug)
+			}
+		}
+	}
+	for _, slug := range slugs {
+		if c.categorized[slug] == nil {
+			c.categorized[slug] = map[string]bool{}
+		}
+		c.categorized[slug][key] = true
+	}
+	if len(slugs) > 0 {
+		c.workSlugs[key] = slugs
+	}
+}
+
+// work groups the editions of a work into one item, ordering them by when
+// they were first seen like GroupEditions over the whole catalog would.
+func (c *Catalog) work(key string) model.GoogleBookItem {
+	ids := slices.Clone(c.works[key])
+	slices.SortFunc(ids, func(a, b string) int { return cmp.Compare(c.sequence[a], c.sequence[b]) })
+	editions := make([]model.GoogleBookItem, 0, len(ids))
+	for _, id := range ids {
+		editions = append(editions, c.volumes[id])
+	}
+	return GroupEditions(editions)[0]
+}
+
+// firstSeen is the place of the work's earliest edition in the catalog.
+func (c *Catalog) firstSeen(key string) int {
+	first := math.MaxInt
+	for _, id := range c.works[key] {
+		first = min(first, c.sequence[id])
+	}
+	return first
+}
+
+func (c *Catalog) evict(id string) {
+	c.unindex(id)
+	delete(c.volumes, id)
+	delete(c.sequence, id)
+	c.version++
+}
+
+// Get returns the volume with the given ID.
+func (c *Catalog) Get(id string) (model.GoogleBookItem, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, ok := c.volumes[id]
+	return item, ok
+}
+
+// All returns every volume in the order it was first seen.
+func (c *Catalog) All() []model.GoogleBookItem {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	items := make([]model.GoogleBookItem, 0, len(c.order))
+	for _, id := range c.order {
+		items = append(items, c.volumes[id])
+	}
+	return items
+}
+
+// Editions returns the other editions of the volume's work, in the order
+// they were first seen.
+func (c *Catalog) Editions(id string) []model.GoogleBookItem {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ids := slices.DeleteFunc(slices.Clone(c.works[c.workKeys[id]]), func(edition string) bool { return edition == id })
+	slices.SortFunc(ids, func(a, b string) int { return cmp.Compare(c.sequence[a], c.sequence[b]) })
+	editions := make([]model.GoogleBookItem, 0, len(ids))
+	for _, edition := range ids {
+		editions = append(editions, c.volumes[edition])
+	}
+	return editions
+}
+
+// Len returns the number of volumes in the catalog.
+func (c *Catalog) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.order)
+}
+
+// Version changes whenever a volume is added or dropped, so callers can
+// tell when something derived from the catalog is stale. Fresh copies of
+// volumes already in the catalog do not change it.
+func (c *Catalog) Version() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// Series returns one edition per work in the series in reading order. Works
+// without a known position follow the numbered ones by publication date.
+func (c *Catalog) Series(id string) []model.GoogleBookItem {
+	c.mu.RLock()
+	ids := slices.Clone(c.seriesMembers[id])
+	slices.SortFunc(ids, func(a, b string) int { return cmp.Compare(c.sequence[a], c.sequence[b]) })
+	members := make([]model.GoogleBookItem, 0, len(ids))
+	positions := map[string]int{}
+	for _, member := range ids {
+		members = append(members, c.volumes[member])
+		positions[member] = math.MaxInt
+		if position := c.series[member].Position; position > 0 {
+			positions[member] = position
+		}
+	}
+	c.mu.RUnlock()
+
+	works := GroupEditions(members)
+	slices.SortStableFunc(works, func(a, b model.GoogleBookItem) int {
+		if c := cmp.Compare(positions[a.ID], positions[b.ID]); c != 0 {
+			return c
+		}
+		return sortComparators[SortPublishedAsc](a, b)
+	})
+	return works
+}
+
+// Category returns the number of works filed under the category or any of
+// its descendants, and up to limit of them from start, one edition per work,
+// in the order the works were first seen.
+func (c *Catalog) Category(slug string, start int, limit int) ([]model.GoogleBookItem, int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.categorized[slug]))
+	firstSeen := map[string]int{}
+	for key := range c.categorized[slug] {
+		keys = append(keys, key)
+		firstSeen[key] = c.firstSeen(key)
+	}
+	slices.SortFunc(keys, func(a, b string) int { return cmp.Compare(firstSeen[a], firstSeen[b]) })
+
+	start = min(max(start, 0), len(keys))
+	end := min(start+max(limit, 0), len(keys))
+	works := make([]model.GoogleBookItem, 0, end-start)
+	for _, key := range keys[start:end] {
+		works = append(works, c.work(key))
+	}
+	return works, len(keys)
+}
+
+// CategoryCounts returns the number of works filed under each category,
+// counting works in subcategories towards their parents.
+func (c *Catalog) CategoryCounts() map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	counts := make(map[string]int, len(c.categorized))
+	for slug, works := range c.categorized {
+		counts[slug] = len(works)
+	}
+	return counts
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog_Add(t *testing.T) {
+	catalog := NewCatalog()
+	first := model.GoogleBookItem{ID: "1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "first"}}
+	second := model.GoogleBookItem{ID: "2", Relevance: &model.RelevanceScore{Total: 1}}
+	updated := model.GoogleBookItem{ID: "1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "updated"}}
+	grouped := model.GoogleBookItem{ID: "3", Editions: []model.GoogleBookItem{{ID: "4"}}}
+
+	catalog.Add(first, second, model.GoogleBookItem{})
+	catalog.Add(updated, grouped)
+
+	ids := []string{}
+	for _, item := range catalog.All() {
+		ids = append(ids, item.ID)
+		assert.Nil(t, item.Relevance)
+		assert.Empty(t, item.Editions)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids)
+	got, ok := catalog.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, "updated", got.VolumeInfo.Title)
+	_, ok = catalog.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestCatalog_Series(t *testing.T) {
+	book := func(id string, title string, date string) model.GoogleBookItem {
+		return model.GoogleBookItem{
+			ID: id,
+			VolumeInfo: model.GoogleBookVolumeInfo{
+				Title:         title,
+				Authors:       []string{"William Gibson"},
+				PublishedDate: date,
+			},
+		}
+	}
+	catalog := NewCatalog()
+	catalog.Add(
+		book("mona-lisa", "Mona Lisa Overdrive (Sprawl #3)", "1988"),
+		book("burning-chrome", "Burning Chrome (Sprawl)", "1986"),
+		book("neuromancer", "Neuromancer (Sprawl #1)", "1984"),
+		book("count-zero", "Count Zero (Sprawl #2)", "1986"),
+		book("count-zero-reissue", "Count Zero (Sprawl #2)", "2006"),
+		book("idoru", "Idoru (Bridge #2)", "1996"),
+	)
+
+	ids := []string{}
+	for _, item := range catalog.Series("sprawl") {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []string{"neuromancer", "count-zero", "mona-lisa"}, ids)
+	assert.Empty(t, catalog.Series("missing"))
+}
+
+func TestCatalog_Series_replaced(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Add(model.GoogleBookItem{ID: "idoru", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Idoru (Bridge #2)"}})
+	assert.Len(t, catalog.Series("bridge"), 1)
+
+	catalog.Add(model.GoogleBookItem{ID: "idoru", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Idoru"}})
+	assert.Empty(t, catalog.Series("bridge"))
+}
+
+func TestCatalog_limit(t *testing.T) {
+	catalog := NewBoundedCatalog(2)
+	catalog.Add(
+		model.GoogleBookItem{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer (Sprawl #1)"}},
+		model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero (Sprawl #2)"}},
+	)
+	version := catalog.Version()
+	catalog.Add(model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero (Sprawl #2)"}})
+	assert.Equal(t, version, catalog.Version())
+
+	// The volume seen longest ago makes room
+	catalog.Add(model.GoogleBookItem{ID: "mona-lisa", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Mona Lisa Overdrive (Sprawl #3)"}})
+	assert.Equal(t, 2, catalog.Len())
+	assert.NotEqual(t, version, catalog.Version())
+	_, ok := catalog.Get("neuromancer")
+	assert.False(t, ok)
+	ids := []string{}
+	for _, item := range catalog.Series("sprawl") {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []string{"count-zero", "mona-lisa"}, ids)
+}
+
+func TestGoogleBookClient_remembersVolumes(t *testing.T) {
+	catalog := NewCatalog()
+	bc := GoogleBookClient{
+		GetData:  mockGetData([]byte(`{"items": [{"id": "seen", "volumeInfo": {"title": "Filtered Out"}}]}`), nil),
+		Catalog:  catalog,
+		PactMode: false,
+	}
+
+	got, err := bc.ByTitle(context.Background(), GoogleBookRequest{Title: "Neuromancer"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, got.Items)
+	_, ok := catalog.Get("seen")
+	assert.True(t, ok)
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"slices"
+	"strings"
+
+	model "example.com/book-learn/models"
+)
+
+type taxonomyNode struct {
+	slug    string
+	name    string
+	parent  string
+	aliases []string
+}
+
+// taxonomy is a BISAC-like category hierarchy. Slugs are stable and used in
+// URLs, so existing entries must not be renamed. Parents come before their
+// children.
+var taxonomy = []taxonomyNode{
+	{slug: "fiction", name: "Fiction", aliases: []string{"fiction", "novel", "novels"}},
+	{slug: "science-fiction", name: "Science Fiction", parent: "fiction", aliases: []string{"science fiction", "sci-fi", "scifi", "sf"}},
+	{slug: "cyberpunk", name: "Cyberpunk", parent: "science-fiction", aliases: []string{"cyberpunk", "cyberpunk fiction"}},
+	{slug: "space-opera", name: "Space Opera", parent: "science-fiction", aliases: []string{"space opera"}},
+	{slug: "steampunk", name: "Steampunk", parent: "science-fiction", aliases: []string{"steampunk"}},
+	{slug: "dystopian", name: "Dystopian", parent: "science-fiction", aliases: []string{"dystopian", "dystopias", "dystopian fiction"}},
+	{slug: "fantasy", name: "Fantasy", parent: "fiction", aliases: []string{"fantasy", "fantasy fiction"}},
+	{slug: "mystery", name: "Mystery & Detective", parent: "fiction", aliases: []string{"mystery", "mystery & detective", "detective and mystery stories", "crime"}},
+	{slug: "thrillers", name: "Thrillers", parent: "fiction", aliases: []string{"thrillers", "thriller", "suspense"}},
+	{slug: "romance", name: "Romance", parent: "fiction", aliases: []string{"romance", "love stories"}},
+	{slug: "horror", name: "Horror", parent: "fiction", aliases: []string{"horror", "horror tales"}},
+	{slug: "historical-fiction", name: "Historical Fiction", parent: "fiction", aliases: []string{"historical", "historical fiction"}},
+	{slug: "literary-fiction", name: "Literary Fiction", parent: "fiction", aliases: []string{"literary", "literary fiction"}},
+	{slug: "classics", name: "Classics", parent: "fiction", aliases: []string{"classics", "classic literature"}},
+	{slug: "short-stories", name: "Short Stories", parent: "fiction", aliases: []string{"short stories", "short story"}},
+	{slug: "nonfiction", name: "Nonfiction", aliases: []string{"nonfiction", "non-fiction"}},
+	{slug: "biography", name: "Biography & Autobiography", parent: "nonfiction", aliases: []string{"biography & autobiography", "biography", "autobiography", "memoir", "memoirs"}},
+	{slug: "history", name: "History", parent: "nonfiction", aliases: []string{"history"}},
+	{slug: "science", name: "Science", parent: "nonfiction", aliases: []string{"science"}},
+	{slug: "technology", name: "Technology & Engineering", parent: "nonfiction", aliases: []string{"technology & engineering", "technology", "engineering", "engineers", "computers"}},
+	{slug: "philosophy", name: "Philosophy", parent: "nonfiction", aliases: []string{"philosophy"}},
+	{slug: "religion", name: "Religion", parent: "nonfiction", aliases: []string{"religion", "christianity", "theology"}},
+	{slug: "essays", name: "Essays", parent: "nonfiction", aliases: []string{"essays", "literary collections"}},
+	{slug: "social-science", name: "Social Science", parent: "nonfiction", aliases: []string{"social science", "sociology", "african americans"}},
+	{slug: "nature", name: "Nature", parent: "nonfiction", aliases: []string{"nature", "natural history"}},
+	{slug: "travel", name: "Travel", parent: "nonfiction", aliases: []string{"travel", "description and travel"}},
+	{slug: "business", name: "Business & Economics", parent: "nonfiction", aliases: []string{"business & economics", "business", "economics"}},
+	{slug: "self-help", name: "Self-Help", parent: "nonfiction", aliases: []string{"self-help", "self help"}},
+	{slug: "cooking", name: "Cooking", parent: "nonfiction", aliases: []string{"cooking", "cookery"}},
+	{slug: "art", name: "Art", parent: "nonfiction", aliases: []string{"art", "design", "photography"}},
+	{slug: "music", name: "Music", parent: "nonfiction", aliases: []string{"music"}},
+	{slug: "poetry", name: "Poetry", aliases: []string{"poetry", "poems"}},
+	{slug: "drama", name: "Drama", aliases: []string{"drama", "plays"}},
+	{slug: "comics", name: "Comics & Graphic Novels", aliases: []string{"comics & graphic novels", "comics", "graphic novels"}},
+	{slug: "juvenile", name: "Children's", aliases: []string{"juvenile fiction", "juvenile nonfiction", "children's stories", "children"}},
+	{slug: "young-adult", name: "Young Adult", aliases: []string{"young adult fiction", "young adult nonfiction", "young adult"}},
+}
+
+var (
+	taxonomyBySlug  = map[string]model.Category{}
+	taxonomyByAlias = map[string]string{}
+)
+
+func init() {
+	for _, node := range taxonomy {
+		category := model.Category{Slug: node.slug, Name: node.name, Parent: node.parent}
+		if parent, ok := taxonomyBySlug[node.parent]; ok {
+			category.Path = append(slices.Clone(parent.Path), node.slug)
+		} else {
+			category.Path = []string{node.slug}
+		}
+		taxonomyBySlug[node.slug] = category
+		for _, alias := range node.aliases {
+			taxonomyByAlias[alias] = node.slug
+		}
+	}
+}
+
+// Categories returns every category in the taxonomy, parents first.
+func Categories() []model.Category {
+	categories := []model.Category{}
+	for _, node := range taxonomy {
+		categories = append(categories, taxonomyBySlug[node.slug])
+	}
+	return categories
+}
+
+// LookupCategory returns the taxonomy category with the given slug.
+func LookupCategory(slug string) (model.Category, bool) {
+	category, ok := taxonomyBySlug[slug]
+	return category, ok
+}
+
+// NormalizeCategories maps Google's free-form categories such as
+// "Fiction / Science Fiction / Cyberpunk" or "Cyberpunk fiction" onto the
+// taxonomy. The most specific recognised part of each category is used and
+// categories that match nothing are left out.
+func NormalizeCategories(raw []string) []model.Category {
+	categories := []model.Category{}
+	for _, value := range raw {
+		slug := matchCategory(value)
+		if slug == "" || slices.ContainsFunc(categories, func(c model.Category) bool { return c.Slug == slug }) {
+			continue
+		}
+		categories = append(categories, taxonomyBySlug[slug])
+	}
+	return categories
+}
+
+func matchCategory(value string) string {
+	segments := strings.Split(value, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if slug := matchCategorySegment(segments[i]); slug != "" {
+			return slug
+		}
+	}
+	return ""
+}
+
+// matchCategorySegment tries the whole segment, then the segment without a
+// trailing "fiction", then ever shorter word suffixes so that
+// "Canadian essays" matches "essays".
+func matchCategorySegment(segment string) string {
+	segment = strings.Join(strings.Fields(strings.ToLower(segment)), " ")
+	if segment == "" || segment == "general" {
+		return ""
+	}
+	if slug, ok := taxonomyByAlias[segment]; ok {
+		return slug
+	}
+	if trimmed := strings.TrimSuffix(segment, " fiction"); trimmed != segment {
+		if slug, ok := taxonomyByAlias[trimmed]; ok {
+			return slug
+		}
+	}
+	words := strings.Fields(segment)
+	for i := 1; i < len(words); i++ {
+		if slug, ok := taxonomyByAlias[strings.Join(words[i:], " ")]; ok {
+			return slug
+		}
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCategories(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   []string
+		slugs []string
+	}{
+		{name: "bisac path", raw: []string{"Fiction / Science Fiction / Cyberpunk"}, slugs: []string{"cyberpunk"}},
+		{name: "general leaf", raw: []string{"Fiction / Science Fiction / General"}, slugs: []string{"science-fiction"}},
+		{name: "trailing fiction", raw: []string{"Cyberpunk fiction"}, slugs: []string{"cyberpunk"}},
+		{name: "word suffix", raw: []string{"Canadian essays"}, slugs: []string{"essays"}},
+		{name: "google top level", raw: []string{"Biography & Autobiography"}, slugs: []string{"biography"}},
+		{name: "duplicates collapse", raw: []string{"Cyberpunk", "Cyberpunk fiction"}, slugs: []string{"cyberpunk"}},
+		{name: "unknown dropped", raw: []string{"Clackmannanshire (Scotland)", "History"}, slugs: []string{"history"}},
+		{name: "empty", raw: nil, slugs: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slugs := []string{}
+			for _, category := range NormalizeCategories(tt.raw) {
+				slugs = append(slugs, category.Slug)
+			}
+			assert.Equal(t, tt.slugs, slugs)
+		})
+	}
+}
+
+func TestLookupCategory(t *testing.T) {
+	got, ok := LookupCategory("cyberpunk")
+	assert.True(t, ok)
+	assert.Equal(t, model.Category{
+		Slug:   "cyberpunk",
+		Name:   "Cyberpunk",
+		Parent: "science-fiction",
+		Path:   []string{"fiction", "science-fiction", "cyberpunk"},
+	}, got)
+
+	_, ok = LookupCategory("missing")
+	assert.False(t, ok)
+}
+
+func TestCategories(t *testing.T) {
+	seen := map[string]bool{}
+	for _, category := range Categories() {
+		assert.False(t, seen[category.Slug], "duplicate slug %s", category.Slug)
+		if category.Parent != "" {
+			assert.True(t, seen[category.Parent], "parent of %s listed after it", category.Slug)
+		}
+		seen[category.Slug] = true
+	}
+}
+
+func TestCatalog_Category(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Add(
+		model.GoogleBookItem{ID: "1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Categories: []string{"Cyberpunk fiction"}}},
+		model.GoogleBookItem{ID: "2", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Categories: []string{"Fiction"}}},
+		model.GoogleBookItem{ID: "3", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Dune", Categories: []string{"Fiction / Science Fiction / Space Opera"}}},
+		model.GoogleBookItem{ID: "4", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Reminiscences", Categories: []string{"Biography & Autobiography"}}},
+	)
+
+	ids := []string{}
+	works, total := catalog.Category("science-fiction", 0, 10)
+	for _, work := range works {
+		ids = append(ids, work.ID)
+	}
+	assert.Equal(t, []string{"1", "3"}, ids)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []string{"2"}, editionIDs(works[0]))
+	works, total = catalog.Category("science-fiction", 1, 10)
+	assert.Equal(t, "3", works[0].ID)
+	assert.Equal(t, 2, total)
+	works, total = catalog.Category("poetry", 0, 10)
+	assert.Empty(t, works)
+	assert.Zero(t, total)
+	assert.Equal(t, map[string]int{
+		"fiction":         2,
+		"science-fiction": 2,
+		"cyberpunk":       1,
+		"space-opera":     1,
+		"nonfiction":      1,
+		"biography":       1,
+	}, catalog.CategoryCounts())
+}
+
+func TestCatalog_Category_replaced(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Add(model.GoogleBookItem{ID: "1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Categories: []string{"Cyberpunk fiction"}}})
+	catalog.Add(model.GoogleBookItem{ID: "1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Categories: []string{"Poetry"}}})
+
+	_, total := catalog.Category("cyberpunk", 0, 10)
+	assert.Zero(t, total)
+	assert.Equal(t, map[string]int{"poetry": 1}, catalog.CategoryCounts())
+}
+
+func editionIDs(work model.GoogleBookItem) []string {
+	ids := []string{}
+	for _, edition := range work.Editions {
+		ids = append(ids, edition.ID)
+	}
+	return ids
+}
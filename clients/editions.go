@@ -0,0 +1,107 @@
+package client
+
+import (
+	"cmp"
+	"regexp"
+	"strings"
+
+	model "example.com/book-learn/models"
+)
+
+var (
+	editionParenthetical = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	leadingArticle       = regexp.MustCompile(`^(the|a|an)\s+`)
+	editionSubtitle      = regexp.MustCompile(`^(a (novel|novella|memoir)|.*\b(edition|anniversary|unabridged|abridged))$`)
+)
+
+// groupEditions clusters the editions of each work after filtering so that a
+// work is returned once with its other printings nested under it.
+func groupEditions(resp model.GoogleBookResponse, err error) (model.GoogleBookResponse, error) {
+	if err != nil {
+		return resp, err
+	}
+	resp.Items = GroupEditions(resp.Items)
+	return resp, err
+}
+
+// GroupEditions groups items by normalized title and primary author. Each
+// group is represented by its most complete edition, placed where the first
+// edition of the group appeared, with the remaining editions in Editions.
+// Items that were already grouped are flattened first and duplicate volume IDs
+// are dropped, so pages merged from several requests can be regrouped safely.
+func GroupEditions(items []model.GoogleBookItem) []model.GoogleBookItem {
+	seen := map[string]bool{}
+	order := []string{}
+	works := map[string][]model.GoogleBookItem{}
+
+	for _, edition := range flattenEditions(items) {
+		if edition.ID != "" && seen[edition.ID] {
+			continue
+		}
+		seen[edition.ID] = true
+		key := workKey(edition)
+		if _, ok := works[key]; !ok {
+			order = append(order, key)
+		}
+		works[key] = append(works[key], edition)
+	}
+
+	grouped := []model.GoogleBookItem{}
+	for _, key := range order {
+		editions := works[key]
+		canonical := canonicalEdition(editions)
+		work := editions[canonical]
+		work.Editions = nil
+		for i, edition := range editions {
+			if i != canonical {
+				work.Editions = append(work.Editions, edition)
+			}
+		}
+		grouped = append(grouped, work)
+	}
+	return grouped
+}
+
+func flattenEditions(items []model.GoogleBookItem) []model.GoogleBookItem {
+	flat := []model.GoogleBookItem{}
+	for _, item := range items {
+		editions := item.Editions
+		item.Editions = nil
+		flat = append(flat, item)
+		flat = append(flat, flattenEditions(editions)...)
+	}
+	return flat
+}
+
+// canonicalEdition returns the index of the most complete edition, preferring
+// the longest description on a tie and then the earliest edition in the list.
+func canonicalEdition(editions []model.GoogleBookItem) int {
+	best := 0
+	for i, edition := range editions {
+		byCompleteness := cmp.Compare(editionCompleteness(edition), editionCompleteness(editions[best]))
+		byDescription := cmp.Compare(len(edition.VolumeInfo.Description), len(editions[best].VolumeInfo.Description))
+		if byCompleteness > 0 || (byCompleteness == 0 && byDescription > 0) {
+			best = i
+		}
+	}
+	return best
+}
+
+// workKey identifies the work an edition belongs to. Bracketed edition notes,
+// subtitles that only name an edition ("A Novel", "Anniversary Edition") and
+// leading articles are ignored; other subtitles are kept, since "Dune: House
+// Atreides" is not an edition of "Dune".
+func workKey(book model.GoogleBookItem) string {
+	title := strings.ToLower(book.VolumeInfo.Title)
+	title = editionParenthetical.ReplaceAllString(title, "")
+	if i := strings.IndexAny(title, ":;"); i > 0 && editionSubtitle.MatchString(strings.TrimSpace(title[i+1:])) {
+		title = title[:i]
+	}
+	title = leadingArticle.ReplaceAllString(strings.TrimSpace(title), "")
+
+	author := ""
+	if len(book.VolumeInfo.Authors) > 0 {
+		author = book.VolumeInfo.Authors[0]
+	}
+	return normalizeString(title) + "|" + normalizeString(author)
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupEditions(t *testing.T) {
+	hardcover := model.GoogleBookItem{
+		ID: "hardcover",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:   "Neuromancer",
+			Authors: []string{"William Gibson"},
+		},
+	}
+	paperback := model.GoogleBookItem{
+		ID: "paperback",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:       "Neuromancer (Ace Science Fiction)",
+			Authors:     []string{"William Gibson"},
+			Description: "has-description",
+			ImageLinks: model.GoogleBookImageLinks{
+				Thumbnail: "http://example.com/has-thumbnail",
+			},
+		},
+	}
+	reissue := model.GoogleBookItem{
+		ID: "reissue",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:   "Neuromancer: 40th Anniversary Edition",
+			Authors: []string{"William Gibson"},
+		},
+	}
+	otherWork := model.GoogleBookItem{
+		ID: "other-work",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:   "Count Zero",
+			Authors: []string{"William Gibson"},
+		},
+	}
+	otherAuthor := model.GoogleBookItem{
+		ID: "other-author",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:   "Neuromancer",
+			Authors: []string{"Gale"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		items        []model.GoogleBookItem
+		wantIDs      []string
+		wantEditions map[string][]string
+	}{
+		{
+			name:    "with empty items",
+			items:   []model.GoogleBookItem{},
+			wantIDs: []string{},
+		},
+		{
+			name:    "groups editions under the most complete one",
+			items:   []model.GoogleBookItem{hardcover, otherWork, paperback, reissue, otherAuthor},
+			wantIDs: []string{"paperback", "other-work", "other-author"},
+			wantEditions: map[string][]string{
+				"paperback": {"hardcover", "reissue"},
+			},
+		},
+		{
+			name: "regroups already grouped pages and drops duplicates",
+			items: []model.GoogleBookItem{
+				GroupEditions([]model.GoogleBookItem{hardcover, paperback})[0],
+				paperback,
+				reissue,
+			},
+			wantIDs: []string{"paperback"},
+			wantEditions: map[string][]string{
+				"paperback": {"hardcover", "reissue"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GroupEditions(tt.items)
+			gotIDs := []string{}
+			for _, work := range got {
+				gotIDs = append(gotIDs, work.ID)
+				editionIDs := []string{}
+				for _, edition := range work.Editions {
+					editionIDs = append(editionIDs, edition.ID)
+				}
+				if want, ok := tt.wantEditions[work.ID]; ok {
+					assert.Equal(t, want, editionIDs)
+				} else {
+					assert.Empty(t, editionIDs)
+				}
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func Test_groupEditions(t *testing.T) {
+	pact, err := authorPact()
+	assert.NoError(t, err)
+
+	got, err := groupEditions(pact, nil)
+	assert.NoError(t, err)
+	assert.Less(t, len(got.Items), len(pact.Items))
+	for _, work := range got.Items {
+		if work.VolumeInfo.Title == "Letters to My Son" {
+			assert.Len(t, work.Editions, 2)
+		}
+	}
+
+	_, err = groupEditions(model.GoogleBookResponse{}, errors.New("test-error"))
+	assert.Error(t, err)
+}
+
+func Test_workKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{name: "plain title", title: "Neuromancer", want: "neuromancer|williamgibson"},
+		{name: "edition subtitle", title: "Neuromancer: A Novel", want: "neuromancer|williamgibson"},
+		{name: "anniversary subtitle", title: "Neuromancer: 40th Anniversary Edition", want: "neuromancer|williamgibson"},
+		{name: "work subtitle", title: "Dune: House Atreides", want: "dunehouseatreides|williamgibson"},
+		{name: "shared main title", title: "Star Wars: The Empire Strikes Back", want: "starwarstheempirestrikesback|williamgibson"},
+		{name: "edition note", title: "Neuromancer [Paperback]", want: "neuromancer|williamgibson"},
+		{name: "leading article", title: "The Peripheral", want: "peripheral|williamgibson"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := model.GoogleBookItem{
+				VolumeInfo: model.GoogleBookVolumeInfo{Title: tt.title, Authors: []string{"William Gibson"}},
+			}
+			assert.Equal(t, tt.want, workKey(book))
+		})
+	}
+}
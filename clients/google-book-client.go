@@ -1,9 +1,9 @@
 package client
 
 import (
-	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -37,44 +37,158 @@ import (
 const DEBUG = false
 
 type GoogleBookRequest struct {
-	Title  string
-	Author string
-	Start  int
-	Limit  int
-	Pages  int
+	Title           string
+	Author          string
+	Start           int
+	Limit           int
+	Pages           int
+	Debug           bool
+	PublishedAfter  string
+	PublishedBefore string
+	SortBy          string
+	Query           string
 }
+
+// Validate reports request fields that cannot be used to query books.
+func (r GoogleBookRequest) Validate() error {
+	if r.PublishedAfter != "" && model.ParsePublicationDate(r.PublishedAfter).IsZero() {
+		return fmt.Errorf("PublishedAfter: unrecognised date %q", r.PublishedAfter)
+	}
+	if r.PublishedBefore != "" && model.ParsePublicationDate(r.PublishedBefore).IsZero() {
+		return fmt.Errorf("PublishedBefore: unrecognised date %q", r.PublishedBefore)
+	}
+	if r.Query != "" {
+		if _, err := ParseSearchQuery(r.Query); err != nil {
+			return err
+		}
+	}
+	return validateSortOrder(r.SortBy)
+}
+
+// ErrVolumeNotFound is returned by ByID when no volume has the requested ID.
+var ErrVolumeNotFound = errors.New("volume not found")
+
+// ErrRateLimited is returned when Google rejects a request for exceeding its
+// rate limits. Callers should back off before retrying.
+var ErrRateLimited = errors.New("rate limited by upstream")
+
 type BookClientInterface interface {
 	ByAuthor(ctx context.Context, request GoogleBookRequest) (model.GoogleBookResponse, error)
 	ByTitle(ctx context.Context, request GoogleBookRequest) (model.GoogleBookResponse, error)
+	ByID(ctx context.Context, id string) (model.GoogleBookItem, error)
+	ByQuery(ctx context.Context, request GoogleBookRequest) (model.GoogleBookResponse, error)
 }
 
 type GoogleBookClient struct {
 	GetData  func(url string) (resp *http.Response, err error)
 	PactMode bool
+	Weights  *RelevanceWeights
+	Catalog  *Catalog
 }
 
 func (bc GoogleBookClient) ByAuthor(ctx context.Context, request GoogleBookRequest) (model.GoogleBookResponse, error) {
 	if bc.PactMode == true {
 		slog.Info("serving pact")
-		return authorPact()
+		return bc.remember(authorPact())
 	} else {
 		query := fmt.Sprintf("inauthor:\"%s\"", url.QueryEscape(request.Author))
-		return sortByPublishedDate(
-			filterAuthorResults(request)(
-				bc.bookRequest(ctx, query, request)))
+		request.SortBy = request.SortOrder(SortPublishedDesc)
+		return sortResults(request.SortBy)(
+			scoreResults(request, bc.relevanceWeights())(
+				groupEditions(
+					filterAuthorResults(request)(
+						bc.remember(bc.bookRequest(ctx, query, request))))))
 	}
 }
 
 func (bc GoogleBookClient) ByTitle(ctx context.Context, request GoogleBookRequest) (model.GoogleBookResponse, error) {
 	if bc.PactMode {
 		slog.Info("serving pact")
-		return titlePact()
+		return bc.remember(titlePact())
 	} else {
 		query := fmt.Sprintf("intitle:%s+inauthor:%s", url.QueryEscape(request.Title), url.QueryEscape(request.Author))
-		return sortByDescLength(
-			filterTitleResults(request)(
-				bc.bookRequest(ctx, query, request)))
+		request.SortBy = request.SortOrder(SortRelevance)
+		return sortResults(request.SortBy)(
+			scoreResults(request, bc.relevanceWeights())(
+				filterTitleResults(request)(
+					bc.remember(bc.bookRequest(ctx, query, request)))))
+	}
+}
+
+// ByQuery searches with request.Query, written in the query language parsed
+// by ParseSearchQuery. Whatever Google cannot filter on is checked locally.
+func (bc GoogleBookClient) ByQuery(ctx context.Context, request GoogleBookRequest) (model.GoogleBookResponse, error) {
+	query, err := ParseSearchQuery(request.Query)
+	if err != nil {
+		return model.GoogleBookResponse{}, err
+	}
+	if bc.PactMode {
+		slog.Info("serving pact")
+		return filterQueryResults(query)(bc.remember(authorPact()))
+	} else {
+		request = query.scoringRequest(request)
+		request.SortBy = request.SortOrder(SortRelevance)
+		return sortResults(request.SortBy)(
+			scoreResults(request, bc.relevanceWeights())(
+				filterQueryResults(query)(
+					bc.remember(bc.bookRequest(ctx, query.GoogleQuery(), request)))))
+	}
+}
+
+// ByID fetches a single volume, serving it from the catalog when it has been
+// seen before.
+func (bc GoogleBookClient) ByID(ctx context.Context, id string) (model.GoogleBookItem, error) {
+	if bc.Catalog != nil {
+		if book, ok := bc.Catalog.Get(id); ok {
+			return book, nil
+		}
+	}
+	if bc.PactMode {
+		slog.Info("serving pact")
+		return bc.volumePact(id)
+	}
+
+	fullUrl := fmt.Sprintf("https://www.googleapis.com/books/v1/volumes/%s", url.PathEscape(id))
+	slog.Info(fullUrl)
+
+	// Make Request to Google Book API
+	res, err := bc.GetData(fullUrl)
+	if err != nil {
+		return model.GoogleBookItem{}, err
 	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return model.GoogleBookItem{}, ErrVolumeNotFound
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		return model.GoogleBookItem{}, ErrRateLimited
+	}
+	// Quota and server errors must not read as a missing volume
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return model.GoogleBookItem{}, fmt.Errorf("volume %s: upstream status %d", id, res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return model.GoogleBookItem{}, err
+	}
+	var book model.GoogleBookItem
+	if err := json.Unmarshal(body, &book); err != nil {
+		return model.GoogleBookItem{}, fmt.Errorf("volume %s: %w", id, err)
+	}
+	if book.ID == "" {
+		return model.GoogleBookItem{}, fmt.Errorf("volume %s: response has no id", id)
+	}
+	bc.remember(model.GoogleBookResponse{Items: []model.GoogleBookItem{book}}, nil)
+	return book, nil
+}
+
+// remember adds every volume Google returned to the catalog before filtering.
+func (bc GoogleBookClient) remember(resp model.GoogleBookResponse, err error) (model.GoogleBookResponse, error) {
+	if err == nil && bc.Catalog != nil {
+		bc.Catalog.Add(resp.Items...)
+	}
+	return resp, err
 }
 
 func filterTitleResults(req GoogleBookRequest) func(model.GoogleBookResponse, error) (model.GoogleBookResponse, error) {
@@ -91,20 +205,21 @@ func filterTitleResults(req GoogleBookRequest) func(model.GoogleBookResponse, er
 				fmt.Printf("filterTitleResults: hasDesc %d > %d is %t\n", len(book.VolumeInfo.Description), 0, (len(book.VolumeInfo.Description) > 0))
 				fmt.Printf("filterTitleResults: hasImage %d > %d is %t\n", len(book.VolumeInfo.ImageLinks.Thumbnail), 0, (len(book.VolumeInfo.ImageLinks.Thumbnail) > 0))
 			}
-			if filterExactTitle(book, req.Title) && filterIsEnglish(book) && filterHasDescription(book) && filterHasImage(book) {
+			if filterExactTitle(book, req.Title) && filterIsEnglish(book) && filterHasDescription(book) && filterHasImage(book) && filterPublishedRange(book, req) {
 				filteredBooks = append(filteredBooks, book)
 			}
 		}
 		if len(filteredBooks) == 0 {
 			fmt.Println("Overfiltered: ", req.Title)
 			for _, book := range resp.Items {
-				if filterCloseTitle(book, req.Title) && filterIsEnglish(book) && filterHasImage(book) {
+				if filterCloseTitle(book, req.Title) && filterIsEnglish(book) && filterHasImage(book) && filterPublishedRange(book, req) {
 					filteredBooks = append(filteredBooks, book)
 				}
 			}
 		}
 
 		// return filtered results unless all results were filtered
+		resp.Filtered += len(resp.Items) - len(filteredBooks)
 		resp.Items = filteredBooks
 		return resp, err
 	}
@@ -118,12 +233,13 @@ func filterAuthorResults(req GoogleBookRequest) func(model.GoogleBookResponse, e
 		filteredBooks := []model.GoogleBookItem{}
 
 		for _, book := range resp.Items {
-			if filterExactAuthor(book, req.Author) && filterIsEnglish(book) && filterHasDescription(book) && filterHasImage(book) {
+			if filterExactAuthor(book, req.Author) && filterIsEnglish(book) && filterHasDescription(book) && filterHasImage(book) && filterPublishedRange(book, req) {
 				filteredBooks = append(filteredBooks, book)
 			}
 		}
 
 		// return filtered results
+		resp.Filtered += len(resp.Items) - len(filteredBooks)
 		resp.Items = filteredBooks
 		return resp, err
 	}
@@ -153,24 +269,24 @@ func filterHasImage(book model.GoogleBookItem) bool {
 	return len(book.VolumeInfo.ImageLinks.Thumbnail) > 10
 }
 
-func sortByPublishedDate(resp model.GoogleBookResponse, err error) (model.GoogleBookResponse, error) {
-	if err != nil {
-		return resp, err
+// filterPublishedRange keeps books whose publication date could fall within
+// the requested range. Both bounds are inclusive and books without a usable
+// date are dropped once either bound is set.
+func filterPublishedRange(book model.GoogleBookItem, req GoogleBookRequest) bool {
+	if req.PublishedAfter == "" && req.PublishedBefore == "" {
+		return true
 	}
-	slices.SortFunc(resp.Items, func(a, b model.GoogleBookItem) int {
-		return cmp.Compare(b.VolumeInfo.PublishedDate, a.VolumeInfo.PublishedDate)
-	})
-	return resp, err
-}
-
-func sortByDescLength(resp model.GoogleBookResponse, err error) (model.GoogleBookResponse, error) {
-	if err != nil {
-		return resp, err
+	published := model.ParsePublicationDate(book.VolumeInfo.PublishedDate)
+	if published.IsZero() {
+		return false
 	}
-	slices.SortFunc(resp.Items, func(a, b model.GoogleBookItem) int {
-		return cmp.Compare(len(b.VolumeInfo.Description), len(a.VolumeInfo.Description))
-	})
-	return resp, err
+	if after := model.ParsePublicationDate(req.PublishedAfter); !after.IsZero() && published.End().Before(after.Start()) {
+		return false
+	}
+	if before := model.ParsePublicationDate(req.PublishedBefore); !before.IsZero() && published.Start().After(before.End()) {
+		return false
+	}
+	return true
 }
 
 func (bc GoogleBookClient) bookRequest(ctx context.Context, query string, request GoogleBookRequest) (model.GoogleBookResponse, error) {
@@ -183,6 +299,9 @@ func (bc GoogleBookClient) bookRequest(ctx context.Context, query string, reques
 		return model.GoogleBookResponse{}, err
 	}
 	defer res.Body.Close()
+	if res.StatusCode == http.StatusTooManyRequests {
+		return model.GoogleBookResponse{}, ErrRateLimited
+	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -207,6 +326,7 @@ func buildRequestUrl(query string, request GoogleBookRequest) string {
 	queryParts := []requestPart{
 		{querystring: fmt.Sprintf("&startIndex=%s", url.QueryEscape(fmt.Sprint(request.Start))), valid: request.Start > 0},
 		{querystring: fmt.Sprintf("&maxResults=%s", url.QueryEscape(fmt.Sprint(request.Limit))), valid: request.Limit > 0},
+		{querystring: "&orderBy=newest", valid: request.SortBy == SortPublishedDesc},
 	}
 
 	fullUrl := fmt.Sprintf("https://www.googleapis.com/books/v1/volumes?q=%s", query)
@@ -236,6 +356,21 @@ func authorPact() (model.GoogleBookResponse, error) {
 	return books, nil
 }
 
+func (bc GoogleBookClient) volumePact(id string) (model.GoogleBookItem, error) {
+	for _, pact := range []func() (model.GoogleBookResponse, error){authorPact, titlePact} {
+		books, err := bc.remember(pact())
+		if err != nil {
+			return model.GoogleBookItem{}, err
+		}
+		for _, book := range books.Items {
+			if book.ID == id {
+				return book, nil
+			}
+		}
+	}
+	return model.GoogleBookItem{}, ErrVolumeNotFound
+}
+
 func titlePact() (model.GoogleBookResponse, error) {
 	var (
 		_, b, _, _ = runtime.Caller(0)
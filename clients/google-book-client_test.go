@@ -20,8 +20,9 @@ func mockGetData(data []byte, err error) func(string) (*http.Response, error) {
 			return nil, err
 		}
 		var res = http.Response{
-			Status: "200 OK",
-			Body:   io.NopCloser(bytes.NewReader(data)),
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(data)),
 		}
 		return &res, nil
 	}
@@ -711,6 +712,9 @@ func Test_filterAuthorResults(t *testing.T) {
 				if !reflect.DeepEqual(len(got.Items), tt.want.count) {
 					t.Errorf("filterResults() = %v, want %v", got, tt.want.count)
 				}
+				if got.Filtered != len(tt.args.resp.Items)-tt.want.count {
+					t.Errorf("filterResults() filtered %d, want %d", got.Filtered, len(tt.args.resp.Items)-tt.want.count)
+				}
 			}
 		})
 	}
@@ -783,6 +787,9 @@ func Test_buildRequestUrl(t *testing.T) {
 		Start: 42,
 		Limit: 99,
 	}
+	requestNewestFirst := GoogleBookRequest{
+		SortBy: SortPublishedDesc,
+	}
 	testQuery := "Test Query"
 	expectedUrl := fmt.Sprintf("https://www.googleapis.com/books/v1/volumes?q=%s", testQuery)
 	expectedUrlWithStart := fmt.Sprintf("https://www.googleapis.com/books/v1/volumes?q=%s&startIndex=%d", testQuery, requestWithStart.Start)
@@ -837,6 +844,14 @@ func Test_buildRequestUrl(t *testing.T) {
 			},
 			want: expectedUrlWithStartAndLimit,
 		},
+		{
+			name: "with newest first sorting",
+			args: args{
+				query:   testQuery,
+				request: requestNewestFirst,
+			},
+			want: expectedUrl + "&orderBy=newest",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1031,7 +1046,7 @@ func Test_filterHasImage(t *testing.T) {
 	}
 }
 
-func Test_sortByPublishedDate(t *testing.T) {
+func Test_sortResults_publishedDesc(t *testing.T) {
 	book1 := model.GoogleBookItem{
 		VolumeInfo: model.GoogleBookVolumeInfo{
 			PublishedDate: "2023-01-01",
@@ -1047,6 +1062,21 @@ func Test_sortByPublishedDate(t *testing.T) {
 			PublishedDate: "2021-01-01",
 		},
 	}
+	bookMonth := model.GoogleBookItem{
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			PublishedDate: "1984-06",
+		},
+	}
+	bookDecade := model.GoogleBookItem{
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			PublishedDate: "198*",
+		},
+	}
+	bookUnknown := model.GoogleBookItem{
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			PublishedDate: "",
+		},
+	}
 
 	type args struct {
 		resp model.GoogleBookResponse
@@ -1072,6 +1102,19 @@ func Test_sortByPublishedDate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "sorts partial dates chronologically",
+			args: args{
+				resp: model.GoogleBookResponse{
+					Items: []model.GoogleBookItem{bookUnknown, bookMonth, book1, bookDecade},
+				},
+				err: nil,
+			},
+			want: model.GoogleBookResponse{
+				Items: []model.GoogleBookItem{book1, bookMonth, bookDecade, bookUnknown},
+			},
+			wantErr: false,
+		},
 		{
 			name: "with and error param",
 			args: args{
@@ -1084,79 +1127,183 @@ func Test_sortByPublishedDate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := sortByPublishedDate(tt.args.resp, tt.args.err)
+			got, err := sortResults(SortPublishedDesc)(tt.args.resp, tt.args.err)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("sortByPublishedDate() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("sortResults() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("sortByPublishedDate() = %v, want %v", got, tt.want)
+				t.Errorf("sortResults() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_sortByDescLength(t *testing.T) {
-	book1 := model.GoogleBookItem{
-		VolumeInfo: model.GoogleBookVolumeInfo{
-			Description: "The very very longest description of all the books.",
-		},
-	}
-	book2 := model.GoogleBookItem{
-		VolumeInfo: model.GoogleBookVolumeInfo{
-			Description: "A Description",
-		},
+func Test_filterPublishedRange(t *testing.T) {
+	bookWithDate := func(date string) model.GoogleBookItem {
+		return model.GoogleBookItem{
+			VolumeInfo: model.GoogleBookVolumeInfo{
+				PublishedDate: date,
+			},
+		}
 	}
-	book3 := model.GoogleBookItem{
-		VolumeInfo: model.GoogleBookVolumeInfo{
-			// No description at all. In fact it's nil !!!
-		},
+	tests := []struct {
+		name string
+		book model.GoogleBookItem
+		req  GoogleBookRequest
+		want bool
+	}{
+		{name: "no range", book: bookWithDate(""), req: GoogleBookRequest{}, want: true},
+		{name: "after the lower bound", book: bookWithDate("1986-04"), req: GoogleBookRequest{PublishedAfter: "1985"}, want: true},
+		{name: "before the lower bound", book: bookWithDate("1984-06-08"), req: GoogleBookRequest{PublishedAfter: "1985"}, want: false},
+		{name: "year overlapping the lower bound", book: bookWithDate("1984"), req: GoogleBookRequest{PublishedAfter: "1984-06-08"}, want: true},
+		{name: "before the upper bound", book: bookWithDate("1984"), req: GoogleBookRequest{PublishedBefore: "1990"}, want: true},
+		{name: "after the upper bound", book: bookWithDate("2012"), req: GoogleBookRequest{PublishedBefore: "1990"}, want: false},
+		{name: "within both bounds", book: bookWithDate("198*"), req: GoogleBookRequest{PublishedAfter: "1985", PublishedBefore: "1985"}, want: true},
+		{name: "unknown date with a bound", book: bookWithDate(""), req: GoogleBookRequest{PublishedBefore: "1990"}, want: false},
 	}
-
-	type args struct {
-		resp model.GoogleBookResponse
-		err  error
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterPublishedRange(tt.book, tt.req); got != tt.want {
+				t.Errorf("filterPublishedRange() = %v, want %v", got, tt.want)
+			}
+		})
 	}
+}
 
+func TestGoogleBookRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
-		args    args
-		want    model.GoogleBookResponse
+		req     GoogleBookRequest
 		wantErr bool
+	}{
+		{name: "no dates", req: GoogleBookRequest{}, wantErr: false},
+		{name: "valid dates", req: GoogleBookRequest{PublishedAfter: "1984", PublishedBefore: "1990-06-01"}, wantErr: false},
+		{name: "invalid after", req: GoogleBookRequest{PublishedAfter: "last year"}, wantErr: true},
+		{name: "invalid before", req: GoogleBookRequest{PublishedBefore: "soon"}, wantErr: true},
+		{name: "valid sort", req: GoogleBookRequest{SortBy: SortRating}, wantErr: false},
+		{name: "invalid sort", req: GoogleBookRequest{SortBy: "popularity"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("GoogleBookRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGoogleBookClient_ByID(t *testing.T) {
+	notFound := func(url string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{"error": {"code": 404}}`))),
+		}, nil
+	}
+	status := func(code int, body string) func(url string) (*http.Response, error) {
+		return func(url string) (*http.Response, error) {
+			return &http.Response{StatusCode: code, Body: io.NopCloser(bytes.NewReader([]byte(body)))}, nil
+		}
+	}
+	cached := NewCatalog()
+	cached.Add(model.GoogleBookItem{ID: "cached-id", Kind: "cached"})
+
+	tests := []struct {
+		name     string
+		client   GoogleBookClient
+		id       string
+		wantKind string
+		wantErr  error
 	}{
 		{
-			name: "sorts by published date",
-			args: args{
-				resp: model.GoogleBookResponse{
-					Items: []model.GoogleBookItem{book2, book1, book3},
-				},
-				err: nil,
+			name: "success",
+			client: GoogleBookClient{
+				GetData: mockGetData([]byte(`{"kind": "books#volume", "id": "test-id"}`), nil),
 			},
-			want: model.GoogleBookResponse{
-				Items: []model.GoogleBookItem{book1, book2, book3},
+			id:       "test-id",
+			wantKind: "books#volume",
+		},
+		{
+			name: "from the catalog",
+			client: GoogleBookClient{
+				GetData: mockGetData(nil, errors.New("test - should not be called")),
+				Catalog: cached,
 			},
-			wantErr: false,
+			id:       "cached-id",
+			wantKind: "cached",
 		},
 		{
-			name: "with and error param",
-			args: args{
-				resp: model.GoogleBookResponse{},
-				err:  errors.New("test-error"),
+			name:    "not found",
+			client:  GoogleBookClient{GetData: notFound},
+			id:      "missing-id",
+			wantErr: ErrVolumeNotFound,
+		},
+		{
+			name:    "server error",
+			client:  GoogleBookClient{GetData: status(http.StatusInternalServerError, `{"error": {"code": 500}}`)},
+			id:      "test-id",
+			wantErr: errors.New("volume test-id: upstream status 500"),
+		},
+		{
+			name:    "quota exceeded",
+			client:  GoogleBookClient{GetData: status(http.StatusForbidden, `{"error": {"code": 403}}`)},
+			id:      "test-id",
+			wantErr: errors.New("volume test-id: upstream status 403"),
+		},
+		{
+			name:    "malformed body",
+			client:  GoogleBookClient{GetData: mockGetData([]byte(`{"id": "test-id"`), nil)},
+			id:      "test-id",
+			wantErr: errors.New("volume test-id: unexpected end of JSON input"),
+		},
+		{
+			name: "failure",
+			client: GoogleBookClient{
+				GetData: mockGetData(nil, errors.New("test - volume request fails")),
 			},
-			want:    model.GoogleBookResponse{},
-			wantErr: true,
+			id:      "test-id",
+			wantErr: errors.New("test - volume request fails"),
+		},
+		{
+			name:     "pact mode",
+			client:   GoogleBookClient{PactMode: true},
+			id:       "atw7PgAACAAJ",
+			wantKind: "books#volume",
+		},
+		{
+			name:    "pact mode not found",
+			client:  GoogleBookClient{PactMode: true},
+			id:      "missing-id",
+			wantErr: ErrVolumeNotFound,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := sortByDescLength(tt.args.resp, tt.args.err)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("sortByDescLength() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := tt.client.ByID(context.Background(), tt.id)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("sortByDescLength() = %v, want %v", got, tt.want)
-			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.id, got.ID)
+			assert.Equal(t, tt.wantKind, got.Kind)
 		})
 	}
 }
+
+func TestGoogleBookClient_rateLimited(t *testing.T) {
+	tooManyRequests := func(url string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{"error": {"code": 429}}`))),
+		}, nil
+	}
+	bc := GoogleBookClient{GetData: tooManyRequests}
+
+	_, err := bc.ByTitle(context.Background(), GoogleBookRequest{Title: "Count Zero"})
+	assert.ErrorIs(t, err, ErrRateLimited)
+	_, err = bc.ByAuthor(context.Background(), GoogleBookRequest{Author: "William Gibson"})
+	assert.ErrorIs(t, err, ErrRateLimited)
+	_, err = bc.ByID(context.Background(), "test-id")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
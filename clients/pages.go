@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+
+	model "example.com/book-learn/models"
+)
+
+// AuthorPage is one page of an author search as it arrives.
+type AuthorPage struct {
+	Index    int
+	Response model.GoogleBookResponse
+	Err      error
+}
+
+// StreamAuthorPages requests Pages+1 pages of an author search concurrently
+// and delivers each page as soon as it arrives. The channel is buffered for
+// every page, so callers may stop reading early, and is closed once all
+// pages have been delivered.
+func StreamAuthorPages(ctx context.Context, bookClient BookClientInterface, bookReq GoogleBookRequest) <-chan AuthorPage {
+	pages := make(chan AuthorPage, bookReq.Pages+1)
+	var wg sync.WaitGroup
+
+	fetch := func(page int) {
+		defer wg.Done()
+		// Fetch data from external API
+		req := bookReq
+		req.Start = bookReq.Start + page*bookReq.Limit
+		req.Pages = 0
+		resp, err := bookClient.ByAuthor(ctx, req)
+		slog.Info(req.Author, "Start", strconv.Itoa(req.Start), "limit", strconv.Itoa(req.Limit), "Pages", strconv.Itoa(req.Pages))
+		pages <- AuthorPage{Index: page, Response: resp, Err: err}
+	}
+	for i := 0; i <= bookReq.Pages; i++ {
+		wg.Add(1)
+		go fetch(i)
+	}
+	go func() {
+		wg.Wait()
+		close(pages)
+	}()
+	return pages
+}
+
+// FetchAuthorPages requests Pages+1 pages of an author search concurrently.
+// Pages are returned in order so merged results keep the upstream order.
+func FetchAuthorPages(ctx context.Context, bookClient BookClientInterface, bookReq GoogleBookRequest) ([]model.GoogleBookResponse, error) {
+	pages := make([]model.GoogleBookResponse, bookReq.Pages+1)
+	errs := make([]error, bookReq.Pages+1)
+	for page := range StreamAuthorPages(ctx, bookClient, bookReq) {
+		pages[page.Index], errs[page.Index] = page.Response, page.Err
+	}
+	return pages, errors.Join(errs...)
+}
+
+// HasMorePages reports whether the author search has pages beyond those
+// requested.
+func HasMorePages(totalItems int, bookReq GoogleBookRequest) bool {
+	return int(math.Ceil(float64(totalItems)/float64(bookReq.Limit))-float64(bookReq.Pages)) > 0
+}
+
+// MergeAuthorPages combines pages into one result set. Editions of a work can
+// be split across pages, and each page was only sorted on its own, so the
+// merged books are regrouped and sorted again.
+func MergeAuthorPages(pages []model.GoogleBookResponse, sortBy string) ([]model.GoogleBookItem, int) {
+	var books []model.GoogleBookItem
+	totalItems := 0
+
+	for _, result := range pages {
+		if totalItems == 0 {
+			totalItems = result.TotalItems
+		}
+		books = append(books, result.Items...)
+	}
+	books = GroupEditions(books)
+	SortItems(books, sortBy)
+	return books, totalItems
+}
+
+// UnsentWorks returns the works on a streamed page that have not been sent
+// yet, marking them and their editions as sent. Editions split across pages
+// are not regrouped, so a work is only ever sent once.
+func UnsentWorks(page model.GoogleBookResponse, sent map[string]bool) []model.GoogleBookItem {
+	works := []model.GoogleBookItem{}
+	for _, work := range GroupEditions(page.Items) {
+		if sent[work.ID] {
+			continue
+		}
+		sent[work.ID] = true
+		for _, edition := range work.Editions {
+			sent[edition.ID] = true
+		}
+		works = append(works, work)
+	}
+	return works
+}
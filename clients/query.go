@@ -0,0 +1,405 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	model "example.com/book-learn/models"
+)
+
+// Query fields. Text fields are sent to Google as qualifiers; range and
+// language fields can only be checked locally.
+const (
+	QueryFieldText      = ""
+	QueryFieldTitle     = "title"
+	QueryFieldAuthor    = "author"
+	QueryFieldPublisher = "publisher"
+	QueryFieldSubject   = "subject"
+	QueryFieldISBN      = "isbn"
+	QueryFieldYear      = "year"
+	QueryFieldPages     = "pages"
+	QueryFieldLanguage  = "lang"
+)
+
+var queryFieldAliases = map[string]string{
+	"title":     QueryFieldTitle,
+	"intitle":   QueryFieldTitle,
+	"author":    QueryFieldAuthor,
+	"inauthor":  QueryFieldAuthor,
+	"publisher": QueryFieldPublisher,
+	"subject":   QueryFieldSubject,
+	"category":  QueryFieldSubject,
+	"isbn":      QueryFieldISBN,
+	"year":      QueryFieldYear,
+	"pages":     QueryFieldPages,
+	"lang":      QueryFieldLanguage,
+	"language":  QueryFieldLanguage,
+}
+
+var googleQualifiers = map[string]string{
+	QueryFieldTitle:     "intitle:",
+	QueryFieldAuthor:    "inauthor:",
+	QueryFieldPublisher: "inpublisher:",
+	QueryFieldSubject:   "subject:",
+	QueryFieldISBN:      "isbn:",
+}
+
+// SyntaxError reports where a search query could not be parsed. Position and
+// End are zero-based character offsets into the query, End exclusive, so a
+// UI can highlight the offending text.
+type SyntaxError struct {
+	Position int
+	End      int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("query: %s at position %d", e.Message, e.Position)
+}
+
+// QueryClause is a single term, phrase, qualifier or range in a query.
+type QueryClause struct {
+	Field   string
+	Value   string
+	Phrase  bool
+	Negated bool
+	// Min and Max bound year and pages clauses, both inclusive.
+	Min int
+	Max int
+}
+
+// SearchQuery is a parsed free-text query such as
+// `author:gibson year:>1990 -"count zero" neuromancer`.
+type SearchQuery struct {
+	Clauses []QueryClause
+}
+
+// ParseSearchQuery parses the query language:
+//
+//	neuromancer            a term matched anywhere
+//	"count zero"           a phrase
+//	author:gibson          a field qualifier, also title, publisher, subject, isbn and lang
+//	title:"count zero"     a qualifier with a phrase
+//	-cyberpunk             negation of any of the above
+//	year:1984              years and page counts match exactly,
+//	year:>1990 pages:<=300 by comparison,
+//	year:1980..1989        or by inclusive range
+func ParseSearchQuery(raw string) (SearchQuery, error) {
+	p := queryParser{input: []rune(raw)}
+	query := SearchQuery{}
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		clause, err := p.clause()
+		if err != nil {
+			return SearchQuery{}, err
+		}
+		query.Clauses = append(query.Clauses, clause)
+	}
+	if !slices.ContainsFunc(query.Clauses, QueryClause.searchable) {
+		return SearchQuery{}, &SyntaxError{Position: 0, End: len(p.input), Message: "query needs a term or a title, author, publisher, subject or isbn qualifier"}
+	}
+	return query, nil
+}
+
+type queryParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *queryParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *queryParser) skipSpace() {
+	for !p.done() && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *queryParser) clause() (QueryClause, error) {
+	start := p.pos
+	clause := QueryClause{}
+	if p.input[p.pos] == '-' {
+		clause.Negated = true
+		p.pos++
+		if p.done() || unicode.IsSpace(p.input[p.pos]) {
+			return clause, &SyntaxError{Position: start, End: start + 1, Message: "nothing to negate"}
+		}
+	}
+	if p.input[p.pos] == '"' {
+		value, err := p.phrase()
+		clause.Value, clause.Phrase = value, true
+		return clause, err
+	}
+
+	wordStart := p.pos
+	word, err := p.word()
+	if err != nil {
+		return clause, err
+	}
+	name, value, qualified := strings.Cut(word, ":")
+	if !qualified {
+		clause.Value = word
+		return clause, nil
+	}
+	field, ok := queryFieldAliases[strings.ToLower(name)]
+	if !ok {
+		return clause, &SyntaxError{Position: wordStart, End: wordStart + len([]rune(name)), Message: fmt.Sprintf("unknown field %q", name)}
+	}
+	clause.Field = field
+
+	valueStart := wordStart + len([]rune(name)) + 1
+	if value == "" && !p.done() && p.input[p.pos] == '"' {
+		value, err = p.phrase()
+		if err != nil {
+			return clause, err
+		}
+		clause.Phrase = true
+	}
+	if strings.TrimSpace(value) == "" {
+		return clause, &SyntaxError{Position: wordStart, End: valueStart, Message: fmt.Sprintf("missing value for %s", name)}
+	}
+	clause.Value = value
+
+	if field == QueryFieldYear || field == QueryFieldPages {
+		clause.Min, clause.Max, ok = parseQueryRange(value)
+		if field == QueryFieldYear {
+			ok = ok && validQueryYear(clause.Min) && validQueryYear(clause.Max)
+		}
+		if !ok || clause.Phrase {
+			return clause, &SyntaxError{Position: valueStart, End: p.pos, Message: fmt.Sprintf("invalid %s range %q", field, value)}
+		}
+	}
+	return clause, nil
+}
+
+// word reads up to the next space or opening quote. A quote inside a word is
+// only allowed straight after a field's colon.
+func (p *queryParser) word() (string, error) {
+	start := p.pos
+	for !p.done() && !unicode.IsSpace(p.input[p.pos]) {
+		if p.input[p.pos] == '"' {
+			if p.pos > start && p.input[p.pos-1] == ':' {
+				break
+			}
+			return "", &SyntaxError{Position: p.pos, End: p.pos + 1, Message: "unexpected quote"}
+		}
+		p.pos++
+	}
+	return string(p.input[start:p.pos]), nil
+}
+
+// phrase reads a quoted phrase starting at the opening quote.
+func (p *queryParser) phrase() (string, error) {
+	start := p.pos
+	p.pos++
+	for !p.done() && p.input[p.pos] != '"' {
+		p.pos++
+	}
+	if p.done() {
+		return "", &SyntaxError{Position: start, End: len(p.input), Message: "unterminated quote"}
+	}
+	p.pos++
+	value := strings.Join(strings.Fields(string(p.input[start+1:p.pos-1])), " ")
+	if value == "" {
+		return "", &SyntaxError{Position: start, End: p.pos, Message: "empty phrase"}
+	}
+	return value, nil
+}
+
+// maxQueryYear is the latest year a year range may name.
+const maxQueryYear = 9999
+
+// parseQueryRange parses "1984", ">1990", ">=1990", "<2000", "<=2000" and
+// "1980..1989" into inclusive bounds. Bounds that would overflow are
+// rejected.
+func parseQueryRange(value string) (int, int, bool) {
+	if low, high, ok := strings.Cut(value, ".."); ok {
+		minimum, maximum := math.MinInt, math.MaxInt
+		var err error
+		if low != "" {
+			if minimum, err = strconv.Atoi(low); err != nil {
+				return 0, 0, false
+			}
+		}
+		if high != "" {
+			if maximum, err = strconv.Atoi(high); err != nil {
+				return 0, 0, false
+			}
+		}
+		return minimum, maximum, (low != "" || high != "") && minimum <= maximum
+	}
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if rest, ok := strings.CutPrefix(value, op); ok {
+			n, err := strconv.Atoi(rest)
+			if err != nil {
+				return 0, 0, false
+			}
+			switch op {
+			case ">=":
+				return n, math.MaxInt, true
+			case "<=":
+				return math.MinInt, n, true
+			case ">":
+				return n + 1, math.MaxInt, n < math.MaxInt
+			default:
+				return math.MinInt, n - 1, n > math.MinInt
+			}
+		}
+	}
+	n, err := strconv.Atoi(value)
+	return n, n, err == nil
+}
+
+// validQueryYear reports whether a year range bound is open or a year
+// between 0 and maxQueryYear.
+func validQueryYear(year int) bool {
+	return year == math.MinInt || year == math.MaxInt || (year >= 0 && year <= maxQueryYear)
+}
+
+// searchable reports whether Google can look the clause up on its own, rather
+// than only filter out what else was found.
+func (c QueryClause) searchable() bool {
+	return !c.Negated && (c.Field == QueryFieldText || googleQualifiers[c.Field] != "")
+}
+
+// GoogleQuery compiles the clauses Google can search into a q= value. Terms
+// and phrases are sent as they are, negated ones with a leading minus, and
+// positive qualifiers become Google's intitle:, inauthor: and so on.
+func (q SearchQuery) GoogleQuery() string {
+	parts := []string{}
+	for _, clause := range q.Clauses {
+		value := clause.Value
+		if clause.Phrase {
+			value = `"` + value + `"`
+		}
+		switch {
+		case clause.Field == QueryFieldText && clause.Negated:
+			parts = append(parts, "-"+url.QueryEscape(value))
+		case clause.Field == QueryFieldText:
+			parts = append(parts, url.QueryEscape(value))
+		case googleQualifiers[clause.Field] != "" && !clause.Negated:
+			parts = append(parts, googleQualifiers[clause.Field]+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// String renders the query back into the query language. Values that would
+// not parse as a bare word are quoted.
+func (q SearchQuery) String() string {
+	parts := []string{}
+	for _, clause := range q.Clauses {
+		part := clause.Value
+		if clause.Phrase || strings.ContainsAny(part, ": \"") || strings.HasPrefix(part, "-") {
+			part = `"` + strings.ReplaceAll(part, `"`, "") + `"`
+		}
+		if clause.Field != QueryFieldText {
+			part = clause.Field + ":" + part
+		}
+		if clause.Negated {
+			part = "-" + part
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Matches applies the clauses Google cannot be trusted with: negations,
+// qualifiers, ranges and languages. Positive terms and phrases are left to
+// Google since they may match text it does not return.
+func (q SearchQuery) Matches(book model.GoogleBookItem) bool {
+	for _, clause := range q.Clauses {
+		if clause.Field == QueryFieldText && !clause.Negated {
+			continue
+		}
+		if clause.matches(book) == clause.Negated {
+			return false
+		}
+	}
+	return true
+}
+
+func (c QueryClause) matches(book model.GoogleBookItem) bool {
+	info := book.VolumeInfo
+	switch c.Field {
+	case QueryFieldTitle:
+		return containsFold(info.Title+" "+info.Subtitle, c.Value)
+	case QueryFieldAuthor:
+		return slices.ContainsFunc(info.Authors, func(author string) bool { return containsFold(author, c.Value) })
+	case QueryFieldPublisher:
+		return containsFold(info.Publisher, c.Value)
+	case QueryFieldSubject:
+		return slices.ContainsFunc(info.Categories, func(category string) bool { return containsFold(category, c.Value) })
+	case QueryFieldISBN:
+		return slices.ContainsFunc(info.IndustryIdentifiers, func(id model.GoogleBookIndustryIdentifier) bool {
+			return isbnDigits(id.Identifier) == isbnDigits(c.Value)
+		})
+	case QueryFieldYear:
+		year := model.ParsePublicationDate(info.PublishedDate).Year
+		return year != 0 && year >= c.Min && year <= c.Max
+	case QueryFieldPages:
+		return info.PageCount > 0 && info.PageCount >= c.Min && info.PageCount <= c.Max
+	case QueryFieldLanguage:
+		return strings.EqualFold(strings.SplitN(info.Language, "-", 2)[0], strings.SplitN(c.Value, "-", 2)[0])
+	default:
+		text := strings.Join(append([]string{info.Title, info.Subtitle, info.Description, info.Publisher}, append(info.Authors, info.Categories...)...), " ")
+		return containsFold(text, c.Value)
+	}
+}
+
+// scoringRequest fills in the title and author that relevance scoring
+// compares results against.
+func (q SearchQuery) scoringRequest(request GoogleBookRequest) GoogleBookRequest {
+	titles, authors := []string{}, []string{}
+	for _, clause := range q.Clauses {
+		switch {
+		case clause.Negated:
+		case clause.Field == QueryFieldTitle, clause.Field == QueryFieldText:
+			titles = append(titles, clause.Value)
+		case clause.Field == QueryFieldAuthor:
+			authors = append(authors, clause.Value)
+		}
+	}
+	request.Title = strings.Join(titles, " ")
+	request.Author = strings.Join(authors, " ")
+	return request
+}
+
+func filterQueryResults(query SearchQuery) func(model.GoogleBookResponse, error) (model.GoogleBookResponse, error) {
+	return func(resp model.GoogleBookResponse, err error) (model.GoogleBookResponse, error) {
+		if err != nil {
+			return resp, err
+		}
+		filtered := []model.GoogleBookItem{}
+		for _, book := range resp.Items {
+			if query.Matches(book) {
+				filtered = append(filtered, book)
+			}
+		}
+		resp.Filtered += len(resp.Items) - len(filtered)
+		resp.Items = filtered
+		return resp, err
+	}
+}
+
+func containsFold(text string, value string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(value))
+}
+
+func isbnDigits(isbn string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == 'X' || r == 'x' {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, isbn)
+}
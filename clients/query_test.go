@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []QueryClause
+	}{
+		{
+			name: "terms, qualifiers and ranges",
+			raw:  "author:gibson year:>1990 neuromancer",
+			expected: []QueryClause{
+				{Field: QueryFieldAuthor, Value: "gibson"},
+				{Field: QueryFieldYear, Value: ">1990", Min: 1991, Max: math.MaxInt},
+				{Value: "neuromancer"},
+			},
+		},
+		{
+			name: "phrases and negation",
+			raw:  `"count zero" -title:"mona lisa" -cyberpunk`,
+			expected: []QueryClause{
+				{Value: "count zero", Phrase: true},
+				{Field: QueryFieldTitle, Value: "mona lisa", Phrase: true, Negated: true},
+				{Value: "cyberpunk", Negated: true},
+			},
+		},
+		{
+			name: "range forms and aliases",
+			raw:  "inauthor:gibson year:1980..1989 pages:<=300 year:1984 year:..1990 pages:<100 language:en",
+			expected: []QueryClause{
+				{Field: QueryFieldAuthor, Value: "gibson"},
+				{Field: QueryFieldYear, Value: "1980..1989", Min: 1980, Max: 1989},
+				{Field: QueryFieldPages, Value: "<=300", Min: math.MinInt, Max: 300},
+				{Field: QueryFieldYear, Value: "1984", Min: 1984, Max: 1984},
+				{Field: QueryFieldYear, Value: "..1990", Min: math.MinInt, Max: 1990},
+				{Field: QueryFieldPages, Value: "<100", Min: math.MinInt, Max: 99},
+				{Field: QueryFieldLanguage, Value: "en"},
+			},
+		},
+		{
+			name: "hyphenated words are not negated",
+			raw:  "sci-fi",
+			expected: []QueryClause{
+				{Value: "sci-fi"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSearchQuery(tt.raw)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got.Clauses)
+		})
+	}
+}
+
+func TestParseSearchQuery_errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected SyntaxError
+	}{
+		{name: "unknown field", raw: "neuromancer colour:blue", expected: SyntaxError{Position: 12, End: 18, Message: `unknown field "colour"`}},
+		{name: "unterminated quote", raw: `gibson "count zero`, expected: SyntaxError{Position: 7, End: 18, Message: "unterminated quote"}},
+		{name: "empty phrase", raw: `gibson ""`, expected: SyntaxError{Position: 7, End: 9, Message: "empty phrase"}},
+		{name: "missing value", raw: "author: gibson", expected: SyntaxError{Position: 0, End: 7, Message: "missing value for author"}},
+		{name: "invalid range", raw: "gibson year:>nineteen", expected: SyntaxError{Position: 12, End: 21, Message: `invalid year range ">nineteen"`}},
+		{name: "overflowing range", raw: "gibson pages:>9223372036854775807", expected: SyntaxError{Position: 13, End: 33, Message: `invalid pages range ">9223372036854775807"`}},
+		{name: "out of range year", raw: "gibson year:<-9223372036854775808", expected: SyntaxError{Position: 12, End: 33, Message: `invalid year range "<-9223372036854775808"`}},
+		{name: "year too late", raw: "gibson year:>=100000", expected: SyntaxError{Position: 12, End: 20, Message: `invalid year range ">=100000"`}},
+		{name: "backwards range", raw: "gibson year:1990..1980", expected: SyntaxError{Position: 12, End: 22, Message: `invalid year range "1990..1980"`}},
+		{name: "dangling negation", raw: "gibson - zero", expected: SyntaxError{Position: 7, End: 8, Message: "nothing to negate"}},
+		{name: "stray quote", raw: `count"zero`, expected: SyntaxError{Position: 5, End: 6, Message: "unexpected quote"}},
+		{name: "positions count characters", raw: `Zéro colour:blue`, expected: SyntaxError{Position: 5, End: 11, Message: `unknown field "colour"`}},
+		{name: "nothing google can search", raw: "year:>1990 -gibson", expected: SyntaxError{Position: 0, End: 18, Message: "query needs a term or a title, author, publisher, subject or isbn qualifier"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSearchQuery(tt.raw)
+			var syntaxErr *SyntaxError
+			if assert.ErrorAs(t, err, &syntaxErr) {
+				assert.Equal(t, tt.expected, *syntaxErr)
+			}
+		})
+	}
+}
+
+func TestSearchQuery_String(t *testing.T) {
+	raw := `author:gibson year:>1990 "count zero" -cyberpunk -title:"mona lisa"`
+	query, err := ParseSearchQuery(raw)
+
+	assert.NoError(t, err)
+	assert.Equal(t, raw, query.String())
+	query.Clauses[2] = QueryClause{Value: "Boyology: Or Boy Analysis"}
+	assert.Equal(t, `author:gibson year:>1990 "Boyology: Or Boy Analysis" -cyberpunk -title:"mona lisa"`, query.String())
+}
+
+func TestSearchQuery_GoogleQuery(t *testing.T) {
+	query, err := ParseSearchQuery(`author:gibson year:>1990 "count zero" -cyberpunk -author:sterling isbn:978-0441569595`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "inauthor:gibson+%22count+zero%22+-cyberpunk+isbn:978-0441569595", query.GoogleQuery())
+}
+
+func TestSearchQuery_Matches(t *testing.T) {
+	book := model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{
+		Title:               "Count Zero",
+		Authors:             []string{"William Gibson"},
+		Publisher:           "Ace Books",
+		PublishedDate:       "1986-03",
+		PageCount:           256,
+		Categories:          []string{"Fiction / Science Fiction / Cyberpunk"},
+		Language:            "en",
+		Description:         "Turner is a corporate mercenary.",
+		IndustryIdentifiers: []model.GoogleBookIndustryIdentifier{{Type: "ISBN_13", Identifier: "9780441117732"}},
+	}}
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{query: "author:gibson zero", expected: true},
+		{query: "author:sterling zero", expected: false},
+		{query: "zero -author:sterling", expected: true},
+		{query: "zero -mercenary", expected: false},
+		{query: `title:"count zero" year:1980..1989 pages:>200`, expected: true},
+		{query: "zero year:>1990", expected: false},
+		{query: "zero -year:>1990", expected: true},
+		{query: "zero pages:<200", expected: false},
+		{query: "zero subject:cyberpunk lang:en-GB", expected: true},
+		{query: "zero lang:fr", expected: false},
+		{query: "isbn:978-0-441-11773-2", expected: true},
+		{query: "publisher:tor zero", expected: false},
+		{query: "unrelated words", expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseSearchQuery(tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, query.Matches(book))
+		})
+	}
+}
+
+func TestGoogleBookClient_ByQuery(t *testing.T) {
+	bc := GoogleBookClient{PactMode: true}
+
+	resp, err := bc.ByQuery(context.Background(), GoogleBookRequest{Query: "gibson author:henry -title:twenty year:>2000"})
+
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, book := range resp.Items {
+		ids = append(ids, book.ID)
+	}
+	assert.Equal(t, []string{"hNgmLwEACAAJ", "8j5RvgAACAAJ"}, ids)
+
+	_, err = bc.ByQuery(context.Background(), GoogleBookRequest{Query: "colour:blue"})
+	assert.ErrorAs(t, err, new(*SyntaxError))
+}
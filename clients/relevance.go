@@ -0,0 +1,223 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+
+	model "example.com/book-learn/models"
+)
+
+// RelevanceWeights controls how much each signal contributes to a volume's
+// relevance score. Derivative is a penalty and is subtracted from the total.
+type RelevanceWeights struct {
+	TitleMatch          float64  `json:"titleMatch"`
+	AuthorMatch         float64  `json:"authorMatch"`
+	Completeness        float64  `json:"completeness"`
+	PageCount           float64  `json:"pageCount"`
+	Publisher           float64  `json:"publisher"`
+	Derivative          float64  `json:"derivative"`
+	ReputablePublishers []string `json:"reputablePublishers"`
+	DerivativeTerms     []string `json:"derivativeTerms"`
+}
+
+var DefaultRelevanceWeights = RelevanceWeights{
+	TitleMatch:   4,
+	AuthorMatch:  3,
+	Completeness: 2,
+	PageCount:    1,
+	Publisher:    1,
+	Derivative:   6,
+	ReputablePublishers: []string{
+		"ace", "bantam", "bloomsbury", "cambridge university press", "del rey", "doubleday",
+		"faber", "hachette", "harpercollins", "knopf", "macmillan", "orbit", "oxford university press",
+		"penguin", "putnam", "random house", "scribner", "simon & schuster", "tor", "vintage",
+	},
+	DerivativeTerms: []string{
+		"study guide", "sparknotes", "cliffsnotes", "cliffs notes", "summary", "reader's guide",
+		"readers guide", "bookrags", "gradesaver", "supersummary", "quicklet", "literature guide",
+	},
+}
+
+// LoadRelevanceWeights reads weights from a JSON file. Fields missing from the
+// file keep their default values.
+func LoadRelevanceWeights(path string) (RelevanceWeights, error) {
+	weights := DefaultRelevanceWeights
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return weights, err
+	}
+	err = json.Unmarshal(data, &weights)
+	return weights, err
+}
+
+func (bc GoogleBookClient) relevanceWeights() RelevanceWeights {
+	if bc.Weights == nil {
+		return DefaultRelevanceWeights
+	}
+	return *bc.Weights
+}
+
+// scoreResults attaches a relevance score to every item so results can be
+// sorted by relevance and the score explained to debugging callers.
+func scoreResults(req GoogleBookRequest, weights RelevanceWeights) func(model.GoogleBookResponse, error) (model.GoogleBookResponse, error) {
+	return func(resp model.GoogleBookResponse, err error) (model.GoogleBookResponse, error) {
+		if err != nil {
+			return resp, err
+		}
+		for i := range resp.Items {
+			resp.Items[i].Relevance = scoreRelevance(resp.Items[i], req, weights)
+		}
+		return resp, err
+	}
+}
+
+func scoreRelevance(book model.GoogleBookItem, req GoogleBookRequest, weights RelevanceWeights) *model.RelevanceScore {
+	score := &model.RelevanceScore{}
+	add := func(signal string, value float64, weight float64) {
+		component := model.RelevanceComponent{Signal: signal, Value: value, Weight: weight, Score: value * weight}
+		score.Components = append(score.Components, component)
+		score.Total += component.Score
+	}
+	if req.Title != "" {
+		add("titleMatch", titleMatchQuality(book.VolumeInfo.Title, req.Title), weights.TitleMatch)
+	}
+	if req.Author != "" {
+		add("authorMatch", authorMatchQuality(book.VolumeInfo.Authors, req.Author), weights.AuthorMatch)
+	}
+	add("completeness", editionCompleteness(book), weights.Completeness)
+	add("pageCount", pageCountPlausibility(book.VolumeInfo.PageCount), weights.PageCount)
+	add("publisher", publisherReputation(book.VolumeInfo.Publisher, weights.ReputablePublishers), weights.Publisher)
+	add("derivative", derivativeWork(book, weights.DerivativeTerms), -weights.Derivative)
+	return score
+}
+
+// titleMatchQuality is 1 for an exact match, 0.75 when the title starts with
+// the query, 0.5 when it contains it and token overlap otherwise.
+func titleMatchQuality(title string, query string) float64 {
+	normalTitle, normalQuery := normalizeString(title), normalizeString(query)
+	switch {
+	case normalTitle == "" || normalQuery == "":
+		return 0
+	case normalTitle == normalQuery:
+		return 1
+	case strings.HasPrefix(normalTitle, normalQuery):
+		return 0.75
+	case strings.Contains(normalTitle, normalQuery):
+		return 0.5
+	}
+	return tokenOverlap(title, query) * 0.5
+}
+
+func authorMatchQuality(authors []string, query string) float64 {
+	best := 0.0
+	normalQuery := normalizeString(query)
+	for _, author := range authors {
+		normalAuthor := normalizeString(author)
+		switch {
+		case normalAuthor == normalQuery:
+			return 1
+		case strings.Contains(normalAuthor, normalQuery):
+			best = max(best, 0.6)
+		default:
+			best = max(best, tokenOverlap(author, query)*0.5)
+		}
+	}
+	return best
+}
+
+func editionCompleteness(book model.GoogleBookItem) float64 {
+	hasISBN := slices.ContainsFunc(book.VolumeInfo.IndustryIdentifiers, func(id model.GoogleBookIndustryIdentifier) bool {
+		return strings.HasPrefix(id.Type, "ISBN")
+	})
+	complete := 0.0
+	for _, ok := range []bool{filterHasImage(book), filterHasDescription(book), hasISBN} {
+		if ok {
+			complete++
+		}
+	}
+	return complete / 3
+}
+
+func pageCountPlausibility(pages int) float64 {
+	switch {
+	case pages <= 0:
+		return 0
+	case pages < 48:
+		return 0.2
+	case pages < 100:
+		return 0.6
+	case pages <= 1500:
+		return 1
+	}
+	return 0.5
+}
+
+// publisherReputation is 1 when a reputable name appears in the publisher as
+// whole words, so "Ace Books" matches "ace" but "CreateSpace" does not.
+func publisherReputation(publisher string, reputable []string) float64 {
+	words := tokenize(publisher)
+	for _, name := range reputable {
+		if containsWords(words, tokenize(name)) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// containsWords reports whether phrase occurs as a contiguous run in words.
+func containsWords(words []string, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(phrase)], phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// derivativeWork is 1 when the title, subtitle or publisher name a derivative
+// term and 0.5 when only the description does.
+func derivativeWork(book model.GoogleBookItem, terms []string) float64 {
+	vi := book.VolumeInfo
+	heading := strings.ToLower(strings.Join([]string{vi.Title, vi.Subtitle, vi.Publisher}, " "))
+	description := strings.ToLower(vi.Description)
+	result := 0.0
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		if strings.Contains(heading, term) {
+			return 1
+		}
+		if strings.Contains(description, term) {
+			result = 0.5
+		}
+	}
+	return result
+}
+
+func tokenOverlap(a string, b string) float64 {
+	tokensA, tokensB := tokenize(a), tokenize(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+	shared := 0
+	for _, token := range tokensB {
+		if slices.Contains(tokensA, token) {
+			shared++
+		}
+	}
+	return float64(shared) / float64(max(len(tokensA), len(tokensB)))
+}
+
+func tokenize(s string) []string {
+	tokens := []string{}
+	for _, field := range strings.Fields(strings.ToLower(s)) {
+		if token := normalizeString(field); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
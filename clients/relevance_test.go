@@ -0,0 +1,197 @@
+package client
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_scoreResults(t *testing.T) {
+	novel := model.GoogleBookItem{
+		ID: "novel",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:       "Neuromancer",
+			Authors:     []string{"William Gibson"},
+			Publisher:   "Ace Books",
+			Description: "The Matrix is a world within the world.",
+			PageCount:   271,
+			ImageLinks: model.GoogleBookImageLinks{
+				Thumbnail: "http://example.com/neuromancer.jpg",
+			},
+			IndustryIdentifiers: []model.GoogleBookIndustryIdentifier{
+				{Type: "ISBN_13", Identifier: "9780441569595"},
+			},
+		},
+	}
+	studyGuide := model.GoogleBookItem{
+		ID: "study-guide",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:       "Neuromancer",
+			Subtitle:    "A Study Guide",
+			Authors:     []string{"Gale"},
+			Description: "A much much much longer description than the novel has, because study guides are wordy.",
+			PageCount:   40,
+			ImageLinks: model.GoogleBookImageLinks{
+				Thumbnail: "http://example.com/guide.jpg",
+			},
+		},
+	}
+	closeTitle := model.GoogleBookItem{
+		ID: "close-title",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:   "Neuromancer: The Graphic Novel",
+			Authors: []string{"William Gibson"},
+		},
+	}
+
+	type args struct {
+		req  GoogleBookRequest
+		resp model.GoogleBookResponse
+		err  error
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name: "ranks the novel above derivative works",
+			args: args{
+				req: GoogleBookRequest{Title: "Neuromancer", Author: "William Gibson"},
+				resp: model.GoogleBookResponse{
+					Items: []model.GoogleBookItem{studyGuide, closeTitle, novel},
+				},
+			},
+			wantIDs: []string{"novel", "close-title", "study-guide"},
+		},
+		{
+			name: "with an error param",
+			args: args{
+				err: errors.New("test-error"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortResults(SortRelevance)(scoreResults(tt.args.req, DefaultRelevanceWeights)(tt.args.resp, tt.args.err))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("scoreResults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			gotIDs := []string{}
+			for _, item := range got.Items {
+				assert.NotNil(t, item.Relevance)
+				gotIDs = append(gotIDs, item.ID)
+			}
+			if tt.wantIDs != nil {
+				assert.Equal(t, tt.wantIDs, gotIDs)
+			}
+		})
+	}
+}
+
+func Test_scoreRelevance(t *testing.T) {
+	book := model.GoogleBookItem{
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:   "Count Zero",
+			Authors: []string{"William Gibson"},
+		},
+	}
+	weights := RelevanceWeights{TitleMatch: 2, AuthorMatch: 1}
+
+	got := scoreRelevance(book, GoogleBookRequest{Title: "Count Zero", Author: "William Gibson"}, weights)
+
+	assert.Equal(t, 3.0, got.Total)
+	assert.Len(t, got.Components, 6)
+	assert.Equal(t, "titleMatch", got.Components[0].Signal)
+	assert.Equal(t, 2.0, got.Components[0].Score)
+}
+
+func Test_titleMatchQuality(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		query string
+		want  float64
+	}{
+		{name: "exact", title: "Count Zero", query: "count zero", want: 1},
+		{name: "prefix", title: "Count Zero: A Novel", query: "Count Zero", want: 0.75},
+		{name: "contains", title: "The Count Zero Companion", query: "Count Zero", want: 0.5},
+		{name: "token overlap", title: "Zero History", query: "Count Zero", want: 0.25},
+		{name: "no match", title: "Idoru", query: "Count Zero", want: 0},
+		{name: "empty title", title: "", query: "Count Zero", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, titleMatchQuality(tt.title, tt.query))
+		})
+	}
+}
+
+func Test_publisherReputation(t *testing.T) {
+	tests := []struct {
+		name      string
+		publisher string
+		want      float64
+	}{
+		{name: "imprint", publisher: "Ace Books", want: 1},
+		{name: "several words", publisher: "Simon & Schuster UK", want: 1},
+		{name: "inside a word", publisher: "CreateSpace Independent Publishing Platform", want: 0},
+		{name: "inside another name", publisher: "Editorial Victoria", want: 0},
+		{name: "empty", publisher: "", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, publisherReputation(tt.publisher, DefaultRelevanceWeights.ReputablePublishers))
+		})
+	}
+}
+
+func Test_derivativeWork(t *testing.T) {
+	tests := []struct {
+		name string
+		book model.GoogleBookItem
+		want float64
+	}{
+		{
+			name: "derivative title",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "SparkNotes: Neuromancer"}},
+			want: 1,
+		},
+		{
+			name: "derivative description",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Description: "A summary of the novel"}},
+			want: 0.5,
+		},
+		{
+			name: "original work",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer"}},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, derivativeWork(tt.book, DefaultRelevanceWeights.DerivativeTerms))
+		})
+	}
+}
+
+func TestLoadRelevanceWeights(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.json")
+	os.WriteFile(path, []byte(`{"titleMatch": 10}`), 0o644)
+
+	got, err := LoadRelevanceWeights(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, got.TitleMatch)
+	assert.Equal(t, DefaultRelevanceWeights.AuthorMatch, got.AuthorMatch)
+
+	_, err = LoadRelevanceWeights(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
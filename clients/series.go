@@ -0,0 +1,106 @@
+package client
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	model "example.com/book-learn/models"
+)
+
+const (
+	SeriesSourceGoogle = "google"
+	SeriesSourceTitle  = "title"
+)
+
+var (
+	// "Mona Lisa Overdrive (Sprawl #3)", "Dune Messiah (Dune Chronicles, #2)"
+	seriesHashPattern = regexp.MustCompile(`(?i)\(\s*([^()#]+?)\s*,?\s*#\s*(\d+)\s*\)`)
+	// "Count Zero (Sprawl Trilogy Book 2)", "Children of Dune (Dune, Vol. 3)"
+	seriesParenPattern = regexp.MustCompile(`(?i)\(\s*([^()]+?)\s*,?\s+(?:book|volume|vol\.?|part|no\.?)\s+(\w+)\s*\)`)
+	// subtitle "Book Two of the Sprawl Trilogy"
+	seriesOrdinalPattern = regexp.MustCompile(`(?i)^(?:book|volume|vol\.?|part)\s+(\w+)\s+(?:of|in)\s+(?:the\s+)?(.+)$`)
+	// subtitle "The Sprawl Trilogy, Book 2" or "A Sprawl Novel: Volume Three"
+	seriesTrailingPattern = regexp.MustCompile(`(?i)^(.+?)\s*[,:;-]?\s+(?:book|volume|vol\.?|part)\s+(\w+)$`)
+
+	seriesSlugPrefix = regexp.MustCompile(`^(the|a|an)\s+`)
+	seriesSlugSuffix = regexp.MustCompile(`\s+(trilogy|series|saga|sequence|cycle|novels?)$`)
+	slugSeparators   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+var seriesNumberWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6,
+	"seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10, "eleventh": 11, "twelfth": 12,
+	"i": 1, "ii": 2, "iii": 3, "iv": 4, "v": 5, "vi": 6, "vii": 7, "viii": 8, "ix": 9, "x": 10,
+}
+
+// DetectSeries works out which series a volume belongs to. Google's seriesInfo
+// is preferred when present; otherwise series markers in the title and
+// subtitle such as "(Dune #3)" or "Book Two of the Sprawl Trilogy" are used.
+// Volumes that do not look like part of a series return nil.
+func DetectSeries(book model.GoogleBookItem) *model.Series {
+	fromTitle := seriesFromTitle(book.VolumeInfo.Title, book.VolumeInfo.Subtitle)
+
+	if info := book.VolumeInfo.SeriesInfo; info != nil && len(info.VolumeSeries) > 0 {
+		series := &model.Series{
+			ID:       info.VolumeSeries[0].SeriesID,
+			Position: info.VolumeSeries[0].OrderNumber,
+			Source:   SeriesSourceGoogle,
+		}
+		if series.Position == 0 {
+			series.Position = parseSeriesNumber(info.BookDisplayNumber)
+		}
+		if fromTitle != nil {
+			series.Title = fromTitle.Title
+		}
+		return series
+	}
+	return fromTitle
+}
+
+func seriesFromTitle(title string, subtitle string) *model.Series {
+	found := func(name string, number string) *model.Series {
+		name = strings.TrimSpace(name)
+		id := SeriesSlug(name)
+		if id == "" {
+			return nil
+		}
+		return &model.Series{ID: id, Title: name, Position: parseSeriesNumber(number), Source: SeriesSourceTitle}
+	}
+
+	for _, text := range []string{title, subtitle} {
+		if match := seriesHashPattern.FindStringSubmatch(text); match != nil {
+			return found(match[1], match[2])
+		}
+		if match := seriesParenPattern.FindStringSubmatch(text); match != nil && parseSeriesNumber(match[2]) > 0 {
+			return found(match[1], match[2])
+		}
+	}
+	subtitle = strings.TrimSpace(subtitle)
+	if match := seriesOrdinalPattern.FindStringSubmatch(subtitle); match != nil && parseSeriesNumber(match[1]) > 0 {
+		return found(match[2], match[1])
+	}
+	if match := seriesTrailingPattern.FindStringSubmatch(subtitle); match != nil && parseSeriesNumber(match[2]) > 0 {
+		return found(match[1], match[2])
+	}
+	return nil
+}
+
+func parseSeriesNumber(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return seriesNumberWords[s]
+}
+
+// SeriesSlug builds a stable series ID from a series name, ignoring leading
+// articles and words like "Trilogy" so "The Sprawl Trilogy" and "Sprawl" match.
+func SeriesSlug(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = seriesSlugPrefix.ReplaceAllString(name, "")
+	name = seriesSlugSuffix.ReplaceAllString(name, "")
+	return strings.Trim(slugSeparators.ReplaceAllString(name, "-"), "-")
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectSeries(t *testing.T) {
+	tests := []struct {
+		name string
+		book model.GoogleBookItem
+		want *model.Series
+	}{
+		{
+			name: "google series info",
+			book: model.GoogleBookItem{
+				VolumeInfo: model.GoogleBookVolumeInfo{
+					Title: "Count Zero",
+					SeriesInfo: &model.GoogleBookSeriesInfo{
+						BookDisplayNumber: "2",
+						VolumeSeries:      []model.GoogleBookVolumeSeries{{SeriesID: "sprawl-google-id"}},
+					},
+				},
+			},
+			want: &model.Series{ID: "sprawl-google-id", Position: 2, Source: SeriesSourceGoogle},
+		},
+		{
+			name: "google series info with a title marker",
+			book: model.GoogleBookItem{
+				VolumeInfo: model.GoogleBookVolumeInfo{
+					Title: "Mona Lisa Overdrive (Sprawl #3)",
+					SeriesInfo: &model.GoogleBookSeriesInfo{
+						VolumeSeries: []model.GoogleBookVolumeSeries{{SeriesID: "sprawl-google-id", OrderNumber: 3}},
+					},
+				},
+			},
+			want: &model.Series{ID: "sprawl-google-id", Title: "Sprawl", Position: 3, Source: SeriesSourceGoogle},
+		},
+		{
+			name: "hash marker in title",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Children of Dune (Dune #3)"}},
+			want: &model.Series{ID: "dune", Title: "Dune", Position: 3, Source: SeriesSourceTitle},
+		},
+		{
+			name: "hash marker with comma",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Dune Messiah (Dune Chronicles, #2)"}},
+			want: &model.Series{ID: "dune-chronicles", Title: "Dune Chronicles", Position: 2, Source: SeriesSourceTitle},
+		},
+		{
+			name: "book marker in title",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero (The Sprawl Trilogy Book 2)"}},
+			want: &model.Series{ID: "sprawl", Title: "The Sprawl Trilogy", Position: 2, Source: SeriesSourceTitle},
+		},
+		{
+			name: "ordinal subtitle",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Subtitle: "Book One of the Sprawl Trilogy"}},
+			want: &model.Series{ID: "sprawl", Title: "Sprawl Trilogy", Position: 1, Source: SeriesSourceTitle},
+		},
+		{
+			name: "trailing subtitle",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Agency", Subtitle: "Jackpot Trilogy, Book 2"}},
+			want: &model.Series{ID: "jackpot", Title: "Jackpot Trilogy", Position: 2, Source: SeriesSourceTitle},
+		},
+		{
+			name: "no series",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Pattern Recognition", Subtitle: "A Novel"}},
+			want: nil,
+		},
+		{
+			name: "parenthetical without a number",
+			book: model.GoogleBookItem{VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer (Ace Science Fiction)"}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectSeries(tt.book))
+		})
+	}
+}
+
+func TestSeriesSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "The Sprawl Trilogy", want: "sprawl"},
+		{name: "Sprawl", want: "sprawl"},
+		{name: "A Sprawl Novel", want: "sprawl"},
+		{name: "Dune Chronicles", want: "dune-chronicles"},
+		{name: "  ", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SeriesSlug(tt.name))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	model "example.com/book-learn/models"
+)
+
+// Sort orders accepted in GoogleBookRequest.SortBy.
+const (
+	SortPublishedDesc = "published-desc"
+	SortPublishedAsc  = "published-asc"
+	SortTitle         = "title"
+	SortPageCount     = "page-count"
+	SortRelevance     = "relevance"
+	SortRating        = "rating"
+)
+
+var sortComparators = map[string]func(a, b model.GoogleBookItem) int{
+	SortPublishedDesc: func(a, b model.GoogleBookItem) int {
+		return model.ParsePublicationDate(b.VolumeInfo.PublishedDate).Compare(model.ParsePublicationDate(a.VolumeInfo.PublishedDate))
+	},
+	SortPublishedAsc: func(a, b model.GoogleBookItem) int {
+		return model.ParsePublicationDate(a.VolumeInfo.PublishedDate).Compare(model.ParsePublicationDate(b.VolumeInfo.PublishedDate))
+	},
+	SortTitle: func(a, b model.GoogleBookItem) int {
+		return cmp.Compare(strings.ToLower(a.VolumeInfo.Title), strings.ToLower(b.VolumeInfo.Title))
+	},
+	SortPageCount: func(a, b model.GoogleBookItem) int {
+		return cmp.Compare(b.VolumeInfo.PageCount, a.VolumeInfo.PageCount)
+	},
+	SortRelevance: func(a, b model.GoogleBookItem) int {
+		return cmp.Compare(relevanceTotal(b), relevanceTotal(a))
+	},
+	SortRating: func(a, b model.GoogleBookItem) int {
+		if c := cmp.Compare(b.VolumeInfo.AverageRating, a.VolumeInfo.AverageRating); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.VolumeInfo.RatingsCount, a.VolumeInfo.RatingsCount)
+	},
+}
+
+// SortOrder returns the requested sort order, or fallback when none was given.
+func (r GoogleBookRequest) SortOrder(fallback string) string {
+	if r.SortBy == "" {
+		return fallback
+	}
+	return r.SortBy
+}
+
+func validateSortOrder(sortBy string) error {
+	if _, ok := sortComparators[sortBy]; sortBy != "" && !ok {
+		return fmt.Errorf("SortBy: unknown sort order %q", sortBy)
+	}
+	return nil
+}
+
+// SortItems sorts items in place by the given order. The sort is stable, so
+// items that compare equal keep the order they were fetched in. Unknown
+// orders leave the items untouched.
+func SortItems(items []model.GoogleBookItem, sortBy string) {
+	if compare, ok := sortComparators[sortBy]; ok {
+		slices.SortStableFunc(items, compare)
+	}
+}
+
+func sortResults(sortBy string) func(model.GoogleBookResponse, error) (model.GoogleBookResponse, error) {
+	return func(resp model.GoogleBookResponse, err error) (model.GoogleBookResponse, error) {
+		if err != nil {
+			return resp, err
+		}
+		SortItems(resp.Items, sortBy)
+		return resp, err
+	}
+}
+
+func relevanceTotal(book model.GoogleBookItem) float64 {
+	if book.Relevance == nil {
+		return 0
+	}
+	return book.Relevance.Total
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortItems(t *testing.T) {
+	neuromancer := model.GoogleBookItem{
+		ID: "neuromancer",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:         "Neuromancer",
+			PublishedDate: "1984-07-01",
+			PageCount:     271,
+			AverageRating: 4,
+			RatingsCount:  100,
+		},
+		Relevance: &model.RelevanceScore{Total: 5},
+	}
+	countZero := model.GoogleBookItem{
+		ID: "count-zero",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:         "count zero",
+			PublishedDate: "1986",
+			PageCount:     256,
+			AverageRating: 4,
+			RatingsCount:  20,
+		},
+		Relevance: &model.RelevanceScore{Total: 7},
+	}
+	monaLisa := model.GoogleBookItem{
+		ID: "mona-lisa",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:         "Mona Lisa Overdrive",
+			PublishedDate: "1988",
+			PageCount:     308,
+			AverageRating: 4.5,
+		},
+	}
+	undated := model.GoogleBookItem{
+		ID: "undated",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:     "Burning Chrome",
+			PageCount: 256,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		sortBy  string
+		wantIDs []string
+	}{
+		{name: "newest first", sortBy: SortPublishedDesc, wantIDs: []string{"mona-lisa", "count-zero", "neuromancer", "undated"}},
+		{name: "oldest first", sortBy: SortPublishedAsc, wantIDs: []string{"undated", "neuromancer", "count-zero", "mona-lisa"}},
+		{name: "title", sortBy: SortTitle, wantIDs: []string{"undated", "count-zero", "mona-lisa", "neuromancer"}},
+		{name: "page count keeps ties in order", sortBy: SortPageCount, wantIDs: []string{"mona-lisa", "neuromancer", "count-zero", "undated"}},
+		{name: "relevance", sortBy: SortRelevance, wantIDs: []string{"count-zero", "neuromancer", "mona-lisa", "undated"}},
+		{name: "rating", sortBy: SortRating, wantIDs: []string{"mona-lisa", "neuromancer", "count-zero", "undated"}},
+		{name: "unknown order", sortBy: "popularity", wantIDs: []string{"neuromancer", "count-zero", "mona-lisa", "undated"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := []model.GoogleBookItem{neuromancer, countZero, monaLisa, undated}
+			SortItems(items, tt.sortBy)
+			gotIDs := []string{}
+			for _, item := range items {
+				gotIDs = append(gotIDs, item.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestGoogleBookRequest_SortOrder(t *testing.T) {
+	assert.Equal(t, SortPublishedDesc, GoogleBookRequest{}.SortOrder(SortPublishedDesc))
+	assert.Equal(t, SortTitle, GoogleBookRequest{SortBy: SortTitle}.SortOrder(SortPublishedDesc))
+}
@@ -4,17 +4,30 @@ go 1.21
 
 require (
 	github.com/go-chi/chi/v5 v5.0.13
+	github.com/graphql-go/graphql v0.8.1
+	github.com/mmcdole/gofeed v1.3.0
 	github.com/stretchr/testify v1.9.0
+	google.golang.org/grpc v1.66.3
+	google.golang.org/protobuf v1.34.2
 )
 
 require (
+	github.com/PuerkitoBio/goquery v1.8.0 // indirect
+	github.com/andybalholm/cascadia v1.3.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/mitchellh/go-ps v1.0.0 // indirect
+	github.com/mmcdole/goxpp v1.1.1-0.20240225020742-a0c311522b23 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/silenceper/gowatch v1.5.3 // indirect
 	github.com/silenceper/log v0.0.0-20171204144354-e5ac7fa8a76a // indirect
+	golang.org/x/net v0.26.0 // indirect
 	golang.org/x/sys v0.24.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
 	gopkg.in/yaml.v1 v1.0.0-20140924161607-9f9df34309c0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	model "example.com/book-learn/models"
+)
+
+const (
+	defaultPageSize = 10
+	// maxPageSize is the most results Google returns per request.
+	maxPageSize = 40
+
+	cursorPrefix = "offset:"
+)
+
+var errInvalidCursor = errors.New("after is not a cursor from this connection")
+
+// bookConnection is a page of books. Offset is the position of the first
+// book in the whole result set and Total the size of that set, which for
+// Google searches is Google's estimate.
+type bookConnection struct {
+	Books  []model.GoogleBookItem
+	Offset int
+	Total  int
+}
+
+type bookEdge struct {
+	Cursor string
+	Book   model.GoogleBookItem
+}
+
+// page is the slice of a connection asked for by first and after.
+type page struct {
+	Offset int
+	Limit  int
+}
+
+// pageArgs reads the first and after arguments of a connection field. After
+// is the cursor of the last edge already seen, so the page starts just past
+// it.
+func pageArgs(args map[string]any) (page, error) {
+	p := page{Limit: defaultPageSize}
+	if first, ok := args["first"].(int); ok {
+		if first < 1 || first > maxPageSize {
+			return page{}, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+		}
+		p.Limit = first
+	}
+	if after, ok := args["after"].(string); ok && after != "" {
+		offset, err := decodeCursor(after)
+		if err != nil {
+			return page{}, err
+		}
+		p.Offset = offset + 1
+	}
+	return p, nil
+}
+
+// sliceConnection pages through books that are all in memory.
+func sliceConnection(books []model.GoogleBookItem, p page) bookConnection {
+	start := min(p.Offset, len(books))
+	end := min(start+p.Limit, len(books))
+	return bookConnection{Books: books[start:end], Offset: start, Total: len(books)}
+}
+
+func (c bookConnection) edges() []bookEdge {
+	edges := make([]bookEdge, 0, len(c.Books))
+	for i, book := range c.Books {
+		edges = append(edges, bookEdge{Cursor: encodeCursor(c.Offset + i), Book: book})
+	}
+	return edges
+}
+
+func (c bookConnection) hasNextPage() bool {
+	return c.Offset+len(c.Books) < c.Total
+}
+
+func (c bookConnection) hasPreviousPage() bool {
+	return c.Offset > 0
+}
+
+// Cursors are opaque to clients, who should only pass them back as after.
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, errInvalidCursor
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, errInvalidCursor
+	}
+	return offset, nil
+}
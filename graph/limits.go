@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// maxQueryDepth is how deeply fields may be nested, counting the root
+	// field as one.
+	maxQueryDepth = 10
+	// maxQueryComplexity bounds the estimated cost of a query. Each field
+	// costs one and a field taking first or ids multiplies the cost of its
+	// selections by the number of items it can return.
+	maxQueryComplexity = 1000
+)
+
+// queryCost is the depth and complexity of an operation.
+type queryCost struct {
+	Depth      int
+	Complexity int
+}
+
+// measure works out the cost of the operation a request would run. Fragments
+// count where they are spread. Introspection is left out, as it never reaches
+// the book service and tools such as GraphiQL nest it deeply. The document
+// must have been validated, which rules out fragment cycles.
+func measure(document *ast.Document, operationName string, variables map[string]any) queryCost {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operation == nil && (operationName == "" || definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return queryCost{}
+	}
+	m := measurer{fragments: fragments, variables: variables}
+	return m.selectionSet(operation.SelectionSet)
+}
+
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+func (m measurer) selectionSet(set *ast.SelectionSet) queryCost {
+	var cost queryCost
+	if set == nil {
+		return cost
+	}
+	for _, selection := range set.Selections {
+		var child queryCost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			child = m.field(selection)
+		case *ast.InlineFragment:
+			child = m.selectionSet(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if fragment, ok := m.fragments[selection.Name.Value]; ok {
+				child = m.selectionSet(fragment.SelectionSet)
+			}
+		}
+		cost.Depth = max(cost.Depth, child.Depth)
+		cost.Complexity += child.Complexity
+	}
+	return cost
+}
+
+func (m measurer) field(field *ast.Field) queryCost {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return queryCost{}
+	}
+	selections := m.selectionSet(field.SelectionSet)
+	return queryCost{
+		Depth:      selections.Depth + 1,
+		Complexity: 1 + m.multiplier(field)*selections.Complexity,
+	}
+}
+
+// pagedFields are the fields that take first and return that many items,
+// defaultPageSize when it is left out.
+var pagedFields = map[string]bool{"search": true, "books": true, "similar": true, "relatedAuthors": true}
+
+// multiplier is how many items a field can return, from its ids or first
+// argument. Other fields count as one.
+func (m measurer) multiplier(field *ast.Field) int {
+	paged := pagedFields[field.Name.Value]
+	for _, argument := range field.Arguments {
+		switch argument.Name.Value {
+		case "ids":
+			return max(m.listLength(argument.Value), 1)
+		case "first":
+			if first, ok := m.intValue(argument.Value); ok && first > 0 {
+				return first
+			}
+		}
+	}
+	if paged {
+		return defaultPageSize
+	}
+	return 1
+}
+
+func (m measurer) intValue(value ast.Value) (int, bool) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := m.variables[value.Name.Value].(type) {
+		case int:
+			return n, true
+		case float64:
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+func (m measurer) listLength(value ast.Value) int {
+	switch value := value.(type) {
+	case *ast.ListValue:
+		return len(value.Values)
+	case *ast.Variable:
+		if list, ok := m.variables[value.Name.Value].([]any); ok {
+			return len(list)
+		}
+	}
+	return 0
+}
+
+// check reports the first limit the cost exceeds.
+func (c queryCost) check() error {
+	if c.Depth > maxQueryDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", c.Depth, maxQueryDepth)
+	}
+	if c.Complexity > maxQueryComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", c.Complexity, maxQueryComplexity)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]any
+		expected      queryCost
+	}{
+		{name: "flat", query: `{ book(id: "a") { id title } }`, expected: queryCost{Depth: 2, Complexity: 3}},
+		{
+			name:     "connection",
+			query:    `{ search(query: "gibson", first: 5) { edges { node { title } } } }`,
+			expected: queryCost{Depth: 4, Complexity: 1 + 5*3},
+		},
+		{
+			name:     "default page size",
+			query:    `{ search(query: "gibson") { nodes { title } } }`,
+			expected: queryCost{Depth: 3, Complexity: 1 + defaultPageSize*2},
+		},
+		{
+			name:      "variables",
+			query:     `query ($first: Int, $ids: [ID!]!) { books(ids: $ids) { similar(first: $first) { id } } }`,
+			variables: map[string]any{"first": float64(3), "ids": []any{"a", "b"}},
+			expected:  queryCost{Depth: 3, Complexity: 1 + 2*(1+3*1)},
+		},
+		{
+			name: "fragments",
+			query: `{ author(name: "William Gibson") { ...books } }
+				fragment books on Author { books(first: 2) { nodes { ... on Book { title } } } }`,
+			expected: queryCost{Depth: 4, Complexity: 1 + 1 + 2*2},
+		},
+		{
+			name:          "named operation",
+			query:         `query A { book(id: "a") { id } } query B { categories { slug children { slug } } }`,
+			operationName: "B",
+			expected:      queryCost{Depth: 3, Complexity: 4},
+		},
+		{
+			name:     "introspection",
+			query:    `{ __schema { types { fields { type { ofType { ofType { name } } } } } } book(id: "a") { __typename id } }`,
+			expected: queryCost{Depth: 2, Complexity: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, measure(document, tt.operationName, tt.variables))
+		})
+	}
+}
+
+func TestQueryCost_check(t *testing.T) {
+	assert.NoError(t, queryCost{Depth: maxQueryDepth, Complexity: maxQueryComplexity}.check())
+	assert.EqualError(t, queryCost{Depth: maxQueryDepth + 1}.check(), "query depth 11 exceeds the limit of 10")
+	assert.EqualError(t, queryCost{Complexity: maxQueryComplexity + 1}.check(), "query complexity 1001 exceeds the limit of 1000")
+}
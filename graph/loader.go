@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"sync"
+)
+
+// loaderConcurrency bounds the upstream requests a single batch makes at once.
+const loaderConcurrency = 4
+
+// loader batches the keys asked for by sibling fields into a single fetch.
+// Resolvers call load and return its thunk. The executor only calls thunks
+// once every sibling field has been resolved, so the first call fetches all
+// of the keys collected so far. Results are kept for the rest of the request,
+// so a key is fetched at most once.
+type loader[K comparable, V any] struct {
+	fetch   func(ctx context.Context, keys []K) map[K]loaderResult[V]
+	mu      sync.Mutex
+	pending []K
+	entries map[K]*loaderEntry[V]
+}
+
+type loaderResult[V any] struct {
+	value V
+	err   error
+}
+
+type loaderEntry[V any] struct {
+	done   chan struct{}
+	result loaderResult[V]
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) map[K]loaderResult[V]) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, entries: map[K]*loaderEntry[V]{}}
+}
+
+// load queues key for the next batch and returns a thunk that waits for it.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &loaderEntry[V]{done: make(chan struct{})}
+		l.entries[key] = entry
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.dispatch(ctx)
+		<-entry.done
+		return entry.result.value, entry.result.err
+	}
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+
+	results := l.fetch(ctx, keys)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		entry := l.entries[key]
+		entry.result = results[key]
+		close(entry.done)
+	}
+}
+
+// fetchEach fetches every key on its own, loaderConcurrency at a time, for
+// upstream APIs that have no batch lookup.
+func fetchEach[K comparable, V any](ctx context.Context, keys []K, fetch func(ctx context.Context, key K) (V, error)) map[K]loaderResult[V] {
+	results := make(map[K]loaderResult[V], len(keys))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, loaderConcurrency)
+	for _, key := range keys {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(key K) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			value, err := fetch(ctx, key)
+			mu.Lock()
+			results[key] = loaderResult[V]{value: value, err: err}
+			mu.Unlock()
+		}(key)
+	}
+	wg.Wait()
+	return results
+}
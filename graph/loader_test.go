@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader(t *testing.T) {
+	var batches [][]string
+	l := newLoader(func(ctx context.Context, keys []string) map[string]loaderResult[string] {
+		batches = append(batches, keys)
+		results := map[string]loaderResult[string]{}
+		for _, key := range keys {
+			if key == "broken" {
+				results[key] = loaderResult[string]{err: errors.New("test-error")}
+				continue
+			}
+			results[key] = loaderResult[string]{value: strings.ToUpper(key)}
+		}
+		return results
+	})
+
+	ctx := context.Background()
+	a := l.load(ctx, "a")
+	b := l.load(ctx, "b")
+	again := l.load(ctx, "a")
+	broken := l.load(ctx, "broken")
+
+	// The first thunk fetches every key queued so far, once
+	value, err := b()
+	assert.NoError(t, err)
+	assert.Equal(t, "B", value)
+	value, _ = a()
+	assert.Equal(t, "A", value)
+	value, _ = again()
+	assert.Equal(t, "A", value)
+	_, err = broken()
+	assert.EqualError(t, err, "test-error")
+	assert.Equal(t, [][]string{{"a", "b", "broken"}}, batches)
+
+	// Keys already fetched are not fetched again
+	value, _ = l.load(ctx, "b")()
+	assert.Equal(t, "B", value)
+	value, _ = l.load(ctx, "c")()
+	assert.Equal(t, "C", value)
+	assert.Equal(t, [][]string{{"a", "b", "broken"}, {"c"}}, batches)
+}
+
+func TestFetchEach(t *testing.T) {
+	var inFlight, most atomic.Int32
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	results := fetchEach(context.Background(), keys, func(ctx context.Context, key string) (string, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := most.Load()
+			if n <= seen || most.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		return key + key, nil
+	})
+
+	var values []string
+	for _, result := range results {
+		values = append(values, result.value)
+	}
+	sort.Strings(values)
+	assert.Equal(t, []string{"aa", "bb", "cc", "dd", "ee", "ff", "gg", "hh"}, values)
+	assert.LessOrEqual(t, most.Load(), int32(loaderConcurrency))
+}
@@ -0,0 +1,121 @@
+// Package graph serves the books API as a GraphQL schema, so a client can
+// fetch a book, its authors and their other books in one request and only
+// receive the fields it asks for.
+package graph
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/recommend"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// errUpstream stands in for book service failures, whose details are logged
+// rather than returned.
+var errUpstream = errors.New("books could not be fetched, try again later")
+
+// Schema resolves queries against the book client for searches and volumes,
+// and against the catalog for series, categories and recommendations.
+type Schema struct {
+	books       client.BookClientInterface
+	catalog     *client.Catalog
+	graph       *client.AuthorGraph
+	recommender *recommend.Recommender
+	schema      graphql.Schema
+
+	book       *graphql.Object
+	author     *graphql.Object
+	series     *graphql.Object
+	category   *graphql.Object
+	connection *graphql.Object
+}
+
+// Request is a GraphQL request as clients send it.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func New(api client.BookClientInterface, catalog *client.Catalog, graph *client.AuthorGraph, recommender *recommend.Recommender) (*Schema, error) {
+	s := &Schema{books: api, catalog: catalog, graph: graph, recommender: recommender}
+	s.book = s.bookType()
+	s.author = s.authorType()
+	s.series = s.seriesType()
+	s.category = s.categoryType()
+	s.connection = s.connectionType()
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: s.queryType()})
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Execute runs a request with its own loaders, so lookups are only shared
+// between the fields of one request. Requests that do not parse, are invalid
+// against the schema or exceed the query limits are not run and their result
+// has errors but no data.
+func (s *Schema) Execute(ctx context.Context, req Request) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := graphql.ValidateDocument(&s.schema, document, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+	if err := measure(document, req.OperationName, req.Variables).check(); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, loadersKey{}, s.newLoaders()),
+	})
+}
+
+type loadersKey struct{}
+
+// loaders batch the book client calls made by the fields of one request.
+type loaders struct {
+	volumes     *loader[string, model.GoogleBookItem]
+	authorBooks *loader[client.GoogleBookRequest, model.GoogleBookResponse]
+}
+
+func (s *Schema) newLoaders() *loaders {
+	return &loaders{
+		volumes: newLoader(func(ctx context.Context, ids []string) map[string]loaderResult[model.GoogleBookItem] {
+			return fetchEach(ctx, ids, s.books.ByID)
+		}),
+		authorBooks: newLoader(func(ctx context.Context, requests []client.GoogleBookRequest) map[client.GoogleBookRequest]loaderResult[model.GoogleBookResponse] {
+			return fetchEach(ctx, requests, s.books.ByAuthor)
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// upstreamError logs a book client error and returns what the client sees.
+func upstreamError(err error) error {
+	slog.Error(err.Error())
+	if errors.Is(err, client.ErrRateLimited) {
+		return client.ErrRateLimited
+	}
+	return errUpstream
+}
@@ -0,0 +1,229 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/recommend"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingClient serves volumes and author pages from memory and records the
+// calls made to it.
+type countingClient struct {
+	volumes map[string]model.GoogleBookItem
+	err     error
+	mu      sync.Mutex
+	calls   []string
+}
+
+func (cli *countingClient) record(call string) {
+	cli.mu.Lock()
+	defer cli.mu.Unlock()
+	cli.calls = append(cli.calls, call)
+}
+
+func (cli *countingClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	cli.record(fmt.Sprintf("ByAuthor %s %d+%d", request.Author, request.Start, request.Limit))
+	if cli.err != nil {
+		return model.GoogleBookResponse{}, cli.err
+	}
+	var books []model.GoogleBookItem
+	for _, id := range []string{"agency", "peripheral", "neuromancer"} {
+		if book := cli.volumes[id]; book.VolumeInfo.Authors[0] == request.Author {
+			books = append(books, book)
+		}
+	}
+	end := min(request.Start+request.Limit, len(books))
+	return model.GoogleBookResponse{TotalItems: len(books), Items: books[min(request.Start, end):end]}, nil
+}
+
+func (cli *countingClient) ByTitle(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return model.GoogleBookResponse{}, nil
+}
+
+func (cli *countingClient) ByID(ctx context.Context, id string) (model.GoogleBookItem, error) {
+	cli.record("ByID " + id)
+	if cli.err != nil {
+		return model.GoogleBookItem{}, cli.err
+	}
+	if book, ok := cli.volumes[id]; ok {
+		return book, nil
+	}
+	return model.GoogleBookItem{}, client.ErrVolumeNotFound
+}
+
+func (cli *countingClient) ByQuery(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	cli.record("ByQuery " + request.Query)
+	if cli.err != nil {
+		return model.GoogleBookResponse{}, cli.err
+	}
+	return model.GoogleBookResponse{TotalItems: 45, Items: []model.GoogleBookItem{cli.volumes["neuromancer"]}}, nil
+}
+
+func testVolume(id string, title string, date string, categories ...string) model.GoogleBookItem {
+	return model.GoogleBookItem{ID: id, VolumeInfo: model.GoogleBookVolumeInfo{
+		Title:         title,
+		Authors:       []string{"William Gibson"},
+		PublishedDate: date,
+		Categories:    categories,
+	}}
+}
+
+func newTestSchema(t *testing.T, cli *countingClient) *Schema {
+	catalog := client.NewCatalog()
+	graph := client.NewAuthorGraph()
+	catalog.OnAdd(graph.AddVolumes)
+	var volumes []model.GoogleBookItem
+	for _, id := range []string{"agency", "peripheral", "neuromancer"} {
+		volumes = append(volumes, cli.volumes[id])
+	}
+	catalog.Add(volumes...)
+
+	schema, err := New(cli, catalog, graph, recommend.New(catalog, graph))
+	assert.NoError(t, err)
+	return schema
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{volumes: map[string]model.GoogleBookItem{
+		"agency":      testVolume("agency", "Agency (Jackpot #2)", "2020-01-21", "Fiction / Science Fiction / Cyberpunk"),
+		"peripheral":  testVolume("peripheral", "The Peripheral (Jackpot #1)", "2014-10-28", "Fiction / Science Fiction / Cyberpunk"),
+		"neuromancer": testVolume("neuromancer", "Neuromancer", "1984-07-01", "Fiction / Science Fiction / Cyberpunk"),
+	}}
+}
+
+// execute runs a query and returns its data and error messages as JSON
+// would give them to a client.
+func execute(t *testing.T, schema *Schema, req Request) (map[string]any, []string) {
+	result := schema.Execute(context.Background(), req)
+	raw, err := json.Marshal(result)
+	assert.NoError(t, err)
+	var decoded struct {
+		Data   map[string]any
+		Errors []struct{ Message string }
+	}
+	assert.NoError(t, json.Unmarshal(raw, &decoded))
+	var messages []string
+	for _, err := range decoded.Errors {
+		messages = append(messages, err.Message)
+	}
+	return decoded.Data, messages
+}
+
+func TestSchema_batching(t *testing.T) {
+	cli := newCountingClient()
+	schema := newTestSchema(t, cli)
+
+	data, errs := execute(t, schema, Request{Query: `{
+		a: book(id: "agency") { title authors { name books(first: 2) { totalCount nodes { id } } } }
+		b: book(id: "peripheral") { title authors { name books(first: 2) { nodes { id } } } }
+		books(ids: ["agency", "neuromancer", "unknown"]) { id }
+	}`})
+	assert.Empty(t, errs)
+
+	// Every author lookup is the same page, so it is fetched once, and each
+	// volume is fetched once however many fields ask for it
+	assert.ElementsMatch(t, []string{"ByID agency", "ByID peripheral", "ByID neuromancer", "ByID unknown", "ByAuthor William Gibson 0+2"}, cli.calls)
+
+	a := data["a"].(map[string]any)
+	assert.Equal(t, "Agency (Jackpot #2)", a["title"])
+	books := a["authors"].([]any)[0].(map[string]any)["books"].(map[string]any)
+	assert.Equal(t, float64(3), books["totalCount"])
+	assert.Equal(t, []any{map[string]any{"id": "agency"}, map[string]any{"id": "peripheral"}}, books["nodes"])
+	assert.Equal(t, []any{map[string]any{"id": "agency"}, map[string]any{"id": "neuromancer"}, nil}, data["books"])
+}
+
+func TestSchema_pagination(t *testing.T) {
+	cli := newCountingClient()
+	schema := newTestSchema(t, cli)
+	query := `query ($after: String) { author(name: "William Gibson") { books(first: 2, after: $after) {
+		edges { cursor node { id } }
+		pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+	} } }`
+
+	data, errs := execute(t, schema, Request{Query: query})
+	assert.Empty(t, errs)
+	books := data["author"].(map[string]any)["books"].(map[string]any)
+	edges := books["edges"].([]any)
+	assert.Len(t, edges, 2)
+	pageInfo := books["pageInfo"].(map[string]any)
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+	assert.Equal(t, false, pageInfo["hasPreviousPage"])
+	assert.Equal(t, edges[0].(map[string]any)["cursor"], pageInfo["startCursor"])
+	assert.Equal(t, edges[1].(map[string]any)["cursor"], pageInfo["endCursor"])
+
+	data, errs = execute(t, schema, Request{Query: query, Variables: map[string]any{"after": pageInfo["endCursor"]}})
+	assert.Empty(t, errs)
+	books = data["author"].(map[string]any)["books"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"cursor": encodeCursor(2), "node": map[string]any{"id": "neuromancer"}}}, books["edges"])
+	pageInfo = books["pageInfo"].(map[string]any)
+	assert.Equal(t, false, pageInfo["hasNextPage"])
+	assert.Equal(t, true, pageInfo["hasPreviousPage"])
+	assert.Contains(t, cli.calls, "ByAuthor William Gibson 2+2")
+
+	_, errs = execute(t, schema, Request{Query: query, Variables: map[string]any{"after": "bm9wZQ=="}})
+	assert.Equal(t, []string{errInvalidCursor.Error()}, errs)
+}
+
+func TestSchema_catalog(t *testing.T) {
+	schema := newTestSchema(t, newCountingClient())
+
+	data, errs := execute(t, schema, Request{Query: `{
+		series(id: "jackpot") { title books { id seriesPosition } }
+		category(slug: "cyberpunk") { name parent { slug } books(first: 1) { totalCount nodes { id } } }
+		categories { slug }
+		book(id: "neuromancer") { series { id } categories { slug } similar(first: 1) { id } }
+		unknown: series(id: "unknown") { id }
+	}`})
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]any{"title": "Jackpot", "books": []any{
+		map[string]any{"id": "peripheral", "seriesPosition": float64(1)},
+		map[string]any{"id": "agency", "seriesPosition": float64(2)},
+	}}, data["series"])
+	category := data["category"].(map[string]any)
+	assert.Equal(t, "Cyberpunk", category["name"])
+	assert.Equal(t, map[string]any{"slug": "science-fiction"}, category["parent"])
+	assert.Equal(t, float64(3), category["books"].(map[string]any)["totalCount"])
+	assert.Contains(t, data["categories"], map[string]any{"slug": "fiction"})
+	assert.Equal(t, map[string]any{"series": nil, "categories": []any{map[string]any{"slug": "cyberpunk"}}, "similar": []any{map[string]any{"id": "agency"}}}, data["book"])
+	assert.Nil(t, data["unknown"])
+}
+
+func TestSchema_errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		err      error
+		hasData  bool
+		expected []string
+	}{
+		{name: "syntax error", query: `{ book(id: "a") { title }`, expected: []string{`Syntax Error GraphQL request (1:26) Expected Name, found EOF
+
+1: { book(id: "a") { title }
+                            ^
+`}},
+		{name: "unknown field", query: `{ book(id: "a") { isbn13 } }`, expected: []string{`Cannot query field "isbn13" on type "Book". Did you mean "isbn"?`}},
+		{name: "too deep", query: `{ book(id: "a") { authors { books { nodes { authors { books { nodes { authors { books { nodes { id } } } } } } } } } } }`, expected: []string{"query depth 11 exceeds the limit of 10"}},
+		{name: "too complex", query: `{ search(query: "a", first: 40) { nodes { authors { books(first: 40) { nodes { id } } } } } }`, expected: []string{"query complexity 3321 exceeds the limit of 1000"}},
+		{name: "bad first", query: `{ search(query: "gibson", first: 41) { totalCount } }`, hasData: true, expected: []string{"first must be between 1 and 40"}},
+		{name: "bad search", query: `{ search(query: "\"gibson", first: 5) { totalCount } }`, hasData: true, expected: []string{"query: unterminated quote at position 0"}},
+		{name: "upstream error", query: `{ book(id: "a") { id } search(query: "gibson") { totalCount } }`, err: errors.New("test-error"), hasData: true, expected: []string{errUpstream.Error(), errUpstream.Error()}},
+		{name: "rate limited", query: `{ book(id: "a") { id } }`, err: client.ErrRateLimited, hasData: true, expected: []string{client.ErrRateLimited.Error()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newCountingClient()
+			cli.err = tt.err
+			data, errs := execute(t, newTestSchema(t, cli), Request{Query: tt.query})
+			assert.Equal(t, tt.expected, errs)
+			assert.Equal(t, tt.hasData, data != nil)
+		})
+	}
+}
@@ -0,0 +1,474 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/graphql-go/graphql"
+)
+
+// author is the source of Author fields. Authors are known by name only.
+type author struct {
+	Name string
+}
+
+// seriesRef is the source of Series fields.
+type seriesRef struct {
+	ID    string
+	Title string
+}
+
+var (
+	pageArgsConfig = graphql.FieldConfigArgument{
+		"first": {Type: graphql.Int, Description: fmt.Sprintf("How many books to return, at most %d.", maxPageSize)},
+		"after": {Type: graphql.String, Description: "The cursor of the last edge already seen."},
+	}
+	firstArgConfig = graphql.FieldConfigArgument{
+		"first": {Type: graphql.Int, DefaultValue: defaultPageSize},
+	}
+)
+
+func (s *Schema) queryType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"book": {
+				Type:    s.book,
+				Args:    graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: s.resolveBook,
+			},
+			"books": {
+				Type:        graphql.NewNonNull(graphql.NewList(s.book)),
+				Description: "Books by ID, in the order asked for. Unknown IDs are null.",
+				Args:        graphql.FieldConfigArgument{"ids": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))}},
+				Resolve:     s.resolveBooks,
+			},
+			"author": {
+				Type:    s.author,
+				Args:    graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: resolveAuthor,
+			},
+			"series": {
+				Type:        s.series,
+				Description: "A series, from the books the service has already seen.",
+				Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     s.resolveSeries,
+			},
+			"category": {
+				Type:    s.category,
+				Args:    graphql.FieldConfigArgument{"slug": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: resolveCategory,
+			},
+			"categories": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.category))),
+				Description: "The top-level categories.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return childCategories(""), nil
+				},
+			},
+			"search": {
+				Type:        s.connection,
+				Description: `Books matching a search query such as 'neuromancer author:"William Gibson"'.`,
+				Args: graphql.FieldConfigArgument{
+					"query": {Type: graphql.NewNonNull(graphql.String)},
+					"first": pageArgsConfig["first"],
+					"after": pageArgsConfig["after"],
+				},
+				Resolve: s.resolveSearch,
+			},
+		},
+	})
+}
+
+func (s *Schema) bookType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Book",
+		Description: "A work, as one of its editions, with its other editions.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":    {Type: graphql.NewNonNull(graphql.ID), Resolve: bookField(func(b model.GoogleBookItem) any { return b.ID })},
+				"title": {Type: graphql.NewNonNull(graphql.String), Resolve: bookField(func(b model.GoogleBookItem) any { return b.VolumeInfo.Title })},
+				"subtitle": {Type: graphql.String, Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.Subtitle)
+				})},
+				"authors": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.author))), Resolve: bookField(func(b model.GoogleBookItem) any {
+					authors := []author{}
+					for _, name := range b.VolumeInfo.Authors {
+						authors = append(authors, author{Name: name})
+					}
+					return authors
+				})},
+				"publisher": {Type: graphql.String, Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.Publisher)
+				})},
+				"publishedDate": {Type: graphql.String, Description: "The date as Google reports it, which may be only a year.", Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.PublishedDate)
+				})},
+				"publishedYear": {Type: graphql.Int, Resolve: bookField(func(b model.GoogleBookItem) any {
+					if date := model.ParsePublicationDate(b.VolumeInfo.PublishedDate); date.Precision >= model.DatePrecisionYear {
+						return date.Year
+					}
+					return nil
+				})},
+				"description": {Type: graphql.String, Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.Description)
+				})},
+				"pageCount": {Type: graphql.Int, Resolve: bookField(func(b model.GoogleBookItem) any {
+					if b.VolumeInfo.PageCount == 0 {
+						return nil
+					}
+					return b.VolumeInfo.PageCount
+				})},
+				"language": {Type: graphql.String, Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.Language)
+				})},
+				"isbn": {Type: graphql.String, Description: "The ISBN-13, or the ISBN-10 when there is none.", Resolve: bookField(func(b model.GoogleBookItem) any {
+					for _, kind := range []string{"ISBN_13", "ISBN_10"} {
+						for _, id := range b.VolumeInfo.IndustryIdentifiers {
+							if id.Type == kind {
+								return id.Identifier
+							}
+						}
+					}
+					return nil
+				})},
+				"categories": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.category))), Resolve: bookField(func(b model.GoogleBookItem) any {
+					return client.NormalizeCategories(b.VolumeInfo.Categories)
+				})},
+				"series": {Type: s.series, Resolve: bookField(func(b model.GoogleBookItem) any {
+					if series := client.DetectSeries(b); series != nil {
+						return seriesRef{ID: series.ID, Title: series.Title}
+					}
+					return nil
+				})},
+				"seriesPosition": {Type: graphql.Int, Resolve: bookField(func(b model.GoogleBookItem) any {
+					if series := client.DetectSeries(b); series != nil && series.Position > 0 {
+						return series.Position
+					}
+					return nil
+				})},
+				"thumbnail": {Type: graphql.String, Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.ImageLinks.Thumbnail)
+				})},
+				"infoLink": {Type: graphql.String, Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.InfoLink)
+				})},
+				"previewLink": {Type: graphql.String, Resolve: bookField(func(b model.GoogleBookItem) any {
+					return optional(b.VolumeInfo.PreviewLink)
+				})},
+				"editions": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.book))), Resolve: bookField(func(b model.GoogleBookItem) any {
+					if b.Editions == nil {
+						return []model.GoogleBookItem{}
+					}
+					return b.Editions
+				})},
+				"similar": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.book))),
+					Description: "Similar books the service has already seen, most similar first.",
+					Args:        firstArgConfig,
+					Resolve:     s.resolveSimilar,
+				},
+			}
+		}),
+	})
+}
+
+func (s *Schema) authorType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name": {Type: graphql.NewNonNull(graphql.String)},
+				"books": {
+					Type:        s.connection,
+					Description: "The author's books, newest first.",
+					Args:        pageArgsConfig,
+					Resolve:     s.resolveAuthorBooks,
+				},
+				"relatedAuthors": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.author))),
+					Description: "Co-authors and authors in the same categories, closest first.",
+					Args:        firstArgConfig,
+					Resolve:     s.resolveRelatedAuthors,
+				},
+			}
+		}),
+	})
+}
+
+func (s *Schema) seriesType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Series",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": {Type: graphql.NewNonNull(graphql.ID)},
+				"title": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return optional(p.Source.(seriesRef).Title), nil
+				}},
+				"books": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.book))),
+					Description: "The books in reading order.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return s.catalog.Series(p.Source.(seriesRef).ID), nil
+					},
+				},
+			}
+		}),
+	})
+}
+
+func (s *Schema) categoryType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"slug": {Type: graphql.NewNonNull(graphql.ID)},
+				"name": {Type: graphql.NewNonNull(graphql.String)},
+				"parent": {Type: s.category, Resolve: func(p graphql.ResolveParams) (any, error) {
+					if parent, ok := client.LookupCategory(p.Source.(model.Category).Parent); ok {
+						return parent, nil
+					}
+					return nil, nil
+				}},
+				"children": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.category))), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return childCategories(p.Source.(model.Category).Slug), nil
+				}},
+				"books": {
+					Type:        graphql.NewNonNull(s.connection),
+					Description: "Books the service has already seen in the category or its subcategories.",
+					Args:        pageArgsConfig,
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						page, err := pageArgs(p.Args)
+						if err != nil {
+							return nil, err
+						}
+						books, total := s.catalog.Category(p.Source.(model.Category).Slug, page.Offset, page.Limit)
+						return bookConnection{Books: books, Offset: min(page.Offset, total), Total: total}, nil
+					},
+				},
+			}
+		}),
+	})
+}
+
+func (s *Schema) connectionType() *graphql.Object {
+	edge := graphql.NewObject(graphql.ObjectConfig{
+		Name: "BookEdge",
+		Fields: graphql.Fields{
+			"cursor": {Type: graphql.NewNonNull(graphql.String)},
+			"node": {Type: graphql.NewNonNull(s.book), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(bookEdge).Book, nil
+			}},
+		},
+	})
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": {Type: graphql.NewNonNull(graphql.Boolean), Resolve: connectionField(func(c bookConnection) any { return c.hasNextPage() })},
+			"hasPreviousPage": {Type: graphql.NewNonNull(graphql.Boolean), Resolve: connectionField(func(c bookConnection) any {
+				return c.hasPreviousPage()
+			})},
+			"startCursor": {Type: graphql.String, Resolve: connectionField(func(c bookConnection) any {
+				if len(c.Books) == 0 {
+					return nil
+				}
+				return encodeCursor(c.Offset)
+			})},
+			"endCursor": {Type: graphql.String, Resolve: connectionField(func(c bookConnection) any {
+				if len(c.Books) == 0 {
+					return nil
+				}
+				return encodeCursor(c.Offset + len(c.Books) - 1)
+			})},
+		},
+	})
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        "BookConnection",
+		Description: "A page of books. Pass a cursor as after to get the books that follow it.",
+		Fields: graphql.Fields{
+			"totalCount": {Type: graphql.NewNonNull(graphql.Int), Description: "For searches, Google's estimate.", Resolve: connectionField(func(c bookConnection) any {
+				return c.Total
+			})},
+			"edges":    {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge))), Resolve: connectionField(func(c bookConnection) any { return c.edges() })},
+			"nodes":    {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(s.book))), Resolve: connectionField(func(c bookConnection) any { return c.Books })},
+			"pageInfo": {Type: graphql.NewNonNull(pageInfo), Resolve: connectionField(func(c bookConnection) any { return c })},
+		},
+	})
+}
+
+func (s *Schema) resolveBook(p graphql.ResolveParams) (any, error) {
+	load := loadersFrom(p.Context).volumes.load(p.Context, p.Args["id"].(string))
+	return func() (any, error) {
+		book, err := load()
+		if errors.Is(err, client.ErrVolumeNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, upstreamError(err)
+		}
+		return book, nil
+	}, nil
+}
+
+func (s *Schema) resolveBooks(p graphql.ResolveParams) (any, error) {
+	ids := p.Args["ids"].([]any)
+	if len(ids) > maxPageSize {
+		return nil, fmt.Errorf("ids must have at most %d IDs", maxPageSize)
+	}
+	volumes := loadersFrom(p.Context).volumes
+	loads := make([]func() (model.GoogleBookItem, error), 0, len(ids))
+	for _, id := range ids {
+		loads = append(loads, volumes.load(p.Context, id.(string)))
+	}
+	return func() (any, error) {
+		books := make([]any, 0, len(loads))
+		for _, load := range loads {
+			book, err := load()
+			if errors.Is(err, client.ErrVolumeNotFound) {
+				books = append(books, nil)
+				continue
+			}
+			if err != nil {
+				return nil, upstreamError(err)
+			}
+			books = append(books, book)
+		}
+		return books, nil
+	}, nil
+}
+
+func resolveAuthor(p graphql.ResolveParams) (any, error) {
+	name := strings.TrimSpace(p.Args["name"].(string))
+	if name == "" {
+		return nil, errors.New("name must not be empty")
+	}
+	return author{Name: name}, nil
+}
+
+func (s *Schema) resolveSeries(p graphql.ResolveParams) (any, error) {
+	id := p.Args["id"].(string)
+	// Unknown series
+	books := s.catalog.Series(id)
+	if len(books) == 0 {
+		return nil, nil
+	}
+	ref := seriesRef{ID: id}
+	for _, book := range books {
+		if series := client.DetectSeries(book); series != nil && series.Title != "" {
+			ref.Title = series.Title
+			break
+		}
+	}
+	return ref, nil
+}
+
+func resolveCategory(p graphql.ResolveParams) (any, error) {
+	if category, ok := client.LookupCategory(p.Args["slug"].(string)); ok {
+		return category, nil
+	}
+	return nil, nil
+}
+
+// childCategories returns the subcategories of parent, or the top-level
+// categories when parent is empty, in taxonomy order.
+func childCategories(parent string) []model.Category {
+	children := []model.Category{}
+	for _, category := range client.Categories() {
+		if category.Parent == parent {
+			children = append(children, category)
+		}
+	}
+	return children
+}
+
+func (s *Schema) resolveSearch(p graphql.ResolveParams) (any, error) {
+	page, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	request := client.GoogleBookRequest{Query: p.Args["query"].(string), Start: page.Offset, Limit: page.Limit}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	books, err := s.books.ByQuery(p.Context, request)
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+	return bookConnection{Books: books.Items, Offset: page.Offset, Total: books.TotalItems}, nil
+}
+
+func (s *Schema) resolveAuthorBooks(p graphql.ResolveParams) (any, error) {
+	page, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	load := loadersFrom(p.Context).authorBooks.load(p.Context, client.GoogleBookRequest{
+		Author: p.Source.(author).Name,
+		Start:  page.Offset,
+		Limit:  page.Limit,
+		SortBy: client.SortPublishedDesc,
+	})
+	return func() (any, error) {
+		books, err := load()
+		if err != nil {
+			return nil, upstreamError(err)
+		}
+		return bookConnection{Books: books.Items, Offset: page.Offset, Total: books.TotalItems}, nil
+	}, nil
+}
+
+func (s *Schema) resolveRelatedAuthors(p graphql.ResolveParams) (any, error) {
+	first, err := firstArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	authors := []author{}
+	for _, related := range s.graph.Related(p.Source.(author).Name, first) {
+		authors = append(authors, author{Name: related.Name})
+	}
+	return authors, nil
+}
+
+func (s *Schema) resolveSimilar(p graphql.ResolveParams) (any, error) {
+	first, err := firstArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	books := []model.GoogleBookItem{}
+	for _, recommendation := range s.recommender.Similar(p.Source.(model.GoogleBookItem), first) {
+		books = append(books, recommendation.Book)
+	}
+	return books, nil
+}
+
+func firstArg(args map[string]any) (int, error) {
+	first, _ := args["first"].(int)
+	if first < 1 || first > maxPageSize {
+		return 0, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+	}
+	return first, nil
+}
+
+func bookField(fn func(model.GoogleBookItem) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return fn(p.Source.(model.GoogleBookItem)), nil
+	}
+}
+
+func connectionField(fn func(bookConnection) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return fn(p.Source.(bookConnection)), nil
+	}
+}
+
+// optional returns null for empty strings, which Google uses for unknown
+// values.
+func optional(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
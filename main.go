@@ -2,26 +2,96 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 
 	client "example.com/book-learn/clients"
+	"example.com/book-learn/graph"
+	"example.com/book-learn/recommend"
 	"example.com/book-learn/routes"
+	"example.com/book-learn/rpc"
+	"example.com/book-learn/search"
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
 	r := chi.NewRouter()
+	catalog := client.NewCatalog()
+	authorGraph := client.NewAuthorGraph()
+	catalog.OnAdd(authorGraph.AddVolumes)
+	catalog.OnEvict(authorGraph.RemoveVolumes)
+	searchIndex := search.NewIndex()
+	catalog.OnAdd(searchIndex.Add)
+	catalog.OnEvict(searchIndex.Remove)
+	suggester := search.NewSuggester()
+	catalog.OnAdd(suggester.AddVolumes)
+	catalog.OnEvict(suggester.RemoveVolumes)
+	speller := search.NewSpeller()
+	catalog.OnAdd(speller.AddVolumes)
+	catalog.OnEvict(speller.RemoveVolumes)
 	bookClient := client.GoogleBookClient{
 		GetData:  http.Get,
 		PactMode: os.Getenv("PACT_MODE") == "true",
+		Catalog:  catalog,
+	}
+	if path := os.Getenv("RELEVANCE_WEIGHTS"); path != "" {
+		weights, err := client.LoadRelevanceWeights(path)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		bookClient.Weights = &weights
+	}
+	// Count the books users ask for so suggestions can rank by popularity
+	api := suggester.Track(bookClient)
+	recommender := recommend.New(catalog, authorGraph)
+	schema, err := graph.New(api, catalog, authorGraph, recommender)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
 	}
 
 	r.Route("/api", func(r chi.Router) {
-		routes.BooksRouter(r, bookClient)
+		routes.BooksRouter(r, api, catalog, speller)
+		routes.AuthorEventsRouter(r, api)
+		routes.SeriesRouter(r, catalog)
+		routes.CategoriesRouter(r, catalog)
+		routes.AuthorsRouter(r, api, authorGraph)
+		routes.RecommendationsRouter(r, api, recommender)
+		routes.SearchRouter(r, api, searchIndex, speller)
+		routes.SuggestRouter(r, suggester)
+		routes.BatchRouter(r, api)
+		routes.OnixRouter(r, api)
+		routes.OPDSRouter(r, api, catalog)
+		routes.GraphQLRouter(r, schema)
 		routes.HealthRouter(r)
 	})
 
+	// Backend services speak gRPC on a port of their own
+	grpcAddr := ":9090"
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		grpcAddr = ":" + port
+	}
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	grpcServer := grpc.NewServer()
+	rpc.Register(grpcServer, api)
+	reflection.Register(grpcServer)
+	go func() {
+		fmt.Println("gRPC listening on", listener.Addr())
+		if err := grpcServer.Serve(listener); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}()
+
 	// Server it up
 	fmt.Println("listening on http://localhost:8080")
 	http.ListenAndServe(":8080", r)
@@ -0,0 +1,162 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ISO 2709 delimiters.
+const (
+	SubfieldDelimiter = 0x1F
+	FieldTerminator   = 0x1E
+	RecordTerminator  = 0x1D
+)
+
+const (
+	directoryEntryLength = 12
+	maxFieldLength       = 9999
+	maxRecordLength      = 99999
+)
+
+var ErrRecordTooLong = errors.New("marc: record too long for ISO 2709")
+
+// MarshalBinary encodes the record in ISO 2709, filling in the record length
+// and base address of the leader. Lengths are counted in bytes of UTF-8.
+func (r Record) MarshalBinary() ([]byte, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	var directory, data bytes.Buffer
+	for _, field := range r.Fields {
+		start := data.Len()
+		if field.IsControl() {
+			data.WriteString(field.Value)
+		} else {
+			data.WriteByte(indicator(field.Indicator1))
+			data.WriteByte(indicator(field.Indicator2))
+			for _, subfield := range field.Subfields {
+				data.WriteByte(SubfieldDelimiter)
+				data.WriteByte(subfield.Code)
+				data.WriteString(subfield.Value)
+			}
+		}
+		data.WriteByte(FieldTerminator)
+		length := data.Len() - start
+		if length > maxFieldLength {
+			return nil, fmt.Errorf("%w: field %s is %d bytes", ErrRecordTooLong, field.Tag, length)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", field.Tag, length, start)
+	}
+	directory.WriteByte(FieldTerminator)
+
+	baseAddress := LeaderLength + directory.Len()
+	recordLength := baseAddress + data.Len() + 1
+	if recordLength > maxRecordLength {
+		return nil, fmt.Errorf("%w: %d bytes", ErrRecordTooLong, recordLength)
+	}
+	leader := fmt.Sprintf("%05d%s%05d%s", recordLength, r.Leader[5:12], baseAddress, r.Leader[17:])
+
+	out := make([]byte, 0, recordLength)
+	out = append(out, leader...)
+	out = append(out, directory.Bytes()...)
+	out = append(out, data.Bytes()...)
+	return append(out, RecordTerminator), nil
+}
+
+// UnmarshalBinary decodes a single ISO 2709 record.
+func (r *Record) UnmarshalBinary(data []byte) error {
+	if len(data) < LeaderLength+1 {
+		return errors.New("marc: record shorter than its leader")
+	}
+	leader := string(data[:LeaderLength])
+	recordLength, err := strconv.Atoi(leader[0:5])
+	if err != nil || recordLength != len(data) {
+		return fmt.Errorf("marc: record length %q does not match %d bytes", leader[0:5], len(data))
+	}
+	if data[len(data)-1] != RecordTerminator {
+		return errors.New("marc: missing record terminator")
+	}
+	baseAddress, err := strconv.Atoi(leader[12:17])
+	if err != nil || baseAddress < LeaderLength+1 || baseAddress > len(data) {
+		return fmt.Errorf("marc: invalid base address %q", leader[12:17])
+	}
+	directory := data[LeaderLength : baseAddress-1]
+	if data[baseAddress-1] != FieldTerminator || len(directory)%directoryEntryLength != 0 {
+		return errors.New("marc: malformed directory")
+	}
+
+	record := Record{Leader: leader}
+	for entry := 0; entry < len(directory); entry += directoryEntryLength {
+		tag := string(directory[entry : entry+3])
+		length, lengthErr := strconv.Atoi(string(directory[entry+3 : entry+7]))
+		start, startErr := strconv.Atoi(string(directory[entry+7 : entry+12]))
+		end := baseAddress + start + length
+		if lengthErr != nil || startErr != nil || length < 1 || end > len(data)-1 || data[end-1] != FieldTerminator {
+			return fmt.Errorf("marc: malformed directory entry for field %s", tag)
+		}
+		content := data[baseAddress+start : end-1]
+
+		field := Field{Tag: tag}
+		if field.IsControl() {
+			field.Value = string(content)
+			record.Fields = append(record.Fields, field)
+			continue
+		}
+		if len(content) < 2 {
+			return fmt.Errorf("marc: field %s has no indicators", tag)
+		}
+		field.Indicator1, field.Indicator2 = content[0], content[1]
+		for _, subfield := range bytes.Split(content[2:], []byte{SubfieldDelimiter})[1:] {
+			if len(subfield) == 0 {
+				return fmt.Errorf("marc: empty subfield in field %s", tag)
+			}
+			field.Subfields = append(field.Subfields, Subfield{Code: subfield[0], Value: string(subfield[1:])})
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	*r = record
+	return nil
+}
+
+// WriteBinary writes the records one after another, as in a .mrc file.
+func WriteBinary(w io.Writer, records []Record) error {
+	for _, record := range records {
+		data, err := record.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadBinary splits an ISO 2709 file into its records.
+func ReadBinary(data []byte) ([]Record, error) {
+	records := []Record{}
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, RecordTerminator)
+		if end < 0 {
+			return records, errors.New("marc: missing record terminator")
+		}
+		var record Record
+		if err := record.UnmarshalBinary(data[:end+1]); err != nil {
+			return records, err
+		}
+		records = append(records, record)
+		data = data[end+1:]
+	}
+	return records, nil
+}
+
+// indicator writes an unset indicator as the blank MARC expects.
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleRecord() Record {
+	record := NewRecord()
+	record.Add(
+		ControlField("001", "hNgmLwEACAAJ"),
+		DataField("020", ' ', ' ', Subfield{'a', "9780441117734"}),
+		DataField("100", '1', ' ', Subfield{'a', "Gibson, William,"}, Subfield{'e', "author."}),
+		DataField("245", '1', '0', Subfield{'a', "Count zero /"}, Subfield{'c', "William Gibson."}),
+		DataField("520", ' ', ' ', Subfield{'a', "Turner wakes up in a new body — “mostly”."}),
+		DataField("650", ' ', '4', Subfield{'a', ""}),
+	)
+	return record
+}
+
+func TestRecord_MarshalBinary(t *testing.T) {
+	data, err := sampleRecord().MarshalBinary()
+	assert.NoError(t, err)
+
+	// Leader with the computed length and base address: five directory
+	// entries, the 650 having been dropped for want of subfields.
+	baseAddress := LeaderLength + 5*directoryEntryLength + 1
+	assert.Equal(t, fmt.Sprintf("%05d", len(data)), string(data[0:5]))
+	assert.Equal(t, fmt.Sprintf("%05d", baseAddress), string(data[12:17]))
+	assert.Equal(t, "nam a22", string(data[5:12]))
+	assert.Equal(t, " i 4500", string(data[17:24]))
+	assert.Equal(t, "001001300000", string(data[24:36]))
+	assert.Equal(t, byte(FieldTerminator), data[baseAddress-1])
+	assert.Equal(t, byte(RecordTerminator), data[len(data)-1])
+	assert.Equal(t, "hNgmLwEACAAJ\x1e  \x1fa9780441117734\x1e", string(data[baseAddress:baseAddress+31]))
+}
+
+func TestRecord_binaryRoundTrip(t *testing.T) {
+	want := sampleRecord()
+	data, err := want.MarshalBinary()
+	assert.NoError(t, err)
+
+	var got Record
+	assert.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, want.Fields, got.Fields)
+	assert.Equal(t, want.Leader[5:12], got.Leader[5:12])
+	assert.Equal(t, "Turner wakes up in a new body — “mostly”.", got.Get("520")[0].Subfield('a'))
+	assert.Equal(t, "William Gibson.", got.Get("245")[0].Subfield('c'))
+}
+
+func TestReadBinary(t *testing.T) {
+	second := NewRecord()
+	second.Add(ControlField("001", "second"), DataField("245", '0', '0', Subfield{'a', "Neuromancer"}))
+	var buf bytes.Buffer
+	assert.NoError(t, WriteBinary(&buf, []Record{sampleRecord(), second}))
+
+	records, err := ReadBinary(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "hNgmLwEACAAJ", records[0].Get("001")[0].Value)
+	assert.Equal(t, "Neuromancer", records[1].Get("245")[0].Subfield('a'))
+}
+
+func TestRecord_UnmarshalBinary_errors(t *testing.T) {
+	data, _ := sampleRecord().MarshalBinary()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "short", data: data[:10]},
+		{name: "truncated", data: data[:len(data)-5]},
+		{name: "bad base address", data: append([]byte(string(data[:12])+"abcde"), data[17:]...)},
+		{name: "bad directory", data: append([]byte(string(data[:24])+"001999900000"), data[36:]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var record Record
+			assert.Error(t, record.UnmarshalBinary(tt.data))
+		})
+	}
+}
+
+func TestRecord_MarshalBinary_tooLong(t *testing.T) {
+	record := NewRecord()
+	record.Add(DataField("520", ' ', ' ', Subfield{'a', strings.Repeat("x", maxFieldLength)}))
+	_, err := record.MarshalBinary()
+	assert.True(t, errors.Is(err, ErrRecordTooLong))
+
+	record.Leader = "short"
+	_, err = record.MarshalBinary()
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+// Package marc reads and writes MARC 21 bibliographic records, both as
+// ISO 2709 exchange files and as MARCXML. It is pure Go and only covers what
+// the service needs to hand records to library systems.
+package marc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LeaderLength is the fixed size of a record's leader.
+const LeaderLength = 24
+
+// Record is a single MARC record. Fields keep the order they were added in,
+// which is also the order they are written in.
+type Record struct {
+	Leader string
+	Fields []Field
+}
+
+// Field is a control field, when Tag is 001 to 009 and only Value is set, or
+// a data field with indicators and subfields.
+type Field struct {
+	Tag        string
+	Value      string
+	Indicator1 byte
+	Indicator2 byte
+	Subfields  []Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// NewRecord starts a record with the leader of a Unicode monograph. The
+// length and base address are filled in when the record is written.
+func NewRecord() Record {
+	return Record{Leader: "00000nam a2200000 i 4500"}
+}
+
+// ControlField makes a field such as 001 or 008.
+func ControlField(tag string, value string) Field {
+	return Field{Tag: tag, Value: value}
+}
+
+// DataField makes a field from indicators and subfields, leaving out
+// subfields with an empty value.
+func DataField(tag string, ind1 byte, ind2 byte, subfields ...Subfield) Field {
+	field := Field{Tag: tag, Indicator1: ind1, Indicator2: ind2}
+	for _, subfield := range subfields {
+		if subfield.Value != "" {
+			field.Subfields = append(field.Subfields, subfield)
+		}
+	}
+	return field
+}
+
+// IsControl reports whether the field is a control field, which has a value
+// instead of indicators and subfields.
+func (f Field) IsControl() bool {
+	return strings.HasPrefix(f.Tag, "00")
+}
+
+// Add appends fields, skipping data fields left without subfields.
+func (r *Record) Add(fields ...Field) {
+	for _, field := range fields {
+		if !field.IsControl() && len(field.Subfields) == 0 {
+			continue
+		}
+		r.Fields = append(r.Fields, field)
+	}
+}
+
+// Get returns every field with the tag.
+func (r Record) Get(tag string) []Field {
+	fields := []Field{}
+	for _, field := range r.Fields {
+		if field.Tag == tag {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Subfield returns the first value of the subfield, or "".
+func (f Field) Subfield(code byte) string {
+	for _, subfield := range f.Subfields {
+		if subfield.Code == code {
+			return subfield.Value
+		}
+	}
+	return ""
+}
+
+func (r Record) validate() error {
+	if len(r.Leader) != LeaderLength {
+		return fmt.Errorf("marc: leader must be %d characters, got %d", LeaderLength, len(r.Leader))
+	}
+	for _, field := range r.Fields {
+		if len(field.Tag) != 3 {
+			return fmt.Errorf("marc: invalid tag %q", field.Tag)
+		}
+	}
+	return nil
+}
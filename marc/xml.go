@@ -0,0 +1,106 @@
+package marc
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Namespace is the MARCXML schema namespace.
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlCollection struct {
+	XMLName xml.Name    `xml:"http://www.loc.gov/MARC21/slim collection"`
+	Records []xmlRecord `xml:"record"`
+}
+
+type xmlRecord struct {
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteXML writes the records as a MARCXML collection. MARCXML lists control
+// fields before data fields, so a record's fields are written in that order.
+func WriteXML(w io.Writer, records []Record) error {
+	collection := xmlCollection{}
+	for _, record := range records {
+		if err := record.validate(); err != nil {
+			return err
+		}
+		xr := xmlRecord{Leader: record.Leader}
+		for _, field := range record.Fields {
+			if field.IsControl() {
+				xr.ControlFields = append(xr.ControlFields, xmlControlField{Tag: field.Tag, Value: field.Value})
+				continue
+			}
+			df := xmlDataField{Tag: field.Tag, Ind1: string(indicator(field.Indicator1)), Ind2: string(indicator(field.Indicator2))}
+			for _, subfield := range field.Subfields {
+				df.Subfields = append(df.Subfields, xmlSubfield{Code: string(subfield.Code), Value: subfield.Value})
+			}
+			xr.DataFields = append(xr.DataFields, df)
+		}
+		collection.Records = append(collection.Records, xr)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(collection); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadXML reads the records of a MARCXML collection.
+func ReadXML(r io.Reader) ([]Record, error) {
+	var collection xmlCollection
+	if err := xml.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, err
+	}
+	records := []Record{}
+	for _, xr := range collection.Records {
+		record := Record{Leader: xr.Leader}
+		for _, cf := range xr.ControlFields {
+			record.Fields = append(record.Fields, ControlField(cf.Tag, cf.Value))
+		}
+		for _, df := range xr.DataFields {
+			field := Field{Tag: df.Tag, Indicator1: firstByte(df.Ind1), Indicator2: firstByte(df.Ind2)}
+			for _, sf := range df.Subfields {
+				field.Subfields = append(field.Subfields, Subfield{Code: firstByte(sf.Code), Value: sf.Value})
+			}
+			record.Fields = append(record.Fields, field)
+		}
+		if err := record.validate(); err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func firstByte(s string) byte {
+	if s == "" {
+		return ' '
+	}
+	return s[0]
+}
@@ -0,0 +1,52 @@
+package marc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteXML(t *testing.T) {
+	record := NewRecord()
+	record.Add(
+		DataField("245", '1', '0', Subfield{'a', "Fish & <Chips>"}),
+		ControlField("001", "id-1"),
+	)
+	var buf bytes.Buffer
+	assert.NoError(t, WriteXML(&buf, []Record{record}))
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>00000nam a2200000 i 4500</leader>
+    <controlfield tag="001">id-1</controlfield>
+    <datafield tag="245" ind1="1" ind2="0">
+      <subfield code="a">Fish &amp; &lt;Chips&gt;</subfield>
+    </datafield>
+  </record>
+</collection>
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	second := NewRecord()
+	second.Add(ControlField("001", "second"), DataField("700", '1', ' ', Subfield{'a', "Sterling, Bruce,"}, Subfield{'e', "author."}))
+	want := []Record{sampleRecord(), second}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteXML(&buf, want))
+	got, err := ReadXML(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestReadXML_errors(t *testing.T) {
+	_, err := ReadXML(strings.NewReader(`<collection xmlns="http://www.loc.gov/MARC21/slim"><record><leader>short</leader></record></collection>`))
+	assert.Error(t, err)
+
+	_, err = ReadXML(strings.NewReader(`<collection xmlns="http://www.loc.gov/MARC21/slim"><record>`))
+	assert.Error(t, err)
+}
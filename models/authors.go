@@ -0,0 +1,62 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+// RelatedAuthor is an author linked to another by co-authorship or shared
+// categories. Weight combines both signals; higher is more closely related.
+type RelatedAuthor struct {
+	Name             string   `json:"name"`
+	Weight           float64  `json:"weight"`
+	CoAuthoredBooks  int      `json:"coAuthoredBooks"`
+	SharedCategories []string `json:"sharedCategories"`
+}
+
+// PersonName is an author's name split the way citation and library
+// formats want it. Names that cannot be split, such as "Homer", only have a
+// Family name.
+type PersonName struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// nameParticles are lowercase prefixes that belong to the family name, as in
+// "Ludwig van Beethoven".
+var nameParticles = []string{"da", "de", "del", "della", "der", "di", "du", "la", "le", "van", "von", "zu"}
+
+var nameSuffixes = []string{"jr", "jr.", "sr", "sr.", "ii", "iii", "iv"}
+
+// ParsePersonName splits "Given Family" and "Family, Given" forms.
+func ParsePersonName(name string) PersonName {
+	name = strings.Join(strings.Fields(name), " ")
+	if family, given, ok := strings.Cut(name, ","); ok {
+		person := PersonName{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)}
+		if slices.Contains(nameSuffixes, strings.ToLower(person.Given)) {
+			return ParsePersonName(family + " " + person.Given)
+		}
+		if given, suffix, ok := strings.Cut(person.Given, ","); ok && slices.Contains(nameSuffixes, strings.ToLower(strings.TrimSpace(suffix))) {
+			person.Given, person.Suffix = strings.TrimSpace(given), strings.TrimSpace(suffix)
+		}
+		return person
+	}
+
+	words := strings.Fields(name)
+	person := PersonName{}
+	if len(words) > 1 && slices.Contains(nameSuffixes, strings.ToLower(words[len(words)-1])) {
+		person.Suffix = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return person
+	}
+	family := len(words) - 1
+	for family > 1 && slices.Contains(nameParticles, words[family-1]) {
+		family--
+	}
+	person.Family = strings.Join(words[family:], " ")
+	person.Given = strings.Join(words[:family], " ")
+	return person
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePersonName(t *testing.T) {
+	tests := []struct {
+		name string
+		want PersonName
+	}{
+		{name: "William Gibson", want: PersonName{Family: "Gibson", Given: "William"}},
+		{name: "Ludwig van Beethoven", want: PersonName{Family: "van Beethoven", Given: "Ludwig"}},
+		{name: "Martin Luther King Jr.", want: PersonName{Family: "King", Given: "Martin Luther", Suffix: "Jr."}},
+		{name: "King, Martin Luther, Jr.", want: PersonName{Family: "King", Given: "Martin Luther", Suffix: "Jr."}},
+		{name: "Gibson, William", want: PersonName{Family: "Gibson", Given: "William"}},
+		{name: "Homer", want: PersonName{Family: "Homer"}},
+		{name: "", want: PersonName{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParsePersonName(tt.name))
+		})
+	}
+}
@@ -6,6 +6,8 @@ type GoogleBookResponse struct {
 	TotalItems   int              `json:"totalItems"`
 	HasMorePages bool             `json:"hasMorePages"`
 	Items        []GoogleBookItem `json:"items"`
+	// Filtered counts the items our filters removed from the response.
+	Filtered int `json:"filtered,omitempty"`
 }
 
 // GoogleBookItem represents individual items in the Items array.
@@ -18,11 +20,14 @@ type GoogleBookItem struct {
 	SaleInfo   GoogleBookSaleInfo   `json:"saleInfo"`
 	AccessInfo GoogleBookAccessInfo `json:"accessInfo"`
 	SearchInfo GoogleBookSearchInfo `json:"searchInfo"`
+	Relevance  *RelevanceScore      `json:"relevance,omitempty"`
+	Editions   []GoogleBookItem     `json:"editions,omitempty"`
 }
 
 // GoogleBookVolumeInfo contains detailed information about the volume.
 type GoogleBookVolumeInfo struct {
 	Title               string                         `json:"title"`
+	Subtitle            string                         `json:"subtitle"`
 	Authors             []string                       `json:"authors"`
 	Publisher           string                         `json:"publisher"`
 	PublishedDate       string                         `json:"publishedDate"`
@@ -30,6 +35,8 @@ type GoogleBookVolumeInfo struct {
 	IndustryIdentifiers []GoogleBookIndustryIdentifier `json:"industryIdentifiers"`
 	ReadingModes        GoogleBookReadingModes         `json:"readingModes"`
 	PageCount           int                            `json:"pageCount"`
+	AverageRating       float64                        `json:"averageRating"`
+	RatingsCount        int                            `json:"ratingsCount"`
 	PrintType           string                         `json:"printType"`
 	Categories          []string                       `json:"categories"`
 	MaturityRating      string                         `json:"maturityRating"`
@@ -37,6 +44,7 @@ type GoogleBookVolumeInfo struct {
 	ContentVersion      string                         `json:"contentVersion"`
 	PanelizationSummary GoogleBookPanelizationSummary  `json:"panelizationSummary"`
 	ImageLinks          GoogleBookImageLinks           `json:"imageLinks"`
+	SeriesInfo          *GoogleBookSeriesInfo          `json:"seriesInfo,omitempty"`
 	Language            string                         `json:"language"`
 	PreviewLink         string                         `json:"previewLink"`
 	InfoLink            string                         `json:"infoLink"`
@@ -67,11 +75,34 @@ type GoogleBookImageLinks struct {
 	Thumbnail      string `json:"thumbnail"`
 }
 
+// GoogleBookSeriesInfo links the volume to the series Google knows it belongs to.
+type GoogleBookSeriesInfo struct {
+	Kind              string                   `json:"kind"`
+	BookDisplayNumber string                   `json:"bookDisplayNumber"`
+	VolumeSeries      []GoogleBookVolumeSeries `json:"volumeSeries"`
+}
+
+// GoogleBookVolumeSeries gives the volume's place in a single series.
+type GoogleBookVolumeSeries struct {
+	SeriesID       string `json:"seriesId"`
+	SeriesBookType string `json:"seriesBookType"`
+	OrderNumber    int    `json:"orderNumber"`
+}
+
 // GoogleBookSaleInfo contains sale information about the book.
 type GoogleBookSaleInfo struct {
-	Country     string `json:"country"`
-	Saleability string `json:"saleability"`
-	IsEbook     bool   `json:"isEbook"`
+	Country     string           `json:"country"`
+	Saleability string           `json:"saleability"`
+	IsEbook     bool             `json:"isEbook"`
+	ListPrice   *GoogleBookPrice `json:"listPrice,omitempty"`
+	RetailPrice *GoogleBookPrice `json:"retailPrice,omitempty"`
+	BuyLink     string           `json:"buyLink,omitempty"`
+}
+
+// GoogleBookPrice is a price in a currency, given only for saleable volumes.
+type GoogleBookPrice struct {
+	Amount       float64 `json:"amount"`
+	CurrencyCode string  `json:"currencyCode"`
 }
 
 // GoogleBookAccessInfo contains access information about the book.
@@ -90,12 +121,15 @@ type GoogleBookAccessInfo struct {
 
 // GoogleBookEpubInfo contains epub-specific information.
 type GoogleBookEpubInfo struct {
-	IsAvailable bool `json:"isAvailable"`
+	IsAvailable  bool   `json:"isAvailable"`
+	DownloadLink string `json:"downloadLink,omitempty"`
+	AcsTokenLink string `json:"acsTokenLink,omitempty"`
 }
 
 // GoogleBookPdfInfo contains pdf-specific information.
 type GoogleBookPdfInfo struct {
 	IsAvailable  bool   `json:"isAvailable"`
+	DownloadLink string `json:"downloadLink,omitempty"`
 	AcsTokenLink string `json:"acsTokenLink"`
 }
 
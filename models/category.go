@@ -0,0 +1,10 @@
+package model
+
+// Category is a node in the normalized category taxonomy. Path lists the
+// slugs from the top-level category down to and including this one.
+type Category struct {
+	Slug   string   `json:"slug"`
+	Name   string   `json:"name"`
+	Parent string   `json:"parent,omitempty"`
+	Path   []string `json:"path"`
+}
@@ -0,0 +1,182 @@
+package model
+
+import (
+	"cmp"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DatePrecision describes how much of a PublicationDate is known.
+type DatePrecision int
+
+const (
+	DatePrecisionNone DatePrecision = iota
+	DatePrecisionCentury
+	DatePrecisionDecade
+	DatePrecisionYear
+	DatePrecisionMonth
+	DatePrecisionDay
+)
+
+var precisionNames = map[DatePrecision]string{
+	DatePrecisionNone:    "none",
+	DatePrecisionCentury: "century",
+	DatePrecisionDecade:  "decade",
+	DatePrecisionYear:    "year",
+	DatePrecisionMonth:   "month",
+	DatePrecisionDay:     "day",
+}
+
+func (p DatePrecision) String() string {
+	return precisionNames[p]
+}
+
+var (
+	fullDatePattern    = regexp.MustCompile(`^(\d{4})(?:-(\d{1,2})(?:-(\d{1,2})(?:T[\d:.]+(?:Z|[+-]\d{2}:?\d{2})?)?)?)?$`)
+	partialYearPattern = regexp.MustCompile(`^(\d{2,3})[*?uUxX-]+$`)
+)
+
+// PublicationDate is a publishedDate as reported by Google, which may be a
+// year, a month, a full date or a partial year such as "198*".
+type PublicationDate struct {
+	Raw       string
+	Year      int
+	Month     int
+	Day       int
+	Precision DatePrecision
+}
+
+// ParsePublicationDate parses a Google publishedDate. Values that cannot be
+// parsed have DatePrecisionNone and sort before every known date.
+func ParsePublicationDate(raw string) PublicationDate {
+	date := PublicationDate{Raw: raw}
+	value := strings.TrimSpace(raw)
+
+	if match := partialYearPattern.FindStringSubmatch(value); match != nil {
+		digits, _ := strconv.Atoi(match[1])
+		if len(match[1]) == 3 {
+			date.Year, date.Precision = digits*10, DatePrecisionDecade
+		} else {
+			date.Year, date.Precision = digits*100, DatePrecisionCentury
+		}
+		return date
+	}
+
+	match := fullDatePattern.FindStringSubmatch(value)
+	if match == nil {
+		return date
+	}
+	date.Year, _ = strconv.Atoi(match[1])
+	date.Precision = DatePrecisionYear
+	if month, _ := strconv.Atoi(match[2]); month >= 1 && month <= 12 {
+		date.Month, date.Precision = month, DatePrecisionMonth
+		day, _ := strconv.Atoi(match[3])
+		if day >= 1 && day <= daysIn(date.Year, month) {
+			date.Day, date.Precision = day, DatePrecisionDay
+		}
+	}
+	return date
+}
+
+func daysIn(year int, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// IsZero reports whether nothing is known about the date.
+func (d PublicationDate) IsZero() bool {
+	return d.Precision == DatePrecisionNone
+}
+
+// ExactYear returns the year when it is known exactly, and 0 for decades,
+// centuries and unknown dates.
+func (d PublicationDate) ExactYear() int {
+	if d.Precision < DatePrecisionYear {
+		return 0
+	}
+	return d.Year
+}
+
+// Start is the first day the date could refer to.
+func (d PublicationDate) Start() time.Time {
+	month, day := max(d.Month, 1), max(d.Day, 1)
+	return time.Date(d.Year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// End is the last day the date could refer to.
+func (d PublicationDate) End() time.Time {
+	switch d.Precision {
+	case DatePrecisionCentury:
+		return d.Start().AddDate(100, 0, -1)
+	case DatePrecisionDecade:
+		return d.Start().AddDate(10, 0, -1)
+	case DatePrecisionYear:
+		return d.Start().AddDate(1, 0, -1)
+	case DatePrecisionMonth:
+		return d.Start().AddDate(0, 1, -1)
+	}
+	return d.Start()
+}
+
+// Compare orders dates chronologically by their start, placing less precise
+// dates before more precise ones that start on the same day. Unknown dates
+// come first.
+func (d PublicationDate) Compare(other PublicationDate) int {
+	if d.IsZero() || other.IsZero() {
+		return cmp.Compare(d.Precision, other.Precision)
+	}
+	if c := d.Start().Compare(other.Start()); c != 0 {
+		return c
+	}
+	return cmp.Compare(d.Precision, other.Precision)
+}
+
+// String formats the date to its precision, e.g. "1984", "1984-06" or "1980s".
+func (d PublicationDate) String() string {
+	switch d.Precision {
+	case DatePrecisionCentury, DatePrecisionDecade:
+		return strconv.Itoa(d.Year) + "s"
+	case DatePrecisionYear:
+		return d.Start().Format("2006")
+	case DatePrecisionMonth:
+		return d.Start().Format("2006-01")
+	case DatePrecisionDay:
+		return d.Start().Format("2006-01-02")
+	}
+	return ""
+}
+
+func (d PublicationDate) MarshalJSON() ([]byte, error) {
+	type normalizedDate struct {
+		Raw       string  `json:"raw"`
+		Date      *string `json:"date"`
+		Display   string  `json:"display"`
+		Precision string  `json:"precision"`
+	}
+	out := normalizedDate{Raw: d.Raw, Display: d.String(), Precision: d.Precision.String()}
+	if !d.IsZero() {
+		start := d.Start().Format("2006-01-02")
+		out.Date = &start
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON accepts either a raw publishedDate string or the object
+// produced by MarshalJSON.
+func (d *PublicationDate) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*d = ParsePublicationDate(raw)
+		return nil
+	}
+	var normalized struct {
+		Raw string `json:"raw"`
+	}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return err
+	}
+	*d = ParsePublicationDate(normalized.Raw)
+	return nil
+}
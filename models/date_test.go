@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePublicationDate(t *testing.T) {
+	tests := []struct {
+		raw       string
+		want      string
+		precision DatePrecision
+	}{
+		{raw: "1984", want: "1984", precision: DatePrecisionYear},
+		{raw: "1984-06", want: "1984-06", precision: DatePrecisionMonth},
+		{raw: "1984-06-08", want: "1984-06-08", precision: DatePrecisionDay},
+		{raw: "1984-6-8", want: "1984-06-08", precision: DatePrecisionDay},
+		{raw: "2012-05-01T00:00:00Z", want: "2012-05-01", precision: DatePrecisionDay},
+		{raw: "198*", want: "1980s", precision: DatePrecisionDecade},
+		{raw: "19??", want: "1900s", precision: DatePrecisionCentury},
+		{raw: "1984-13", want: "1984", precision: DatePrecisionYear},
+		{raw: "1984-02-30", want: "1984-02", precision: DatePrecisionMonth},
+		{raw: "", want: "", precision: DatePrecisionNone},
+		{raw: "unknown", want: "", precision: DatePrecisionNone},
+		{raw: "19845", want: "", precision: DatePrecisionNone},
+		{raw: "1984-05-123", want: "", precision: DatePrecisionNone},
+		{raw: "1984-05-12 and later", want: "", precision: DatePrecisionNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got := ParsePublicationDate(tt.raw)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.precision, got.Precision)
+			assert.Equal(t, tt.raw, got.Raw)
+		})
+	}
+}
+
+func TestPublicationDate_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{name: "earlier year", a: "1983", b: "1984-06-08", want: -1},
+		{name: "later month", a: "1984-07", b: "1984-06-08", want: 1},
+		{name: "year before day in same year", a: "1984", b: "1984-01-01", want: -1},
+		{name: "decade before year", a: "198*", b: "1980", want: -1},
+		{name: "unknown first", a: "", b: "1066", want: -1},
+		{name: "both unknown", a: "", b: "n/a", want: 0},
+		{name: "equal", a: "1984-06", b: "1984-06", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParsePublicationDate(tt.a).Compare(ParsePublicationDate(tt.b)))
+		})
+	}
+}
+
+func TestPublicationDate_ExactYear(t *testing.T) {
+	assert.Equal(t, 1984, ParsePublicationDate("1984-06").ExactYear())
+	assert.Equal(t, 0, ParsePublicationDate("198*").ExactYear())
+	assert.Equal(t, 0, ParsePublicationDate("19??").ExactYear())
+	assert.Equal(t, 0, ParsePublicationDate("").ExactYear())
+}
+
+func TestPublicationDate_End(t *testing.T) {
+	assert.Equal(t, "1984-12-31", ParsePublicationDate("1984").End().Format("2006-01-02"))
+	assert.Equal(t, "1984-02-29", ParsePublicationDate("1984-02").End().Format("2006-01-02"))
+	assert.Equal(t, "1989-12-31", ParsePublicationDate("198*").End().Format("2006-01-02"))
+	assert.Equal(t, "1984-06-08", ParsePublicationDate("1984-06-08").End().Format("2006-01-02"))
+}
+
+func TestPublicationDate_JSON(t *testing.T) {
+	data, err := json.Marshal(ParsePublicationDate("1984-06"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"raw":"1984-06","date":"1984-06-01","display":"1984-06","precision":"month"}`, string(data))
+
+	data, err = json.Marshal(ParsePublicationDate(""))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"raw":"","date":null,"display":"","precision":"none"}`, string(data))
+
+	var fromObject, fromString PublicationDate
+	assert.NoError(t, json.Unmarshal([]byte(`{"raw":"1984-06"}`), &fromObject))
+	assert.NoError(t, json.Unmarshal([]byte(`"1984-06"`), &fromString))
+	assert.Equal(t, ParsePublicationDate("1984-06"), fromObject)
+	assert.Equal(t, fromObject, fromString)
+}
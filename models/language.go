@@ -0,0 +1,16 @@
+package model
+
+import "strings"
+
+// bibliographicLanguages maps the ISO 639-1 codes Google reports to the
+// ISO 639-2/B codes library and trade formats such as MARC and ONIX use.
+var bibliographicLanguages = map[string]string{
+	"de": "ger", "en": "eng", "es": "spa", "fr": "fre", "it": "ita", "ja": "jpn",
+	"nl": "dut", "pl": "pol", "pt": "por", "ru": "rus", "sv": "swe", "zh": "chi",
+}
+
+// BibliographicLanguage returns the ISO 639-2/B code for a language tag such
+// as "en" or "en-GB", or "" when it is not known.
+func BibliographicLanguage(tag string) string {
+	return bibliographicLanguages[strings.ToLower(strings.SplitN(tag, "-", 2)[0])]
+}
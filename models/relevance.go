@@ -0,0 +1,15 @@
+package model
+
+// RelevanceScore explains how a volume was ranked against a search request.
+type RelevanceScore struct {
+	Total      float64              `json:"total"`
+	Components []RelevanceComponent `json:"components"`
+}
+
+// RelevanceComponent is a single weighted signal contributing to a RelevanceScore.
+type RelevanceComponent struct {
+	Signal string  `json:"signal"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+	Score  float64 `json:"score"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// Series identifies the series a volume belongs to and its place in it.
+type Series struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Position int    `json:"position,omitempty"`
+	Source   string `json:"source"`
+}
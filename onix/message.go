@@ -0,0 +1,433 @@
+// Package onix describes volumes as ONIX for Books 3.0 product records, the
+// XML format the book trade uses to exchange metadata with retailers. Only
+// the reference tag names are produced.
+package onix
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+)
+
+const (
+	Namespace = "http://ns.editeur.org/onix/3.0/reference"
+	Release   = "3.0"
+	// RecordReferencePrefix namespaces record references, which must be
+	// unique for the sender, by the source of the volume IDs.
+	RecordReferencePrefix = "com.google.books."
+)
+
+// Message is an ONIX message: a header and one Product for each volume.
+type Message struct {
+	XMLName  xml.Name  `xml:"http://ns.editeur.org/onix/3.0/reference ONIXMessage"`
+	Release  string    `xml:"release,attr"`
+	Header   Header    `xml:"Header"`
+	Products []Product `xml:"Product"`
+}
+
+type Header struct {
+	Sender       Sender `xml:"Sender"`
+	SentDateTime string `xml:"SentDateTime"`
+}
+
+type Sender struct {
+	SenderName string `xml:"SenderName"`
+}
+
+type Product struct {
+	RecordReference    string              `xml:"RecordReference"`
+	NotificationType   string              `xml:"NotificationType"`
+	ProductIdentifiers []ProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  DescriptiveDetail   `xml:"DescriptiveDetail"`
+	CollateralDetail   *CollateralDetail   `xml:"CollateralDetail,omitempty"`
+	PublishingDetail   PublishingDetail    `xml:"PublishingDetail"`
+	ProductSupply      *ProductSupply      `xml:"ProductSupply,omitempty"`
+}
+
+type ProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDTypeName    string `xml:"IDTypeName,omitempty"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type DescriptiveDetail struct {
+	ProductComposition string        `xml:"ProductComposition"`
+	ProductForm        string        `xml:"ProductForm"`
+	ProductFormDetails []string      `xml:"ProductFormDetail"`
+	Collections        []Collection  `xml:"Collection"`
+	TitleDetail        TitleDetail   `xml:"TitleDetail"`
+	Contributors       []Contributor `xml:"Contributor"`
+	NoContributor      *struct{}     `xml:"NoContributor"`
+	Languages          []Language    `xml:"Language"`
+	Extents            []Extent      `xml:"Extent"`
+	Subjects           []Subject     `xml:"Subject"`
+}
+
+type Collection struct {
+	CollectionType string      `xml:"CollectionType"`
+	TitleDetail    TitleDetail `xml:"TitleDetail"`
+}
+
+type TitleDetail struct {
+	TitleType    string       `xml:"TitleType"`
+	TitleElement TitleElement `xml:"TitleElement"`
+}
+
+type TitleElement struct {
+	TitleElementLevel string `xml:"TitleElementLevel"`
+	PartNumber        string `xml:"PartNumber,omitempty"`
+	TitleText         string `xml:"TitleText"`
+	Subtitle          string `xml:"Subtitle,omitempty"`
+}
+
+type Contributor struct {
+	SequenceNumber     int    `xml:"SequenceNumber"`
+	ContributorRole    string `xml:"ContributorRole"`
+	PersonName         string `xml:"PersonName"`
+	PersonNameInverted string `xml:"PersonNameInverted,omitempty"`
+	NamesBeforeKey     string `xml:"NamesBeforeKey,omitempty"`
+	KeyNames           string `xml:"KeyNames,omitempty"`
+	SuffixToKey        string `xml:"SuffixToKey,omitempty"`
+}
+
+type Language struct {
+	LanguageRole string `xml:"LanguageRole"`
+	LanguageCode string `xml:"LanguageCode"`
+}
+
+type Extent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue string `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+type Subject struct {
+	MainSubject             *struct{} `xml:"MainSubject"`
+	SubjectSchemeIdentifier string    `xml:"SubjectSchemeIdentifier"`
+	SubjectSchemeName       string    `xml:"SubjectSchemeName,omitempty"`
+	SubjectHeadingText      string    `xml:"SubjectHeadingText"`
+}
+
+type CollateralDetail struct {
+	TextContents        []TextContent        `xml:"TextContent"`
+	SupportingResources []SupportingResource `xml:"SupportingResource"`
+}
+
+type TextContent struct {
+	TextType        string `xml:"TextType"`
+	ContentAudience string `xml:"ContentAudience"`
+	Text            Text   `xml:"Text"`
+}
+
+type Text struct {
+	TextFormat string `xml:"textformat,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+type SupportingResource struct {
+	ResourceContentType string          `xml:"ResourceContentType"`
+	ContentAudience     string          `xml:"ContentAudience"`
+	ResourceMode        string          `xml:"ResourceMode"`
+	ResourceVersion     ResourceVersion `xml:"ResourceVersion"`
+}
+
+type ResourceVersion struct {
+	ResourceForm string `xml:"ResourceForm"`
+	ResourceLink string `xml:"ResourceLink"`
+}
+
+type PublishingDetail struct {
+	Publishers       []Publisher      `xml:"Publisher"`
+	PublishingStatus string           `xml:"PublishingStatus"`
+	PublishingDates  []PublishingDate `xml:"PublishingDate"`
+}
+
+type Publisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+type PublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               Date   `xml:"Date"`
+}
+
+type Date struct {
+	DateFormat string `xml:"dateformat,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+type ProductSupply struct {
+	SupplyDetail SupplyDetail `xml:"SupplyDetail"`
+}
+
+type SupplyDetail struct {
+	Supplier            Supplier `xml:"Supplier"`
+	ProductAvailability string   `xml:"ProductAvailability"`
+	Prices              []Price  `xml:"Price"`
+}
+
+type Supplier struct {
+	SupplierRole string    `xml:"SupplierRole"`
+	SupplierName string    `xml:"SupplierName"`
+	Websites     []Website `xml:"Website"`
+}
+
+type Website struct {
+	WebsiteRole string `xml:"WebsiteRole"`
+	WebsiteLink string `xml:"WebsiteLink"`
+}
+
+type Price struct {
+	PriceType    string     `xml:"PriceType"`
+	PriceAmount  string     `xml:"PriceAmount"`
+	CurrencyCode string     `xml:"CurrencyCode"`
+	Territory    *Territory `xml:"Territory,omitempty"`
+}
+
+type Territory struct {
+	CountriesIncluded string `xml:"CountriesIncluded"`
+}
+
+// NewMessage builds a message from sender describing the volumes.
+func NewMessage(sender string, sent time.Time, items []model.GoogleBookItem) Message {
+	message := Message{
+		Release: Release,
+		Header: Header{
+			Sender:       Sender{SenderName: sender},
+			SentDateTime: sent.UTC().Format("20060102T1504Z"),
+		},
+	}
+	for _, item := range items {
+		message.Products = append(message.Products, NewProduct(item))
+	}
+	return message
+}
+
+// NewProduct maps a volume to a Product record. Codes are from the ONIX
+// code lists, named in the comments by list number.
+func NewProduct(item model.GoogleBookItem) Product {
+	info := item.VolumeInfo
+	product := Product{
+		RecordReference: RecordReferencePrefix + item.ID,
+		// List 1: confirmed record
+		NotificationType: "03",
+	}
+
+	// List 5: 15 ISBN-13, 02 ISBN-10, 01 proprietary
+	for _, id := range info.IndustryIdentifiers {
+		switch id.Type {
+		case "ISBN_13":
+			product.ProductIdentifiers = append(product.ProductIdentifiers, ProductIdentifier{ProductIDType: "15", IDValue: id.Identifier})
+		case "ISBN_10":
+			product.ProductIdentifiers = append(product.ProductIdentifiers, ProductIdentifier{ProductIDType: "02", IDValue: id.Identifier})
+		}
+	}
+	product.ProductIdentifiers = append(product.ProductIdentifiers, ProductIdentifier{ProductIDType: "01", IDTypeName: "Google Books ID", IDValue: item.ID})
+
+	product.DescriptiveDetail = descriptiveDetail(item)
+	product.CollateralDetail = collateralDetail(info)
+	product.PublishingDetail = publishingDetail(item)
+	product.ProductSupply = productSupply(item.SaleInfo)
+	return product
+}
+
+func descriptiveDetail(item model.GoogleBookItem) DescriptiveDetail {
+	info := item.VolumeInfo
+	detail := DescriptiveDetail{
+		// List 2: single-component retail product
+		ProductComposition: "00",
+		// List 150: BA book, EA digital
+		ProductForm: "BA",
+		TitleDetail: TitleDetail{
+			// List 15: distinctive title; list 149: product level
+			TitleType:    "01",
+			TitleElement: TitleElement{TitleElementLevel: "01", TitleText: info.Title, Subtitle: info.Subtitle},
+		},
+	}
+	if item.SaleInfo.IsEbook {
+		detail.ProductForm = "EA"
+		// List 175: E101 EPUB, E107 PDF
+		if item.AccessInfo.Epub.IsAvailable {
+			detail.ProductFormDetails = append(detail.ProductFormDetails, "E101")
+		}
+		if item.AccessInfo.Pdf.IsAvailable {
+			detail.ProductFormDetails = append(detail.ProductFormDetails, "E107")
+		}
+	}
+
+	if series := client.DetectSeries(item); series != nil && series.Title != "" {
+		collection := Collection{
+			// List 148: publisher collection; list 149: collection level
+			CollectionType: "10",
+			TitleDetail:    TitleDetail{TitleType: "01", TitleElement: TitleElement{TitleElementLevel: "02", TitleText: series.Title}},
+		}
+		if series.Position > 0 {
+			collection.TitleDetail.TitleElement.PartNumber = strconv.Itoa(series.Position)
+		}
+		detail.Collections = append(detail.Collections, collection)
+	}
+
+	for i, author := range info.Authors {
+		name := model.ParsePersonName(author)
+		contributor := Contributor{
+			SequenceNumber: i + 1,
+			// List 17: A01 by (author)
+			ContributorRole: "A01",
+			PersonName:      strings.Join(strings.Fields(author), " "),
+			NamesBeforeKey:  name.Given,
+			KeyNames:        name.Family,
+			SuffixToKey:     name.Suffix,
+		}
+		if name.Given != "" {
+			contributor.PersonNameInverted = name.Family + ", " + name.Given
+		}
+		detail.Contributors = append(detail.Contributors, contributor)
+	}
+	if len(detail.Contributors) == 0 {
+		detail.NoContributor = &struct{}{}
+	}
+
+	if language := model.BibliographicLanguage(info.Language); language != "" {
+		// List 22: language of text
+		detail.Languages = append(detail.Languages, Language{LanguageRole: "01", LanguageCode: language})
+	}
+	if info.PageCount > 0 {
+		// List 23: main content page count; list 24: pages
+		detail.Extents = append(detail.Extents, Extent{ExtentType: "00", ExtentValue: strconv.Itoa(info.PageCount), ExtentUnit: "03"})
+	}
+	for i, category := range info.Categories {
+		// List 27: 24 proprietary scheme
+		subject := Subject{SubjectSchemeIdentifier: "24", SubjectSchemeName: "Google Books categories", SubjectHeadingText: category}
+		if i == 0 {
+			subject.MainSubject = &struct{}{}
+		}
+		detail.Subjects = append(detail.Subjects, subject)
+	}
+	return detail
+}
+
+func collateralDetail(info model.GoogleBookVolumeInfo) *CollateralDetail {
+	detail := CollateralDetail{}
+	if description := strings.TrimSpace(info.Description); description != "" {
+		// List 153: 03 description; list 154: unrestricted; list 34: 06 plain text
+		detail.TextContents = append(detail.TextContents, TextContent{
+			TextType:        "03",
+			ContentAudience: "00",
+			Text:            Text{TextFormat: "06", Value: description},
+		})
+	}
+	if cover := info.ImageLinks.Thumbnail; cover != "" {
+		// List 158: 01 front cover; list 159: 03 image; list 161: 01 linkable
+		detail.SupportingResources = append(detail.SupportingResources, SupportingResource{
+			ResourceContentType: "01",
+			ContentAudience:     "00",
+			ResourceMode:        "03",
+			ResourceVersion:     ResourceVersion{ResourceForm: "01", ResourceLink: cover},
+		})
+	}
+	if len(detail.TextContents) == 0 && len(detail.SupportingResources) == 0 {
+		return nil
+	}
+	return &detail
+}
+
+func publishingDetail(item model.GoogleBookItem) PublishingDetail {
+	info := item.VolumeInfo
+	detail := PublishingDetail{
+		// List 64: 00 unspecified, 04 active
+		PublishingStatus: "00",
+	}
+	if info.Publisher != "" {
+		// List 45: 01 publisher
+		detail.Publishers = append(detail.Publishers, Publisher{PublishingRole: "01", PublisherName: info.Publisher})
+	}
+	if forSale(item.SaleInfo) {
+		detail.PublishingStatus = "04"
+	}
+	if date := publicationDate(model.ParsePublicationDate(info.PublishedDate)); date.Value != "" {
+		// List 163: 01 publication date
+		detail.PublishingDates = append(detail.PublishingDates, PublishingDate{PublishingDateRole: "01", Date: date})
+	}
+	return detail
+}
+
+// publicationDate formats a date to the precision known. List 55: 00
+// YYYYMMDD, 01 YYYYMM, 05 YYYY. A decade or century has no format of its
+// own and is left out rather than passed off as its first year.
+func publicationDate(date model.PublicationDate) Date {
+	switch date.Precision {
+	case model.DatePrecisionYear:
+		return Date{DateFormat: "05", Value: fmt.Sprintf("%04d", date.Year)}
+	case model.DatePrecisionMonth:
+		return Date{DateFormat: "01", Value: fmt.Sprintf("%04d%02d", date.Year, date.Month)}
+	case model.DatePrecisionDay:
+		return Date{DateFormat: "00", Value: fmt.Sprintf("%04d%02d%02d", date.Year, date.Month, date.Day)}
+	default:
+		return Date{}
+	}
+}
+
+func forSale(sale model.GoogleBookSaleInfo) bool {
+	return sale.Saleability == "FOR_SALE" || sale.Saleability == "FOR_SALE_AND_RENTAL"
+}
+
+// productSupply describes Google Play as a retailer of the volume, when it
+// knows whether the volume is on sale.
+func productSupply(sale model.GoogleBookSaleInfo) *ProductSupply {
+	if sale.Saleability == "" {
+		return nil
+	}
+	detail := SupplyDetail{
+		// List 93: 08 retailer
+		Supplier: Supplier{SupplierRole: "08", SupplierName: "Google Play"},
+		// List 65: 20 available, 40 not available
+		ProductAvailability: "40",
+	}
+	if forSale(sale) {
+		detail.ProductAvailability = "20"
+	}
+	if sale.BuyLink != "" {
+		// List 73: 02 supplier's retail website
+		detail.Supplier.Websites = append(detail.Supplier.Websites, Website{WebsiteRole: "02", WebsiteLink: sale.BuyLink})
+	}
+	// List 58: 02 RRP including tax, 04 fixed retail price including tax
+	for _, price := range []struct {
+		kind  string
+		price *model.GoogleBookPrice
+	}{{kind: "02", price: sale.ListPrice}, {kind: "04", price: sale.RetailPrice}} {
+		if price.price == nil {
+			continue
+		}
+		onixPrice := Price{
+			PriceType:    price.kind,
+			PriceAmount:  strconv.FormatFloat(price.price.Amount, 'f', 2, 64),
+			CurrencyCode: price.price.CurrencyCode,
+		}
+		if sale.Country != "" {
+			onixPrice.Territory = &Territory{CountriesIncluded: sale.Country}
+		}
+		detail.Prices = append(detail.Prices, onixPrice)
+	}
+	return &ProductSupply{SupplyDetail: detail}
+}
+
+// Write writes the message as an XML document.
+func (m Message) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(m); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,171 @@
+package onix
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var sent = time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func pactItems(t *testing.T) []model.GoogleBookItem {
+	bc := client.GoogleBookClient{PactMode: true, Catalog: client.NewCatalog()}
+	authors, err := bc.ByAuthor(context.Background(), client.GoogleBookRequest{Author: "William Gibson"})
+	assert.NoError(t, err)
+	titles, err := bc.ByTitle(context.Background(), client.GoogleBookRequest{Title: "Count Zero"})
+	assert.NoError(t, err)
+	return append(authors.Items, titles.Items...)
+}
+
+func saleItem() model.GoogleBookItem {
+	return model.GoogleBookItem{
+		ID: "VJvQDSqL3f8C",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:         "Count Zero",
+			Subtitle:      "Sprawl Trilogy 2",
+			Authors:       []string{"William Gibson", "Homer"},
+			Publisher:     "Ace",
+			PublishedDate: "1987-04-15",
+			Description:   "Turner wakes up & <runs>.",
+			IndustryIdentifiers: []model.GoogleBookIndustryIdentifier{
+				{Type: "ISBN_13", Identifier: "9780441117734"},
+				{Type: "ISBN_10", Identifier: "0441117732"},
+				{Type: "OTHER", Identifier: "OCLC:1"},
+			},
+			PageCount:  256,
+			Categories: []string{"Fiction / Science Fiction / Cyberpunk", "Fiction"},
+			Language:   "en",
+			ImageLinks: model.GoogleBookImageLinks{Thumbnail: "http://books.example/cover?id=1&zoom=1"},
+		},
+		SaleInfo: model.GoogleBookSaleInfo{
+			Country:     "US",
+			Saleability: "FOR_SALE",
+			IsEbook:     true,
+			ListPrice:   &model.GoogleBookPrice{Amount: 25, CurrencyCode: "USD"},
+			RetailPrice: &model.GoogleBookPrice{Amount: 14.75, CurrencyCode: "USD"},
+			BuyLink:     "https://play.example/store?id=1",
+		},
+		AccessInfo: model.GoogleBookAccessInfo{Epub: model.GoogleBookEpubInfo{IsAvailable: true}},
+	}
+}
+
+func TestNewProduct(t *testing.T) {
+	product := NewProduct(saleItem())
+
+	assert.Equal(t, "com.google.books.VJvQDSqL3f8C", product.RecordReference)
+	assert.Equal(t, []ProductIdentifier{
+		{ProductIDType: "15", IDValue: "9780441117734"},
+		{ProductIDType: "02", IDValue: "0441117732"},
+		{ProductIDType: "01", IDTypeName: "Google Books ID", IDValue: "VJvQDSqL3f8C"},
+	}, product.ProductIdentifiers)
+
+	detail := product.DescriptiveDetail
+	assert.Equal(t, "EA", detail.ProductForm)
+	assert.Equal(t, []string{"E101"}, detail.ProductFormDetails)
+	assert.Equal(t, TitleElement{TitleElementLevel: "01", TitleText: "Count Zero", Subtitle: "Sprawl Trilogy 2"}, detail.TitleDetail.TitleElement)
+	assert.Equal(t, []Contributor{
+		{SequenceNumber: 1, ContributorRole: "A01", PersonName: "William Gibson", PersonNameInverted: "Gibson, William", NamesBeforeKey: "William", KeyNames: "Gibson"},
+		{SequenceNumber: 2, ContributorRole: "A01", PersonName: "Homer", KeyNames: "Homer"},
+	}, detail.Contributors)
+	assert.Nil(t, detail.NoContributor)
+	assert.Equal(t, []Language{{LanguageRole: "01", LanguageCode: "eng"}}, detail.Languages)
+	assert.Equal(t, []Extent{{ExtentType: "00", ExtentValue: "256", ExtentUnit: "03"}}, detail.Extents)
+	assert.Len(t, detail.Subjects, 2)
+	assert.NotNil(t, detail.Subjects[0].MainSubject)
+	assert.Nil(t, detail.Subjects[1].MainSubject)
+	assert.Equal(t, "Fiction / Science Fiction / Cyberpunk", detail.Subjects[0].SubjectHeadingText)
+
+	assert.Equal(t, "Turner wakes up & <runs>.", product.CollateralDetail.TextContents[0].Text.Value)
+	assert.Equal(t, "http://books.example/cover?id=1&zoom=1", product.CollateralDetail.SupportingResources[0].ResourceVersion.ResourceLink)
+
+	assert.Equal(t, PublishingDetail{
+		Publishers:       []Publisher{{PublishingRole: "01", PublisherName: "Ace"}},
+		PublishingStatus: "04",
+		PublishingDates:  []PublishingDate{{PublishingDateRole: "01", Date: Date{DateFormat: "00", Value: "19870415"}}},
+	}, product.PublishingDetail)
+
+	supply := product.ProductSupply.SupplyDetail
+	assert.Equal(t, "20", supply.ProductAvailability)
+	assert.Equal(t, []Website{{WebsiteRole: "02", WebsiteLink: "https://play.example/store?id=1"}}, supply.Supplier.Websites)
+	assert.Equal(t, []Price{
+		{PriceType: "02", PriceAmount: "25.00", CurrencyCode: "USD", Territory: &Territory{CountriesIncluded: "US"}},
+		{PriceType: "04", PriceAmount: "14.75", CurrencyCode: "USD", Territory: &Territory{CountriesIncluded: "US"}},
+	}, supply.Prices)
+}
+
+func TestNewProduct_sparseVolume(t *testing.T) {
+	product := NewProduct(model.GoogleBookItem{ID: "id", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Untitled", PublishedDate: "1991-03"}})
+
+	assert.Equal(t, "BA", product.DescriptiveDetail.ProductForm)
+	assert.NotNil(t, product.DescriptiveDetail.NoContributor)
+	assert.Nil(t, product.CollateralDetail)
+	assert.Nil(t, product.ProductSupply)
+	assert.Equal(t, "00", product.PublishingDetail.PublishingStatus)
+	assert.Equal(t, Date{DateFormat: "01", Value: "199103"}, product.PublishingDetail.PublishingDates[0].Date)
+}
+
+func TestPublicationDate(t *testing.T) {
+	tests := []struct {
+		date string
+		want Date
+	}{
+		{date: "1987-04-15", want: Date{DateFormat: "00", Value: "19870415"}},
+		{date: "1991-03", want: Date{DateFormat: "01", Value: "199103"}},
+		{date: "1984", want: Date{DateFormat: "05", Value: "1984"}},
+		{date: "198*", want: Date{}},
+		{date: "18??", want: Date{}},
+		{date: "", want: Date{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			assert.Equal(t, tt.want, publicationDate(model.ParsePublicationDate(tt.date)))
+		})
+	}
+}
+
+func TestMessage_validatesAgainstSchema(t *testing.T) {
+	schema := loadSubsetSchema(t, "testdata/onix-3.0-subset.xsd")
+	items := append(pactItems(t), saleItem(), model.GoogleBookItem{ID: "bare", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Bare"}})
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewMessage("book-learn", sent, items).Write(&buf))
+
+	assert.True(t, strings.HasPrefix(buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, buf.String(), `<ONIXMessage xmlns="http://ns.editeur.org/onix/3.0/reference" release="3.0">`)
+	assert.Contains(t, buf.String(), "<SentDateTime>20260301T0930Z</SentDateTime>")
+	assert.Contains(t, buf.String(), "<Text textformat=\"06\">Turner wakes up &amp; &lt;runs&gt;.</Text>")
+	assert.Empty(t, schema.Validate(buf.Bytes()))
+}
+
+func TestSubsetSchema_rejectsInvalidMessages(t *testing.T) {
+	schema := loadSubsetSchema(t, "testdata/onix-3.0-subset.xsd")
+	var buf bytes.Buffer
+	assert.NoError(t, NewMessage("book-learn", sent, []model.GoogleBookItem{saleItem()}).Write(&buf))
+	valid := buf.String()
+
+	tests := []struct {
+		name    string
+		replace [2]string
+	}{
+		{name: "bad code", replace: [2]string{"<NotificationType>03<", "<NotificationType>3<"}},
+		{name: "missing element", replace: [2]string{"<RecordReference>com.google.books.VJvQDSqL3f8C</RecordReference>", ""}},
+		{name: "wrong order", replace: [2]string{"<ProductComposition>00</ProductComposition>\n      <ProductForm>EA</ProductForm>", "<ProductForm>EA</ProductForm>\n      <ProductComposition>00</ProductComposition>"}},
+		{name: "bad attribute", replace: [2]string{`dateformat="00"`, `dateformat="14"`}},
+		{name: "missing release", replace: [2]string{` release="3.0"`, ""}},
+		{name: "wrong namespace", replace: [2]string{"onix/3.0/reference", "onix/3.0/short"}},
+		{name: "bad price", replace: [2]string{"<PriceAmount>25.00</PriceAmount>", "<PriceAmount>$25</PriceAmount>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, valid, tt.replace[0])
+			invalid := strings.Replace(valid, tt.replace[0], tt.replace[1], 1)
+			assert.NotEmpty(t, schema.Validate([]byte(invalid)))
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package onix
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// xmlNode is a generic element, used to read both the schema and the
+// documents checked against it.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+func (n xmlNode) attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name && attr.Name.Space == "" {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (n xmlNode) child(name string) (xmlNode, bool) {
+	for _, child := range n.Nodes {
+		if child.XMLName.Local == name {
+			return child, true
+		}
+	}
+	return xmlNode{}, false
+}
+
+// subsetSchema checks documents against the small part of XML Schema the
+// checked-in ONIX subset uses: named and anonymous types, sequences with
+// occurrence bounds, simple content with attributes, and string
+// restrictions by pattern, enumeration and minimum length. It is a test
+// helper, not a general validator.
+type subsetSchema struct {
+	targetNamespace string
+	root            xmlNode
+	simpleTypes     map[string]xmlNode
+	complexTypes    map[string]xmlNode
+}
+
+func loadSubsetSchema(t *testing.T, path string) *subsetSchema {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc xmlNode
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	s := &subsetSchema{targetNamespace: doc.attr("targetNamespace"), simpleTypes: map[string]xmlNode{}, complexTypes: map[string]xmlNode{}}
+	for _, node := range doc.Nodes {
+		switch node.XMLName.Local {
+		case "simpleType":
+			s.simpleTypes[node.attr("name")] = node
+		case "complexType":
+			s.complexTypes[node.attr("name")] = node
+		case "element":
+			s.root = node
+		}
+	}
+	return s
+}
+
+// Validate returns every violation found in the document.
+func (s *subsetSchema) Validate(data []byte) []error {
+	var doc xmlNode
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return []error{err}
+	}
+	errs := []error{}
+	if doc.XMLName.Local != s.root.attr("name") {
+		return append(errs, fmt.Errorf("root is %s, want %s", doc.XMLName.Local, s.root.attr("name")))
+	}
+	s.element(s.root, doc, "/"+doc.XMLName.Local, &errs)
+	return errs
+}
+
+func (s *subsetSchema) element(decl xmlNode, node xmlNode, path string, errs *[]error) {
+	if node.XMLName.Space != s.targetNamespace {
+		*errs = append(*errs, fmt.Errorf("%s: namespace %q", path, node.XMLName.Space))
+	}
+	if complexType, ok := decl.child("complexType"); ok {
+		s.complexContent(complexType, node, path, errs)
+		return
+	}
+	typeName := decl.attr("type")
+	if complexType, ok := s.complexTypes[typeName]; ok {
+		s.complexContent(complexType, node, path, errs)
+		return
+	}
+	if len(node.Nodes) > 0 || len(node.Attrs) > 0 {
+		*errs = append(*errs, fmt.Errorf("%s: simple element has children or attributes", path))
+	}
+	if err := s.simpleValue(typeName, node.Content); err != nil {
+		*errs = append(*errs, fmt.Errorf("%s: %w", path, err))
+	}
+}
+
+func (s *subsetSchema) complexContent(complexType xmlNode, node xmlNode, path string, errs *[]error) {
+	attributes := complexType
+	simpleContent, isSimple := complexType.child("simpleContent")
+	if isSimple {
+		attributes, _ = simpleContent.child("extension")
+	}
+	s.attributes(attributes, node, path, errs)
+
+	if isSimple {
+		if len(node.Nodes) > 0 {
+			*errs = append(*errs, fmt.Errorf("%s: simple content has children", path))
+		}
+		if err := s.simpleValue(attributes.attr("base"), node.Content); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", path, err))
+		}
+		return
+	}
+	if strings.TrimSpace(node.Content) != "" {
+		*errs = append(*errs, fmt.Errorf("%s: unexpected text %q", path, strings.TrimSpace(node.Content)))
+	}
+
+	sequence, _ := complexType.child("sequence")
+	children := node.Nodes
+	for _, particle := range sequence.Nodes {
+		name := particle.attr("name")
+		minOccurs, maxOccurs := occurs(particle)
+		count := 0
+		for len(children) > 0 && children[0].XMLName.Local == name {
+			count++
+			s.element(particle, children[0], fmt.Sprintf("%s/%s[%d]", path, name, count), errs)
+			children = children[1:]
+		}
+		if count < minOccurs || (maxOccurs >= 0 && count > maxOccurs) {
+			*errs = append(*errs, fmt.Errorf("%s: %d %s elements, want %d to %s", path, count, name, minOccurs, particle.attr("maxOccurs")))
+		}
+	}
+	for _, child := range children {
+		*errs = append(*errs, fmt.Errorf("%s: unexpected element %s", path, child.XMLName.Local))
+	}
+}
+
+func (s *subsetSchema) attributes(decl xmlNode, node xmlNode, path string, errs *[]error) {
+	declared := map[string]xmlNode{}
+	for _, attribute := range decl.Nodes {
+		if attribute.XMLName.Local != "attribute" {
+			continue
+		}
+		declared[attribute.attr("name")] = attribute
+		if attribute.attr("use") == "required" && node.attr(attribute.attr("name")) == "" {
+			*errs = append(*errs, fmt.Errorf("%s: missing attribute %s", path, attribute.attr("name")))
+		}
+	}
+	for _, attr := range node.Attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		attribute, ok := declared[attr.Name.Local]
+		if !ok {
+			*errs = append(*errs, fmt.Errorf("%s: undeclared attribute %s", path, attr.Name.Local))
+			continue
+		}
+		if err := s.simpleValue(attribute.attr("type"), attr.Value); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s/@%s: %w", path, attr.Name.Local, err))
+		}
+	}
+}
+
+func (s *subsetSchema) simpleValue(typeName string, value string) error {
+	switch typeName {
+	case "xs:string":
+		return nil
+	case "xs:positiveInteger":
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return fmt.Errorf("%q is not a positive integer", value)
+		}
+		return nil
+	case "xs:decimal":
+		if !regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`).MatchString(value) {
+			return fmt.Errorf("%q is not a decimal", value)
+		}
+		return nil
+	case "xs:anyURI":
+		if _, err := url.Parse(value); err != nil {
+			return fmt.Errorf("%q is not a URI", value)
+		}
+		return nil
+	}
+
+	simpleType, ok := s.simpleTypes[typeName]
+	if !ok {
+		return fmt.Errorf("unknown type %s", typeName)
+	}
+	restriction, _ := simpleType.child("restriction")
+	if err := s.simpleValue(restriction.attr("base"), value); err != nil {
+		return err
+	}
+	patterns, enumeration := []string{}, []string{}
+	for _, facet := range restriction.Nodes {
+		switch facet.XMLName.Local {
+		case "pattern":
+			patterns = append(patterns, facet.attr("value"))
+		case "enumeration":
+			enumeration = append(enumeration, facet.attr("value"))
+		case "minLength":
+			if n, _ := strconv.Atoi(facet.attr("value")); len([]rune(value)) < n {
+				return fmt.Errorf("%q is shorter than %d for %s", value, n, typeName)
+			}
+		}
+	}
+	if len(patterns) > 0 && !matchesAny(patterns, value) {
+		return fmt.Errorf("%q does not match %s", value, typeName)
+	}
+	if len(enumeration) > 0 && !slices.Contains(enumeration, value) {
+		return fmt.Errorf("%q is not one of %s", value, typeName)
+	}
+	return nil
+}
+
+// occurs reads minOccurs and maxOccurs, with -1 for unbounded.
+func occurs(particle xmlNode) (int, int) {
+	minOccurs, maxOccurs := 1, 1
+	if value := particle.attr("minOccurs"); value != "" {
+		minOccurs, _ = strconv.Atoi(value)
+	}
+	switch value := particle.attr("maxOccurs"); value {
+	case "":
+	case "unbounded":
+		maxOccurs = -1
+	default:
+		maxOccurs, _ = strconv.Atoi(value)
+	}
+	return minOccurs, maxOccurs
+}
+
+// matchesAny applies XML Schema patterns, which are anchored at both ends.
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if regexp.MustCompile(`^(?:` + pattern + `)$`).MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package opds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	AtomNamespace       = "http://www.w3.org/2005/Atom"
+	DublinCoreNamespace = "http://purl.org/dc/terms/"
+	OPDSNamespace       = "http://opds-spec.org/2010/catalog"
+	OpenSearchNamespace = "http://a9.com/-/spec/opensearch/1.1/"
+	ThreadingNamespace  = "http://purl.org/syndication/thread/1.0"
+)
+
+// The Atom document uses the prefixes e-readers expect, dc:, opds:,
+// opensearch: and thr:, declared once on the feed element.
+type atomFeed struct {
+	XMLName         xml.Name    `xml:"feed"`
+	Xmlns           string      `xml:"xmlns,attr"`
+	XmlnsDC         string      `xml:"xmlns:dc,attr"`
+	XmlnsOPDS       string      `xml:"xmlns:opds,attr"`
+	XmlnsOpenSearch string      `xml:"xmlns:opensearch,attr"`
+	XmlnsThr        string      `xml:"xmlns:thr,attr"`
+	ID              string      `xml:"id"`
+	Title           string      `xml:"title"`
+	Updated         string      `xml:"updated"`
+	Author          atomPerson  `xml:"author"`
+	Links           []atomLink  `xml:"link"`
+	TotalResults    int         `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage    int         `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex      int         `xml:"opensearch:startIndex,omitempty"`
+	Entries         []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel      string        `xml:"rel,attr,omitempty"`
+	Href     string        `xml:"href,attr"`
+	Type     string        `xml:"type,attr,omitempty"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Count    int           `xml:"thr:count,attr,omitempty"`
+	Indirect *atomIndirect `xml:"opds:indirectAcquisition,omitempty"`
+}
+
+// atomIndirect is the format a buy link eventually delivers.
+type atomIndirect struct {
+	Type string `xml:"type,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomPerson   `xml:"author"`
+	Identifier string         `xml:"dc:identifier,omitempty"`
+	Publisher  string         `xml:"dc:publisher,omitempty"`
+	Language   string         `xml:"dc:language,omitempty"`
+	Issued     string         `xml:"dc:issued,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Links      []atomLink     `xml:"link"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteAtom writes the feed as an OPDS 1.2 catalog. Atom entries need an
+// updated time, and volumes have none, so they take the feed's.
+func (f Feed) WriteAtom(w io.Writer) error {
+	updated := f.Updated.UTC().Format(time.RFC3339)
+	feed := atomFeed{
+		Xmlns:           AtomNamespace,
+		XmlnsDC:         DublinCoreNamespace,
+		XmlnsOPDS:       OPDSNamespace,
+		XmlnsOpenSearch: OpenSearchNamespace,
+		XmlnsThr:        ThreadingNamespace,
+		ID:              f.ID,
+		Title:           f.Title,
+		Updated:         updated,
+		Author:          atomPerson{Name: f.Author},
+		TotalResults:    f.TotalResults,
+		ItemsPerPage:    f.ItemsPerPage,
+		StartIndex:      f.StartIndex,
+	}
+	for _, link := range f.Links {
+		feed.Links = append(feed.Links, atomLinks(link)...)
+	}
+	for _, link := range f.Navigation {
+		entry := atomEntry{Title: link.Title, ID: link.Href, Updated: updated, Links: atomLinks(link)}
+		if link.Count > 0 {
+			entry.Content = &atomText{Type: "text", Value: countText(link.Count)}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	for _, publication := range f.Publications {
+		feed.Entries = append(feed.Entries, publication.atomEntry(updated))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (p Publication) atomEntry(updated string) atomEntry {
+	entry := atomEntry{
+		Title:      p.Title,
+		ID:         p.ID,
+		Updated:    updated,
+		Identifier: p.Identifier,
+		Publisher:  p.Publisher,
+		Language:   p.Language,
+		Issued:     p.Published,
+	}
+	if p.Subtitle != "" {
+		entry.Title = p.Title + ": " + p.Subtitle
+	}
+	for _, author := range p.Authors {
+		entry.Authors = append(entry.Authors, atomPerson{Name: author})
+	}
+	for _, subject := range p.Subjects {
+		entry.Categories = append(entry.Categories, atomCategory{Term: subject, Label: subject})
+	}
+	if p.Description != "" {
+		entry.Content = &atomText{Type: "text", Value: p.Description}
+	}
+	for _, link := range p.Links {
+		entry.Links = append(entry.Links, atomLinks(link)...)
+	}
+	for _, link := range p.Images {
+		entry.Links = append(entry.Links, atomLinks(link)...)
+	}
+	return entry
+}
+
+// atomLinks writes a link. OPDS 1.2 gives each indirect acquisition its own
+// link, so a buy link delivering two formats becomes two links.
+func atomLinks(link Link) []atomLink {
+	atom := atomLink{Rel: link.Rel, Href: link.Href, Type: link.Type, Title: link.Title, Count: link.Count}
+	if len(link.IndirectTypes) == 0 {
+		return []atomLink{atom}
+	}
+	links := []atomLink{}
+	for _, mediaType := range link.IndirectTypes {
+		indirect := atom
+		indirect.Indirect = &atomIndirect{Type: mediaType}
+		links = append(links, indirect)
+	}
+	return links
+}
+
+func countText(count int) string {
+	if count == 1 {
+		return "1 book"
+	}
+	return fmt.Sprintf("%d books", count)
+}
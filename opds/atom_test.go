@@ -0,0 +1,119 @@
+package opds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// parsedFeed reads an Atom feed by namespace, so prefixes that are not
+// declared, or declared wrongly, leave fields empty.
+type parsedFeed struct {
+	XMLName      xml.Name      `xml:"http://www.w3.org/2005/Atom feed"`
+	ID           string        `xml:"http://www.w3.org/2005/Atom id"`
+	Updated      string        `xml:"http://www.w3.org/2005/Atom updated"`
+	Author       string        `xml:"http://www.w3.org/2005/Atom author>name"`
+	TotalResults int           `xml:"http://a9.com/-/spec/opensearch/1.1/ totalResults"`
+	StartIndex   int           `xml:"http://a9.com/-/spec/opensearch/1.1/ startIndex"`
+	Links        []parsedLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Entries      []parsedEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type parsedLink struct {
+	Rel      string `xml:"rel,attr"`
+	Href     string `xml:"href,attr"`
+	Type     string `xml:"type,attr"`
+	Count    int    `xml:"http://purl.org/syndication/thread/1.0 count,attr"`
+	Indirect struct {
+		Type string `xml:"type,attr"`
+	} `xml:"http://opds-spec.org/2010/catalog indirectAcquisition"`
+}
+
+type parsedEntry struct {
+	Title      string       `xml:"http://www.w3.org/2005/Atom title"`
+	ID         string       `xml:"http://www.w3.org/2005/Atom id"`
+	Updated    string       `xml:"http://www.w3.org/2005/Atom updated"`
+	Authors    []string     `xml:"http://www.w3.org/2005/Atom author>name"`
+	Identifier string       `xml:"http://purl.org/dc/terms/ identifier"`
+	Issued     string       `xml:"http://purl.org/dc/terms/ issued"`
+	Content    string       `xml:"http://www.w3.org/2005/Atom content"`
+	Links      []parsedLink `xml:"http://www.w3.org/2005/Atom link"`
+}
+
+func TestFeed_WriteAtom(t *testing.T) {
+	feed := Feed{
+		ID:           "http://localhost/opds/search?q=gibson",
+		Title:        "Search: gibson",
+		Author:       "book-learn",
+		Kind:         Acquisition,
+		Updated:      updated,
+		Links:        []Link{{Rel: "self", Href: "http://localhost/opds/search?q=gibson", Type: AcquisitionMediaType}},
+		Publications: append([]Publication{NewPublication(saleItem())}, publications(pactItems(t))...),
+		TotalResults: 34,
+		ItemsPerPage: 20,
+		StartIndex:   1,
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, feed.WriteAtom(&buf))
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var parsed parsedFeed
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	assert.Equal(t, "2026-03-01T09:30:00Z", parsed.Updated)
+	assert.Equal(t, "book-learn", parsed.Author)
+	assert.Equal(t, 34, parsed.TotalResults)
+	assert.Equal(t, 1, parsed.StartIndex)
+	assert.Len(t, parsed.Entries, len(feed.Publications))
+
+	entry := parsed.Entries[0]
+	assert.Equal(t, "Count Zero: Sprawl Trilogy 2", entry.Title)
+	assert.Equal(t, "https://books.google.com/books?id=VJvQDSqL3f8C", entry.ID)
+	assert.Equal(t, parsed.Updated, entry.Updated)
+	assert.Equal(t, []string{"William Gibson"}, entry.Authors)
+	assert.Equal(t, "urn:isbn:9780441117734", entry.Identifier)
+	assert.Equal(t, "1987-04-15", entry.Issued)
+	assert.Equal(t, "Turner wakes up & <runs>.", entry.Content)
+	assert.Equal(t, RelAcquisitionBuy, entry.Links[0].Rel)
+	assert.Equal(t, EpubMediaType, entry.Links[0].Indirect.Type)
+	assert.Equal(t, RelAcquisitionBuy, entry.Links[1].Rel)
+	assert.Equal(t, PDFMediaType, entry.Links[1].Indirect.Type)
+
+	// Every entry has an id, a title and an updated time, as Atom requires
+	for _, entry := range parsed.Entries {
+		assert.NotEmpty(t, entry.ID)
+		assert.NotEmpty(t, entry.Title)
+		assert.NotEmpty(t, entry.Updated)
+	}
+}
+
+func TestFeed_WriteAtom_navigation(t *testing.T) {
+	feed := Feed{
+		ID:         "http://localhost/opds",
+		Title:      "book-learn",
+		Kind:       Navigation,
+		Updated:    updated,
+		Navigation: []Link{{Rel: "subsection", Href: "http://localhost/opds/categories/fiction", Type: NavigationMediaType, Title: "Fiction", Count: 3}},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, feed.WriteAtom(&buf))
+
+	var parsed parsedFeed
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	assert.Len(t, parsed.Entries, 1)
+	assert.Equal(t, "Fiction", parsed.Entries[0].Title)
+	assert.Equal(t, "3 books", parsed.Entries[0].Content)
+	assert.Equal(t, 3, parsed.Entries[0].Links[0].Count)
+	assert.Equal(t, NavigationMediaType, parsed.Entries[0].Links[0].Type)
+}
+
+func publications(items []model.GoogleBookItem) []Publication {
+	out := []Publication{}
+	for _, item := range items {
+		out = append(out, NewPublication(item))
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+// Package opds describes catalogs of volumes as OPDS feeds, which e-reader
+// apps browse to find and download books. A Feed is written either as an
+// OPDS 1.2 Atom document or as OPDS 2.0 JSON.
+package opds
+
+import (
+	"net/url"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+)
+
+const (
+	AtomMediaType        = "application/atom+xml"
+	NavigationMediaType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	AcquisitionMediaType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	JSONMediaType        = "application/opds+json"
+	OpenSearchMediaType  = "application/opensearchdescription+xml"
+
+	EpubMediaType = "application/epub+zip"
+	PDFMediaType  = "application/pdf"
+	HTMLMediaType = "text/html"
+)
+
+// Link relations defined by OPDS, on top of the Atom ones such as self,
+// start, up, next, previous, first, last, search and subsection.
+const (
+	RelAcquisition           = "http://opds-spec.org/acquisition"
+	RelAcquisitionOpenAccess = "http://opds-spec.org/acquisition/open-access"
+	RelAcquisitionBuy        = "http://opds-spec.org/acquisition/buy"
+	RelAcquisitionSample     = "http://opds-spec.org/acquisition/sample"
+	RelImage                 = "http://opds-spec.org/image"
+	RelThumbnail             = "http://opds-spec.org/image/thumbnail"
+)
+
+// Kind tells navigation feeds, which list other feeds, from acquisition
+// feeds, which list publications.
+type Kind int
+
+const (
+	Navigation Kind = iota
+	Acquisition
+)
+
+// Feed is a page of a catalog. Navigation feeds fill Navigation and
+// acquisition feeds fill Publications.
+type Feed struct {
+	ID           string
+	Title        string
+	Author       string
+	Kind         Kind
+	Updated      time.Time
+	Links        []Link
+	Navigation   []Link
+	Publications []Publication
+	// Pagination, for feeds that are a page of search results. StartIndex
+	// counts from 1, as in OpenSearch.
+	TotalResults int
+	ItemsPerPage int
+	StartIndex   int
+}
+
+// Link is a typed link. Count is the number of items behind a navigation
+// link, and IndirectTypes the formats a buy link eventually delivers.
+type Link struct {
+	Rel           string
+	Href          string
+	Type          string
+	Title         string
+	Templated     bool
+	Count         int
+	IndirectTypes []string
+}
+
+// Publication is a volume in an acquisition feed.
+type Publication struct {
+	ID          string
+	Identifier  string
+	Title       string
+	Subtitle    string
+	Authors     []string
+	Publisher   string
+	Language    string
+	Published   string
+	Description string
+	Subjects    []string
+	Series      *model.Series
+	Links       []Link
+	Images      []Link
+}
+
+// EntryID is the stable identifier of a volume in feeds: its Google Books
+// page, built from the volume ID alone.
+func EntryID(volumeID string) string {
+	return "https://books.google.com/books?id=" + url.QueryEscape(volumeID)
+}
+
+// NewPublication maps a volume to a publication. Acquisition links follow
+// the access Google grants: public domain and free books are open access,
+// books on sale link to the store, and the web reader is offered as a
+// sample otherwise.
+func NewPublication(item model.GoogleBookItem) Publication {
+	info := item.VolumeInfo
+	publication := Publication{
+		ID:          EntryID(item.ID),
+		Identifier:  identifier(info.IndustryIdentifiers),
+		Title:       info.Title,
+		Subtitle:    info.Subtitle,
+		Authors:     info.Authors,
+		Publisher:   info.Publisher,
+		Language:    info.Language,
+		Description: info.Description,
+		Subjects:    info.Categories,
+		Series:      client.DetectSeries(item),
+		Links:       acquisitionLinks(item),
+	}
+	if date := model.ParsePublicationDate(info.PublishedDate); date.Precision >= model.DatePrecisionYear {
+		publication.Published = date.String()
+	}
+	if info.ImageLinks.Thumbnail != "" {
+		publication.Images = append(publication.Images, Link{Rel: RelImage, Href: info.ImageLinks.Thumbnail, Type: "image/jpeg"})
+	}
+	if thumbnail := coalesce(info.ImageLinks.SmallThumbnail, info.ImageLinks.Thumbnail); thumbnail != "" {
+		publication.Images = append(publication.Images, Link{Rel: RelThumbnail, Href: thumbnail, Type: "image/jpeg"})
+	}
+	return publication
+}
+
+// identifier prefers the ISBN-13 and writes it as a URN.
+func identifier(ids []model.GoogleBookIndustryIdentifier) string {
+	for _, kind := range []string{"ISBN_13", "ISBN_10"} {
+		for _, id := range ids {
+			if id.Type == kind {
+				return "urn:isbn:" + id.Identifier
+			}
+		}
+	}
+	return ""
+}
+
+func acquisitionLinks(item model.GoogleBookItem) []Link {
+	access, sale := item.AccessInfo, item.SaleInfo
+	free := access.PublicDomain || sale.Saleability == "FREE"
+	onSale := sale.BuyLink != "" && (sale.Saleability == "FOR_SALE" || sale.Saleability == "FOR_SALE_AND_RENTAL")
+
+	rel := RelAcquisition
+	if free {
+		rel = RelAcquisitionOpenAccess
+	}
+	links, indirect := []Link{}, []string{}
+	formats := []struct {
+		mediaType string
+		available bool
+		href      string
+	}{
+		{EpubMediaType, access.Epub.IsAvailable, coalesce(access.Epub.DownloadLink, access.Epub.AcsTokenLink)},
+		{PDFMediaType, access.Pdf.IsAvailable, coalesce(access.Pdf.DownloadLink, access.Pdf.AcsTokenLink)},
+	}
+	for _, format := range formats {
+		switch {
+		case format.href != "":
+			links = append(links, Link{Rel: rel, Href: format.href, Type: format.mediaType})
+		case format.available && onSale:
+			indirect = append(indirect, format.mediaType)
+		}
+	}
+	if onSale {
+		links = append(links, Link{Rel: RelAcquisitionBuy, Href: sale.BuyLink, Type: HTMLMediaType, IndirectTypes: indirect})
+	}
+	if access.WebReaderLink != "" && access.Viewability != "NO_PAGES" {
+		readerRel := RelAcquisitionSample
+		if free {
+			readerRel = RelAcquisitionOpenAccess
+		}
+		links = append(links, Link{Rel: readerRel, Href: access.WebReaderLink, Type: HTMLMediaType, Title: "Read online"})
+	}
+	return links
+}
+
+func coalesce(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
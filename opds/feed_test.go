@@ -0,0 +1,105 @@
+package opds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var updated = time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func pactItems(t *testing.T) []model.GoogleBookItem {
+	bc := client.GoogleBookClient{PactMode: true, Catalog: client.NewCatalog()}
+	authors, err := bc.ByAuthor(context.Background(), client.GoogleBookRequest{Author: "William Gibson"})
+	assert.NoError(t, err)
+	return authors.Items
+}
+
+func saleItem() model.GoogleBookItem {
+	return model.GoogleBookItem{
+		ID: "VJvQDSqL3f8C",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:               "Count Zero",
+			Subtitle:            "Sprawl Trilogy 2",
+			Authors:             []string{"William Gibson"},
+			Publisher:           "Ace",
+			PublishedDate:       "1987-04-15",
+			Description:         "Turner wakes up & <runs>.",
+			IndustryIdentifiers: []model.GoogleBookIndustryIdentifier{{Type: "ISBN_10", Identifier: "0441117732"}, {Type: "ISBN_13", Identifier: "9780441117734"}},
+			Categories:          []string{"Fiction"},
+			Language:            "en",
+			ImageLinks:          model.GoogleBookImageLinks{SmallThumbnail: "http://books.example/small", Thumbnail: "http://books.example/cover"},
+		},
+		SaleInfo: model.GoogleBookSaleInfo{Saleability: "FOR_SALE", BuyLink: "https://play.example/store?id=1"},
+		AccessInfo: model.GoogleBookAccessInfo{
+			Viewability:   "PARTIAL",
+			Epub:          model.GoogleBookEpubInfo{IsAvailable: true},
+			Pdf:           model.GoogleBookPdfInfo{IsAvailable: true},
+			WebReaderLink: "http://play.example/reader?id=1",
+		},
+	}
+}
+
+func TestNewPublication(t *testing.T) {
+	publication := NewPublication(saleItem())
+
+	assert.Equal(t, "https://books.google.com/books?id=VJvQDSqL3f8C", publication.ID)
+	assert.Equal(t, "urn:isbn:9780441117734", publication.Identifier)
+	assert.Equal(t, "1987-04-15", publication.Published)
+	assert.Equal(t, []Link{
+		{Rel: RelAcquisitionBuy, Href: "https://play.example/store?id=1", Type: HTMLMediaType, IndirectTypes: []string{EpubMediaType, PDFMediaType}},
+		{Rel: RelAcquisitionSample, Href: "http://play.example/reader?id=1", Type: HTMLMediaType, Title: "Read online"},
+	}, publication.Links)
+	assert.Equal(t, []Link{
+		{Rel: RelImage, Href: "http://books.example/cover", Type: "image/jpeg"},
+		{Rel: RelThumbnail, Href: "http://books.example/small", Type: "image/jpeg"},
+	}, publication.Images)
+}
+
+func TestAcquisitionLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		access   model.GoogleBookAccessInfo
+		sale     model.GoogleBookSaleInfo
+		expected []Link
+	}{
+		{
+			name: "public domain download",
+			access: model.GoogleBookAccessInfo{
+				PublicDomain:  true,
+				Viewability:   "ALL_PAGES",
+				Epub:          model.GoogleBookEpubInfo{DownloadLink: "http://books.example/book.epub"},
+				Pdf:           model.GoogleBookPdfInfo{IsAvailable: true, DownloadLink: "http://books.example/book.pdf"},
+				WebReaderLink: "http://play.example/reader",
+			},
+			sale: model.GoogleBookSaleInfo{Saleability: "FREE", BuyLink: "http://play.example/store"},
+			expected: []Link{
+				{Rel: RelAcquisitionOpenAccess, Href: "http://books.example/book.epub", Type: EpubMediaType},
+				{Rel: RelAcquisitionOpenAccess, Href: "http://books.example/book.pdf", Type: PDFMediaType},
+				{Rel: RelAcquisitionOpenAccess, Href: "http://play.example/reader", Type: HTMLMediaType, Title: "Read online"},
+			},
+		},
+		{
+			name:   "protected pdf",
+			access: model.GoogleBookAccessInfo{Viewability: "NO_PAGES", Pdf: model.GoogleBookPdfInfo{IsAvailable: true, AcsTokenLink: "http://books.example/acs"}, WebReaderLink: "http://play.example/reader"},
+			expected: []Link{
+				{Rel: RelAcquisition, Href: "http://books.example/acs", Type: PDFMediaType},
+			},
+		},
+		{
+			name:     "nothing offered",
+			access:   model.GoogleBookAccessInfo{Viewability: "NO_PAGES", Epub: model.GoogleBookEpubInfo{IsAvailable: true}},
+			sale:     model.GoogleBookSaleInfo{Saleability: "NOT_FOR_SALE"},
+			expected: []Link{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, acquisitionLinks(model.GoogleBookItem{AccessInfo: tt.access, SaleInfo: tt.sale}))
+		})
+	}
+}
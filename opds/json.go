@@ -0,0 +1,148 @@
+package opds
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type jsonFeed struct {
+	Metadata   jsonFeedMetadata `json:"metadata"`
+	Links      []jsonLink       `json:"links"`
+	Navigation []jsonLink       `json:"navigation,omitempty"`
+	// Publications is a pointer so an acquisition feed with no results
+	// still has the array, which navigation feeds leave out.
+	Publications *[]jsonPublication `json:"publications,omitempty"`
+}
+
+type jsonFeedMetadata struct {
+	Title         string `json:"title"`
+	Modified      string `json:"modified"`
+	NumberOfItems int    `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type jsonLink struct {
+	Href       string          `json:"href"`
+	Type       string          `json:"type,omitempty"`
+	Rel        string          `json:"rel,omitempty"`
+	Title      string          `json:"title,omitempty"`
+	Templated  bool            `json:"templated,omitempty"`
+	Properties *jsonProperties `json:"properties,omitempty"`
+}
+
+type jsonProperties struct {
+	NumberOfItems       int            `json:"numberOfItems,omitempty"`
+	IndirectAcquisition []jsonIndirect `json:"indirectAcquisition,omitempty"`
+}
+
+type jsonIndirect struct {
+	Type string `json:"type"`
+}
+
+type jsonPublication struct {
+	Metadata jsonMetadata `json:"metadata"`
+	Links    []jsonLink   `json:"links"`
+	Images   []jsonLink   `json:"images,omitempty"`
+}
+
+type jsonMetadata struct {
+	Type        string         `json:"@type"`
+	Identifier  string         `json:"identifier"`
+	Title       string         `json:"title"`
+	Subtitle    string         `json:"subtitle,omitempty"`
+	Authors     []jsonName     `json:"author,omitempty"`
+	Publisher   string         `json:"publisher,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Published   string         `json:"published,omitempty"`
+	Modified    string         `json:"modified"`
+	Description string         `json:"description,omitempty"`
+	Subjects    []jsonName     `json:"subject,omitempty"`
+	BelongsTo   *jsonBelongsTo `json:"belongsTo,omitempty"`
+}
+
+type jsonName struct {
+	Name     string `json:"name"`
+	Position int    `json:"position,omitempty"`
+}
+
+type jsonBelongsTo struct {
+	Series []jsonName `json:"series"`
+}
+
+// WriteJSON writes the feed as an OPDS 2.0 catalog.
+func (f Feed) WriteJSON(w io.Writer) error {
+	modified := f.Updated.UTC().Format(time.RFC3339)
+	feed := jsonFeed{
+		Metadata: jsonFeedMetadata{
+			Title:         f.Title,
+			Modified:      modified,
+			NumberOfItems: f.TotalResults,
+			ItemsPerPage:  f.ItemsPerPage,
+		},
+		Links: jsonLinks(f.Links),
+	}
+	if f.ItemsPerPage > 0 && f.StartIndex > 0 {
+		feed.Metadata.CurrentPage = (f.StartIndex-1)/f.ItemsPerPage + 1
+	}
+	feed.Navigation = jsonLinks(f.Navigation)
+	if f.Kind == Acquisition {
+		publications := []jsonPublication{}
+		for _, publication := range f.Publications {
+			publications = append(publications, publication.jsonPublication(modified))
+		}
+		feed.Publications = &publications
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(feed)
+}
+
+func (p Publication) jsonPublication(modified string) jsonPublication {
+	metadata := jsonMetadata{
+		Type:        "http://schema.org/Book",
+		Identifier:  coalesce(p.Identifier, p.ID),
+		Title:       p.Title,
+		Subtitle:    p.Subtitle,
+		Publisher:   p.Publisher,
+		Language:    p.Language,
+		Published:   p.Published,
+		Modified:    modified,
+		Description: p.Description,
+	}
+	for _, author := range p.Authors {
+		metadata.Authors = append(metadata.Authors, jsonName{Name: author})
+	}
+	for _, subject := range p.Subjects {
+		metadata.Subjects = append(metadata.Subjects, jsonName{Name: subject})
+	}
+	if p.Series != nil && p.Series.Title != "" {
+		metadata.BelongsTo = &jsonBelongsTo{Series: []jsonName{{Name: p.Series.Title, Position: p.Series.Position}}}
+	}
+	links := jsonLinks(p.Links)
+	if links == nil {
+		links = []jsonLink{}
+	}
+	return jsonPublication{Metadata: metadata, Links: links, Images: jsonLinks(p.Images)}
+}
+
+func jsonLinks(links []Link) []jsonLink {
+	var out []jsonLink
+	for _, link := range links {
+		out = append(out, jsonLinkOf(link))
+	}
+	return out
+}
+
+func jsonLinkOf(link Link) jsonLink {
+	out := jsonLink{Href: link.Href, Type: link.Type, Rel: link.Rel, Title: link.Title, Templated: link.Templated}
+	if link.Count > 0 || len(link.IndirectTypes) > 0 {
+		out.Properties = &jsonProperties{NumberOfItems: link.Count}
+		for _, mediaType := range link.IndirectTypes {
+			out.Properties.IndirectAcquisition = append(out.Properties.IndirectAcquisition, jsonIndirect{Type: mediaType})
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package opds
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeed_WriteJSON(t *testing.T) {
+	item := saleItem()
+	item.VolumeInfo.Title = "Count Zero (Sprawl #2)"
+	feed := Feed{
+		Title:        "Search: gibson",
+		Kind:         Acquisition,
+		Updated:      updated,
+		Links:        []Link{{Rel: "search", Href: "http://localhost/opds/search?format=opds2{&q}", Type: JSONMediaType, Templated: true}},
+		Publications: []Publication{NewPublication(item)},
+		TotalResults: 34,
+		ItemsPerPage: 20,
+		StartIndex:   21,
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, feed.WriteJSON(&buf))
+
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, map[string]any{"title": "Search: gibson", "modified": "2026-03-01T09:30:00Z", "numberOfItems": 34.0, "itemsPerPage": 20.0, "currentPage": 2.0}, doc["metadata"])
+	assert.Equal(t, []any{map[string]any{"href": "http://localhost/opds/search?format=opds2{&q}", "type": JSONMediaType, "rel": "search", "templated": true}}, doc["links"])
+
+	publication := doc["publications"].([]any)[0].(map[string]any)
+	metadata := publication["metadata"].(map[string]any)
+	assert.Equal(t, "http://schema.org/Book", metadata["@type"])
+	assert.Equal(t, "urn:isbn:9780441117734", metadata["identifier"])
+	assert.Equal(t, []any{map[string]any{"name": "William Gibson"}}, metadata["author"])
+	assert.Equal(t, map[string]any{"series": []any{map[string]any{"name": "Sprawl", "position": 2.0}}}, metadata["belongsTo"])
+	assert.Equal(t, map[string]any{
+		"href":       "https://play.example/store?id=1",
+		"type":       HTMLMediaType,
+		"rel":        RelAcquisitionBuy,
+		"properties": map[string]any{"indirectAcquisition": []any{map[string]any{"type": EpubMediaType}, map[string]any{"type": PDFMediaType}}},
+	}, publication["links"].([]any)[0])
+	assert.Len(t, publication["images"], 2)
+}
+
+func TestFeed_WriteJSON_empty(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Feed{Title: "Search: nothing", Kind: Acquisition, Updated: updated}.WriteJSON(&buf))
+
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, []any{}, doc["publications"])
+	assert.NotContains(t, doc, "navigation")
+
+	// A publication with no way to get it still has a links array
+	buf.Reset()
+	feed := Feed{Kind: Acquisition, Updated: updated, Publications: []Publication{NewPublication(model.GoogleBookItem{ID: "id"})}}
+	assert.NoError(t, feed.WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"links": []`)
+}
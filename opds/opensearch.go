@@ -0,0 +1,42 @@
+package opds
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// OpenSearchDescription tells clients how to search the catalog. Templates
+// take {searchTerms} and, optionally, {startIndex?}.
+type OpenSearchDescription struct {
+	XMLName        xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName      string          `xml:"ShortName"`
+	Description    string          `xml:"Description"`
+	InputEncoding  string          `xml:"InputEncoding"`
+	OutputEncoding string          `xml:"OutputEncoding"`
+	URLs           []OpenSearchURL `xml:"Url"`
+}
+
+type OpenSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// Write writes the description document.
+func (d OpenSearchDescription) Write(w io.Writer) error {
+	if d.InputEncoding == "" {
+		d.InputEncoding = "UTF-8"
+	}
+	if d.OutputEncoding == "" {
+		d.OutputEncoding = "UTF-8"
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,240 @@
+// Package recommend suggests books similar to a given volume using only the
+// volumes the service has already seen, so it works offline.
+package recommend
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+)
+
+const (
+	SignalDescription = "description"
+	SignalCategory    = "category"
+	SignalAuthor      = "author"
+
+	explainedTerms = 5
+
+	// indexRefreshInterval is the least time between rebuilds of the work
+	// index, which would otherwise happen whenever a search sees a new volume.
+	indexRefreshInterval = time.Minute
+)
+
+// Weights controls how much each signal contributes to a recommendation.
+type Weights struct {
+	Description float64
+	Category    float64
+	Author      float64
+}
+
+var DefaultWeights = Weights{Description: 0.5, Category: 0.3, Author: 0.2}
+
+// Recommender finds similar books among the works in a catalog. Author
+// proximity comes from the author graph when one is given.
+type Recommender struct {
+	Catalog *client.Catalog
+	Graph   *client.AuthorGraph
+	Weights Weights
+
+	now        func() time.Time
+	mu         sync.Mutex
+	index      *workIndex
+	rebuilding bool
+}
+
+// workIndex is the catalog grouped into works with their TF-IDF model. It
+// is rebuilt at most every indexRefreshInterval once the catalog changes,
+// rather than for every recommendation.
+type workIndex struct {
+	version int
+	built   time.Time
+	works   []model.GoogleBookItem
+	text    tfidf
+}
+
+func newWorkIndex(items []model.GoogleBookItem) *workIndex {
+	works := client.GroupEditions(items)
+	documents := map[string][]string{}
+	for _, work := range works {
+		documents[work.ID] = terms(documentText(work))
+	}
+	return &workIndex{works: works, text: newTFIDF(documents)}
+}
+
+// catalogIndex returns the index of the catalog, rebuilding it when the
+// catalog has changed and the index is due a refresh. One caller rebuilds it
+// without holding the lock while the others keep using the current index.
+func (r *Recommender) catalogIndex() *workIndex {
+	version := r.Catalog.Version()
+	r.mu.Lock()
+	if r.index == nil {
+		defer r.mu.Unlock()
+		r.index = r.buildIndex(version)
+		return r.index
+	}
+	index := r.index
+	if index.version == version || r.rebuilding || r.now().Sub(index.built) < indexRefreshInterval {
+		r.mu.Unlock()
+		return index
+	}
+	r.rebuilding = true
+	r.mu.Unlock()
+
+	index = r.buildIndex(version)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.index, r.rebuilding = index, false
+	return index
+}
+
+func (r *Recommender) buildIndex(version int) *workIndex {
+	index := newWorkIndex(r.Catalog.All())
+	index.version, index.built = version, r.now()
+	return index
+}
+
+func New(catalog *client.Catalog, graph *client.AuthorGraph) *Recommender {
+	return &Recommender{Catalog: catalog, Graph: graph, Weights: DefaultWeights, now: time.Now}
+}
+
+// Recommendation is a similar work and why it was recommended.
+type Recommendation struct {
+	Book    model.GoogleBookItem
+	Score   float64
+	Reasons []Reason
+}
+
+// Reason explains one signal's contribution to a recommendation.
+type Reason struct {
+	Signal string  `json:"signal"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail"`
+}
+
+// Similar returns up to limit works most similar to seed, best first. Works
+// scoring zero and other editions of the seed itself are never returned.
+func (r *Recommender) Similar(seed model.GoogleBookItem, limit int) []Recommendation {
+	index := r.catalogIndex()
+	seedIndex := slices.IndexFunc(index.works, func(work model.GoogleBookItem) bool {
+		return work.ID == seed.ID || slices.ContainsFunc(work.Editions, func(edition model.GoogleBookItem) bool {
+			return edition.ID == seed.ID
+		})
+	})
+	// A seed the index has not seen is weighted against the existing model
+	seedWork := seed
+	var seedVector map[string]float64
+	if seedIndex >= 0 {
+		seedWork = index.works[seedIndex]
+		seedVector = index.text.vectors[seedWork.ID]
+	} else {
+		seedVector = index.text.vector(terms(documentText(seed)))
+	}
+
+	recommendations := []Recommendation{}
+	for i, work := range index.works {
+		if i == seedIndex || (seedIndex < 0 && len(client.GroupEditions([]model.GoogleBookItem{seed, work})) == 1) {
+			continue
+		}
+		recommendation := Recommendation{Book: work}
+		add := func(signal string, value float64, weight float64, detail string) {
+			if value <= 0 {
+				return
+			}
+			reason := Reason{Signal: signal, Score: value * weight, Detail: detail}
+			recommendation.Reasons = append(recommendation.Reasons, reason)
+			recommendation.Score += reason.Score
+		}
+
+		similarity, shared := cosine(seedVector, index.text.vectors[work.ID], explainedTerms)
+		add(SignalDescription, similarity, r.Weights.Description, fmt.Sprintf("shares the terms %s", strings.Join(shared, ", ")))
+
+		overlap, categories := categoryOverlap(seedWork, work)
+		add(SignalCategory, overlap, r.Weights.Category, fmt.Sprintf("also filed under %s", strings.Join(categories, ", ")))
+
+		proximity, detail := r.authorProximity(seedWork, work)
+		add(SignalAuthor, proximity, r.Weights.Author, detail)
+
+		if recommendation.Score > 0 {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+
+	slices.SortStableFunc(recommendations, func(a, b Recommendation) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return recommendations[:min(limit, len(recommendations))]
+}
+
+func documentText(work model.GoogleBookItem) string {
+	parts := []string{work.VolumeInfo.Title, work.VolumeInfo.Subtitle, work.VolumeInfo.Description}
+	for _, edition := range work.Editions {
+		if len(edition.VolumeInfo.Description) > len(parts[2]) {
+			parts[2] = edition.VolumeInfo.Description
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// categoryOverlap is the Jaccard overlap of the two works' normalized
+// categories, counting parent categories, with the names they share.
+func categoryOverlap(a model.GoogleBookItem, b model.GoogleBookItem) (float64, []string) {
+	slugsA, slugsB := categorySlugs(a), categorySlugs(b)
+	shared := []string{}
+	for _, slug := range slugsA {
+		if slices.Contains(slugsB, slug) {
+			category, _ := client.LookupCategory(slug)
+			shared = append(shared, category.Name)
+		}
+	}
+	union := len(slugsA) + len(slugsB) - len(shared)
+	if union == 0 {
+		return 0, shared
+	}
+	return float64(len(shared)) / float64(union), shared
+}
+
+func categorySlugs(work model.GoogleBookItem) []string {
+	raw := slices.Clone(work.VolumeInfo.Categories)
+	for _, edition := range work.Editions {
+		raw = append(raw, edition.VolumeInfo.Categories...)
+	}
+	slugs := []string{}
+	for _, category := range client.NormalizeCategories(raw) {
+		for _, slug := range category.Path {
+			if !slices.Contains(slugs, slug) {
+				slugs = append(slugs, slug)
+			}
+		}
+	}
+	return slugs
+}
+
+// authorProximity is 1 for a shared author, otherwise the strongest link
+// between the two works' authors in the author graph, capped at 1.
+func (r *Recommender) authorProximity(a model.GoogleBookItem, b model.GoogleBookItem) (float64, string) {
+	best, detail := 0.0, ""
+	for _, author := range a.VolumeInfo.Authors {
+		for _, other := range b.VolumeInfo.Authors {
+			if strings.EqualFold(author, other) {
+				return 1, fmt.Sprintf("also by %s", other)
+			}
+			if r.Graph == nil {
+				continue
+			}
+			if relation := r.Graph.Relation(author, other); relation.Weight > best {
+				best = min(relation.Weight, 1)
+				detail = fmt.Sprintf("%s is related to %s", other, author)
+				if relation.CoAuthoredBooks > 0 {
+					detail = fmt.Sprintf("%s has co-written with %s", other, author)
+				}
+			}
+		}
+	}
+	return best, detail
+}
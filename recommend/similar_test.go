@@ -0,0 +1,118 @@
+package recommend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// pactRecommender loads the pact corpus into a catalog the same way the
+// service does when running in pact mode.
+func pactRecommender(t *testing.T) (*Recommender, client.GoogleBookClient) {
+	catalog := client.NewCatalog()
+	graph := client.NewAuthorGraph()
+	catalog.OnAdd(graph.AddVolumes)
+	bc := client.GoogleBookClient{PactMode: true, Catalog: catalog}
+	_, err := bc.ByAuthor(context.Background(), client.GoogleBookRequest{Author: "William Gibson"})
+	assert.NoError(t, err)
+	_, err = bc.ByTitle(context.Background(), client.GoogleBookRequest{Title: "Count Zero"})
+	assert.NoError(t, err)
+	return New(catalog, graph), bc
+}
+
+func TestRecommender_Similar(t *testing.T) {
+	recommender, bc := pactRecommender(t)
+	seed, err := bc.ByID(context.Background(), "hNgmLwEACAAJ") // Boyology - Or Boy Analysis
+	assert.NoError(t, err)
+
+	got := recommender.Similar(seed, 3)
+
+	assert.Len(t, got, 3)
+	assert.Equal(t, "8j5RvgAACAAJ", got[0].Book.ID) // BOYOLOGY OR BOY ANALYSIS
+	signals := []string{}
+	for _, reason := range got[0].Reasons {
+		signals = append(signals, reason.Signal)
+		assert.NotEmpty(t, reason.Detail)
+	}
+	assert.Equal(t, []string{SignalDescription, SignalCategory, SignalAuthor}, signals)
+	for i := 1; i < len(got); i++ {
+		assert.GreaterOrEqual(t, got[i-1].Score, got[i].Score)
+		assert.NotEqual(t, seed.ID, got[i].Book.ID)
+	}
+}
+
+func TestRecommender_Similar_excludesOtherEditions(t *testing.T) {
+	recommender, bc := pactRecommender(t)
+	seed, err := bc.ByID(context.Background(), "IYUQLS0SnpEC") // Letters to My Son
+
+	assert.NoError(t, err)
+	for _, recommendation := range recommender.Similar(seed, 50) {
+		assert.NotEqual(t, "Letters to My Son", recommendation.Book.VolumeInfo.Title)
+	}
+}
+
+func TestRecommender_Similar_authorProximity(t *testing.T) {
+	catalog := client.NewCatalog()
+	graph := client.NewAuthorGraph()
+	catalog.OnAdd(graph.AddVolumes)
+	catalog.Add(
+		model.GoogleBookItem{ID: "engine", VolumeInfo: model.GoogleBookVolumeInfo{Title: "The Difference Engine", Authors: []string{"William Gibson", "Bruce Sterling"}}},
+		model.GoogleBookItem{ID: "schismatrix", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Schismatrix", Authors: []string{"Bruce Sterling"}}},
+		model.GoogleBookItem{ID: "emma", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Emma", Authors: []string{"Jane Austen"}}},
+	)
+	seed := model.GoogleBookItem{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}}}
+
+	got := New(catalog, graph).Similar(seed, 10)
+
+	assert.Len(t, got, 2)
+	assert.Equal(t, "engine", got[0].Book.ID)
+	assert.Equal(t, Reason{Signal: SignalAuthor, Score: 0.2, Detail: "also by William Gibson"}, got[0].Reasons[0])
+	assert.Equal(t, "schismatrix", got[1].Book.ID)
+	assert.Equal(t, "Bruce Sterling has co-written with William Gibson", got[1].Reasons[0].Detail)
+}
+
+func TestRecommender_Similar_reusesIndex(t *testing.T) {
+	catalog := client.NewCatalog()
+	catalog.Add(
+		model.GoogleBookItem{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}}},
+		model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}}},
+	)
+	recommender := New(catalog, nil)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recommender.now = func() time.Time { return now }
+	seed, _ := catalog.Get("neuromancer")
+
+	assert.Len(t, recommender.Similar(seed, 10), 1)
+	index := recommender.index
+	assert.Len(t, recommender.Similar(seed, 10), 1)
+	assert.Same(t, index, recommender.index)
+
+	// New volumes wait for the next refresh
+	catalog.Add(model.GoogleBookItem{ID: "agency", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Agency", Authors: []string{"William Gibson"}}})
+	assert.Len(t, recommender.Similar(seed, 10), 1)
+	assert.Same(t, index, recommender.index)
+
+	// A seed outside the index is scored against it without a rebuild
+	unseen := model.GoogleBookItem{ID: "idoru", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Idoru", Authors: []string{"William Gibson"}}}
+	assert.Len(t, recommender.Similar(unseen, 10), 2)
+	assert.Same(t, index, recommender.index)
+
+	now = now.Add(indexRefreshInterval)
+	assert.Len(t, recommender.Similar(seed, 10), 2)
+	assert.NotSame(t, index, recommender.index)
+}
+
+func TestDocumentText(t *testing.T) {
+	work := model.GoogleBookItem{
+		VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Description: "Short."},
+		Editions: []model.GoogleBookItem{
+			{VolumeInfo: model.GoogleBookVolumeInfo{Description: "The longest description."}},
+			{VolumeInfo: model.GoogleBookVolumeInfo{Description: "A longer one."}},
+		},
+	}
+	assert.Equal(t, "Neuromancer  The longest description.", documentText(work))
+}
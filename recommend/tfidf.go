@@ -0,0 +1,117 @@
+package recommend
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var stopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "an": true, "and": true,
+	"any": true, "are": true, "as": true, "at": true, "be": true, "been": true, "book": true,
+	"but": true, "by": true, "can": true, "for": true, "from": true, "had": true, "has": true,
+	"have": true, "he": true, "her": true, "his": true, "how": true, "in": true, "into": true,
+	"is": true, "it": true, "its": true, "more": true, "most": true, "new": true, "not": true,
+	"of": true, "on": true, "one": true, "or": true, "our": true, "she": true, "so": true,
+	"than": true, "that": true, "the": true, "their": true, "them": true, "there": true,
+	"these": true, "they": true, "this": true, "to": true, "was": true, "we": true, "were": true,
+	"what": true, "when": true, "which": true, "who": true, "will": true, "with": true,
+	"work": true, "would": true, "you": true, "your": true,
+}
+
+// terms splits text into lowercase words, dropping stop words and anything
+// shorter than three letters.
+func terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := []string{}
+	for _, word := range words {
+		if len([]rune(word)) >= 3 && !stopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+// tfidf holds a TF-IDF weighted, unit-length term vector for each document
+// along with the document frequencies, so documents outside the set can be
+// weighted the same way.
+type tfidf struct {
+	vectors   map[string]map[string]float64
+	frequency map[string]int
+	total     float64
+}
+
+// newTFIDF weights the documents' terms by term frequency and smoothed
+// inverse document frequency across the whole set of documents.
+func newTFIDF(documents map[string][]string) tfidf {
+	frequency := map[string]int{}
+	for _, words := range documents {
+		seen := map[string]bool{}
+		for _, word := range words {
+			if !seen[word] {
+				seen[word] = true
+				frequency[word]++
+			}
+		}
+	}
+
+	model := tfidf{vectors: map[string]map[string]float64{}, frequency: frequency, total: float64(len(documents))}
+	for id, words := range documents {
+		model.vectors[id] = model.vector(words)
+	}
+	return model
+}
+
+// vector weights the words of a document against the model's documents.
+func (m tfidf) vector(words []string) map[string]float64 {
+	vector := map[string]float64{}
+	for _, word := range words {
+		vector[word]++
+	}
+	norm := 0.0
+	for word, count := range vector {
+		weight := (count / float64(len(words))) * (math.Log((1+m.total)/(1+float64(m.frequency[word]))) + 1)
+		vector[word] = weight
+		norm += weight * weight
+	}
+	for word := range vector {
+		vector[word] /= math.Sqrt(norm)
+	}
+	return vector
+}
+
+// similarity is the cosine similarity of two documents along with the shared
+// terms that contributed most to it.
+func (m tfidf) similarity(a string, b string, topTerms int) (float64, []string) {
+	return cosine(m.vectors[a], m.vectors[b], topTerms)
+}
+
+func cosine(vectorA map[string]float64, vectorB map[string]float64, topTerms int) (float64, []string) {
+	type contribution struct {
+		term  string
+		score float64
+	}
+	shared := []contribution{}
+	score := 0.0
+	for term, weight := range vectorA {
+		if other, ok := vectorB[term]; ok {
+			shared = append(shared, contribution{term: term, score: weight * other})
+			score += weight * other
+		}
+	}
+	slices.SortFunc(shared, func(x, y contribution) int {
+		if c := cmp.Compare(y.score, x.score); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.term, y.term)
+	})
+	top := []string{}
+	for _, c := range shared[:min(topTerms, len(shared))] {
+		top = append(top, c.term)
+	}
+	return score, top
+}
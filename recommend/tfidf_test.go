@@ -0,0 +1,35 @@
+package recommend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"matrix", "world", "within", "world", "1984"}, terms("The Matrix is a world within the world, 1984."))
+	assert.Empty(t, terms(""))
+}
+
+func TestTFIDF_similarity(t *testing.T) {
+	model := newTFIDF(map[string][]string{
+		"a": terms("hackers jack into cyberspace"),
+		"b": terms("cyberspace cowboys and hackers"),
+		"c": terms("a regency romance in bath"),
+		"d": {},
+	})
+
+	score, shared := model.similarity("a", "b", 5)
+	assert.Greater(t, score, 0.0)
+	assert.ElementsMatch(t, []string{"hackers", "cyberspace"}, shared)
+
+	score, shared = model.similarity("a", "c", 5)
+	assert.Equal(t, 0.0, score)
+	assert.Empty(t, shared)
+
+	score, _ = model.similarity("a", "a", 5)
+	assert.InDelta(t, 1.0, score, 1e-9)
+
+	score, _ = model.similarity("a", "d", 5)
+	assert.Equal(t, 0.0, score)
+}
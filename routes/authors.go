@@ -0,0 +1,216 @@
+package routes
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/syndication"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	profilePageSize     = 40
+	maxProfilePages     = 10
+	profileCacheTTL     = time.Hour
+	profileTopCounts    = 5
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 50
+)
+
+type AuthorProfileResponse struct {
+	Name                string          `json:"name"`
+	TotalWorks          int             `json:"totalWorks"`
+	FirstPublishedYear  int             `json:"firstPublishedYear,omitempty"`
+	LatestPublishedYear int             `json:"latestPublishedYear,omitempty"`
+	TopCategories       []NameCount     `json:"topCategories"`
+	CoAuthors           []NameCount     `json:"coAuthors"`
+	NotableWorkID       string          `json:"notableWorkId,omitempty"`
+	Cover               *BookImageLinks `json:"cover,omitempty"`
+	Works               []BookResponse  `json:"works"`
+}
+
+type NameCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type RelatedAuthorsResponse struct {
+	Author  string                `json:"author"`
+	Related []model.RelatedAuthor `json:"related"`
+}
+
+// AuthorsRouter serves author resources. Profiles are cached separately from
+// book searches for profileCacheTTL, and feeds of new books for
+// authorFeedCacheTTL.
+func AuthorsRouter(r chi.Router, api client.BookClientInterface, graph *client.AuthorGraph) {
+	profiles := newResponseCache[AuthorProfileResponse](profileCacheTTL)
+	r.Get("/authors/{name}", getAuthorProfile(api, profiles))
+	r.Get("/authors/{name}/related", getRelatedAuthors(graph))
+	feeds := newResponseCache[[]model.GoogleBookItem](authorFeedCacheTTL)
+	r.Get("/authors/{name}/feed.atom", authorFeedHandler(api, feeds, syndication.AtomMediaType))
+	r.Get("/authors/{name}/feed.rss", authorFeedHandler(api, feeds, syndication.RSSMediaType))
+}
+
+func getRelatedAuthors(graph *client.AuthorGraph) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := url.PathUnescape(chi.URLParam(r, "name"))
+		if err != nil || strings.TrimSpace(name) == "" {
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+		limit := defaultRelatedLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > maxRelatedLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxRelatedLimit), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Authors only appear once one of their books has been fetched
+		if !graph.Known(name) {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		resp := RelatedAuthorsResponse{Author: name, Related: graph.Related(name, limit)}
+		render(w, r, http.StatusOK, resp)
+	}
+}
+
+func getAuthorProfile(bookClient client.BookClientInterface, profiles *responseCache[AuthorProfileResponse]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := url.PathUnescape(chi.URLParam(r, "name"))
+		if err != nil || strings.TrimSpace(name) == "" {
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+
+		profile, ok := profiles.Get(name)
+		if !ok {
+			books, err := fetchAuthorBibliography(r, bookClient, name)
+			if err != nil {
+				slog.Error(err.Error())
+				http.Error(w, "", http.StatusInternalServerError)
+				return
+			}
+			profile = buildAuthorProfile(name, books)
+			// Unknown authors are not cached, so they appear once Google
+			// has their books
+			if profile.TotalWorks > 0 {
+				profiles.Set(name, profile)
+			}
+		}
+
+		// Unknown author
+		if profile.TotalWorks == 0 {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(profileCacheTTL.Seconds())))
+		render(w, r, http.StatusOK, profile)
+	}
+}
+
+// fetchAuthorBibliography reads the first page of an author search to learn
+// how many results there are, then fetches the remaining pages, up to
+// maxProfilePages, through the same paginated flow as POST /books/author.
+func fetchAuthorBibliography(r *http.Request, bookClient client.BookClientInterface, name string) ([]model.GoogleBookItem, error) {
+	bookReq := client.GoogleBookRequest{Author: name, Limit: profilePageSize, SortBy: client.SortPublishedAsc}
+	pages, err := client.FetchAuthorPages(r.Context(), bookClient, bookReq)
+	if err != nil {
+		return nil, err
+	}
+
+	pageCount := min(int(math.Ceil(float64(pages[0].TotalItems)/profilePageSize)), maxProfilePages)
+	if pageCount > 1 {
+		bookReq.Start = profilePageSize
+		bookReq.Pages = pageCount - 2
+		rest, err := client.FetchAuthorPages(r.Context(), bookClient, bookReq)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, rest...)
+	}
+	books, _ := client.MergeAuthorPages(pages, client.SortPublishedAsc)
+	return books, nil
+}
+
+func buildAuthorProfile(name string, books []model.GoogleBookItem) AuthorProfileResponse {
+	profile := AuthorProfileResponse{Name: name, TotalWorks: len(books), TopCategories: []NameCount{}, CoAuthors: []NameCount{}}
+	categories := map[string]int{}
+	coAuthors := map[string]int{}
+	var notable *model.GoogleBookItem
+
+	for i, book := range books {
+		if published := model.ParsePublicationDate(book.VolumeInfo.PublishedDate); !published.IsZero() {
+			if profile.FirstPublishedYear == 0 || published.Year < profile.FirstPublishedYear {
+				profile.FirstPublishedYear = published.Year
+			}
+			profile.LatestPublishedYear = max(profile.LatestPublishedYear, published.Year)
+		}
+		for _, category := range book.VolumeInfo.Categories {
+			categories[category]++
+		}
+		for _, author := range book.VolumeInfo.Authors {
+			if author != name {
+				coAuthors[author]++
+			}
+		}
+		if notable == nil || compareNotability(book, *notable) > 0 {
+			notable = &books[i]
+		}
+
+		var br BookResponse
+		br.fromItem(book)
+		profile.Works = append(profile.Works, br)
+	}
+
+	profile.TopCategories = topCounts(categories, profileTopCounts)
+	profile.CoAuthors = topCounts(coAuthors, len(coAuthors))
+	if notable != nil {
+		profile.NotableWorkID = notable.ID
+		profile.Cover = &BookImageLinks{
+			SmallThumbnail: notable.VolumeInfo.ImageLinks.SmallThumbnail,
+			Thumbnail:      notable.VolumeInfo.ImageLinks.Thumbnail,
+		}
+	}
+	return profile
+}
+
+// compareNotability ranks works by how many ratings they have, then by how
+// many editions were printed, then by how much Google knows about them.
+func compareNotability(a model.GoogleBookItem, b model.GoogleBookItem) int {
+	if c := cmp.Compare(a.VolumeInfo.RatingsCount, b.VolumeInfo.RatingsCount); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(len(a.Editions), len(b.Editions)); c != 0 {
+		return c
+	}
+	return cmp.Compare(len(a.VolumeInfo.Description), len(b.VolumeInfo.Description))
+}
+
+func topCounts(counts map[string]int, limit int) []NameCount {
+	top := []NameCount{}
+	for name, count := range counts {
+		top = append(top, NameCount{Name: name, Count: count})
+	}
+	slices.SortFunc(top, func(a, b NameCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return top[:min(limit, len(top))]
+}
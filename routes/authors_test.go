@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// CountingMockClient counts author requests made through a PagedMockClient
+type CountingMockClient struct {
+	PagedMockClient
+	calls *atomic.Int32
+}
+
+func (cli CountingMockClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	cli.calls.Add(1)
+	return cli.PagedMockClient.ByAuthor(ctx, request)
+}
+
+func TestAuthorsRouter(t *testing.T) {
+	book := func(id string, title string, date string, authors []string, categories []string) model.GoogleBookItem {
+		return model.GoogleBookItem{
+			ID: id,
+			VolumeInfo: model.GoogleBookVolumeInfo{
+				Title:         title,
+				Authors:       authors,
+				PublishedDate: date,
+				Categories:    categories,
+				ImageLinks:    model.GoogleBookImageLinks{Thumbnail: "http://example.com/" + id},
+			},
+		}
+	}
+	gibson := []string{"William Gibson"}
+	cli := CountingMockClient{
+		calls: &atomic.Int32{},
+		PagedMockClient: PagedMockClient{
+			Pages: map[int]model.GoogleBookResponse{
+				0: {TotalItems: 50, Items: []model.GoogleBookItem{
+					book("neuromancer", "Neuromancer", "1984-07", gibson, []string{"Fiction"}),
+					book("neuromancer-reissue", "Neuromancer", "2000", gibson, []string{"Fiction"}),
+				}},
+				40: {TotalItems: 50, Items: []model.GoogleBookItem{
+					book("difference-engine", "The Difference Engine", "1990", []string{"William Gibson", "Bruce Sterling"}, []string{"Fiction", "Steampunk"}),
+					book("agency", "Agency", "2020", gibson, []string{"Science Fiction"}),
+				}},
+			},
+		},
+	}
+	r := chi.NewRouter()
+	AuthorsRouter(r, cli, client.NewAuthorGraph())
+
+	req, _ := http.NewRequest("GET", "/authors/William%20Gibson", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	var profile AuthorProfileResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+	assert.Equal(t, "William Gibson", profile.Name)
+	assert.Equal(t, 3, profile.TotalWorks)
+	assert.Equal(t, 1984, profile.FirstPublishedYear)
+	assert.Equal(t, 2020, profile.LatestPublishedYear)
+	assert.Equal(t, []NameCount{{Name: "Fiction", Count: 2}, {Name: "Science Fiction", Count: 1}, {Name: "Steampunk", Count: 1}}, profile.TopCategories)
+	assert.Equal(t, []NameCount{{Name: "Bruce Sterling", Count: 1}}, profile.CoAuthors)
+	assert.Equal(t, "neuromancer", profile.NotableWorkID)
+	assert.Equal(t, "http://example.com/neuromancer", profile.Cover.Thumbnail)
+	assert.Len(t, profile.Works[0].Editions, 1)
+	assert.Equal(t, int32(2), cli.calls.Load())
+
+	// cached profiles skip the upstream requests
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(2), cli.calls.Load())
+
+}
+
+func TestAuthorsRouter_profileExpires(t *testing.T) {
+	cli := CountingMockClient{
+		calls: &atomic.Int32{},
+		PagedMockClient: PagedMockClient{Pages: map[int]model.GoogleBookResponse{
+			0: {TotalItems: 1, Items: []model.GoogleBookItem{{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}}}}},
+		}},
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	profiles := newResponseCache[AuthorProfileResponse](profileCacheTTL)
+	profiles.now = func() time.Time { return now }
+	r := chi.NewRouter()
+	r.Get("/authors/{name}", getAuthorProfile(cli, profiles))
+
+	get := func() {
+		req, _ := http.NewRequest("GET", "/authors/William%20Gibson", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	get()
+	assert.Equal(t, int32(1), cli.calls.Load())
+
+	// hits do not extend the entry's lifetime
+	now = now.Add(profileCacheTTL / 2)
+	get()
+	assert.Equal(t, int32(1), cli.calls.Load())
+
+	now = now.Add(profileCacheTTL/2 + time.Second)
+	get()
+	assert.Equal(t, int32(2), cli.calls.Load())
+}
+
+func TestAuthorsRouter_unknownAuthor(t *testing.T) {
+	r := chi.NewRouter()
+	AuthorsRouter(r, PagedMockClient{}, client.NewAuthorGraph())
+
+	req, _ := http.NewRequest("GET", "/authors/Nobody", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAuthorsRouter_clientError(t *testing.T) {
+	r := chi.NewRouter()
+	AuthorsRouter(r, MockClient{Err: errors.New("test-error")}, client.NewAuthorGraph())
+
+	req, _ := http.NewRequest("GET", "/authors/William%20Gibson", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthorsRouter_related(t *testing.T) {
+	graph := client.NewAuthorGraph()
+	graph.AddVolumes([]model.GoogleBookItem{
+		{ID: "1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "The Difference Engine", Authors: []string{"William Gibson", "Bruce Sterling"}, Categories: []string{"Fiction"}}},
+		{ID: "2", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Snow Crash", Authors: []string{"Neal Stephenson"}, Categories: []string{"Fiction"}}},
+	})
+	r := chi.NewRouter()
+	AuthorsRouter(r, PagedMockClient{}, graph)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedNames  []string
+	}{
+		{
+			name:           "GET:/authors/{name}/related with a known author",
+			path:           "/authors/William%20Gibson/related",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"Bruce Sterling", "Neal Stephenson"},
+		},
+		{
+			name:           "GET:/authors/{name}/related with a limit",
+			path:           "/authors/William%20Gibson/related?limit=1",
+			expectedStatus: http.StatusOK,
+			expectedNames:  []string{"Bruce Sterling"},
+		},
+		{
+			name:           "GET:/authors/{name}/related with an invalid limit",
+			path:           "/authors/William%20Gibson/related?limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GET:/authors/{name}/related with an unknown author",
+			path:           "/authors/Nobody/related",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp RelatedAuthorsResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				names := []string{}
+				for _, related := range resp.Related {
+					names = append(names, related.Name)
+				}
+				assert.Equal(t, tt.expectedNames, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxBatchLookups = 200
+	// batchConcurrency bounds the lookups in flight for a single batch.
+	batchConcurrency = 4
+	// batchRequestInterval spaces upstream requests from all batches so a
+	// large import stays within Google's per-second quota.
+	batchRequestInterval  = 100 * time.Millisecond
+	batchRateLimitBackoff = 2 * time.Second
+	batchRetries          = 2
+	batchCacheTTL         = time.Hour
+)
+
+type BatchRequest struct {
+	Lookups []BatchLookup
+}
+
+// BatchLookup finds books by ISBN, or by title and author. At least one of
+// the fields must be set and ISBN takes precedence.
+type BatchLookup struct {
+	Title  string
+	Author string
+	ISBN   string
+}
+
+type BatchResponse struct {
+	TotalItems int           `json:"totalItems"`
+	Succeeded  int           `json:"succeeded"`
+	Results    []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of one lookup. Status is the HTTP status the
+// lookup would have had as a request of its own.
+type BatchResult struct {
+	Index      int            `json:"index"`
+	Title      string         `json:"title,omitempty"`
+	Author     string         `json:"author,omitempty"`
+	ISBN       string         `json:"isbn,omitempty"`
+	Status     int            `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	Cached     bool           `json:"cached"`
+	TotalItems int            `json:"totalItems"`
+	Books      []BookResponse `json:"books"`
+}
+
+// BatchRouter serves many lookups in one request. Identical lookups are
+// fetched once and results are cached between batches.
+func BatchRouter(r chi.Router, api client.BookClientInterface) {
+	limiter := newRateLimiter(batchRequestInterval, batchRateLimitBackoff)
+	cache := newResponseCache[BatchResult](batchCacheTTL)
+	r.Post("/books/batch", batchLookup(api, limiter, cache))
+}
+
+func batchLookup(bookClient client.BookClientInterface, limiter *rateLimiter, cache *responseCache[BatchResult]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse the request body
+		var batchReq BatchRequest
+		err := json.NewDecoder(r.Body).Decode(&batchReq)
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+		if len(batchReq.Lookups) == 0 || len(batchReq.Lookups) > maxBatchLookups {
+			http.Error(w, fmt.Sprintf("Lookups must have between 1 and %d entries", maxBatchLookups), http.StatusBadRequest)
+			return
+		}
+
+		// Fetch each distinct lookup once
+		indices := map[string][]int{}
+		keys := []string{}
+		for i, lookup := range batchReq.Lookups {
+			key := lookup.key()
+			if _, ok := indices[key]; !ok {
+				keys = append(keys, key)
+			}
+			indices[key] = append(indices[key], i)
+		}
+		results := make([]BatchResult, len(batchReq.Lookups))
+		sem := make(chan struct{}, batchConcurrency)
+		var wg sync.WaitGroup
+		for _, key := range keys {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				result := lookupBatchItem(r.Context(), bookClient, limiter, cache, batchReq.Lookups[indices[key][0]])
+				for _, i := range indices[key] {
+					lookup := batchReq.Lookups[i]
+					results[i] = result
+					results[i].Index = i
+					results[i].Title, results[i].Author, results[i].ISBN = lookup.Title, lookup.Author, lookup.ISBN
+				}
+			}(key)
+		}
+		wg.Wait()
+
+		// Format Response
+		resp := BatchResponse{TotalItems: len(results), Results: results}
+		for _, result := range results {
+			if result.Status == http.StatusOK {
+				resp.Succeeded++
+			}
+		}
+
+		render(w, r, http.StatusOK, resp)
+	}
+}
+
+func lookupBatchItem(ctx context.Context, bookClient client.BookClientInterface, limiter *rateLimiter, cache *responseCache[BatchResult], lookup BatchLookup) BatchResult {
+	result := BatchResult{Books: []BookResponse{}}
+	if lookup.key() == "" {
+		result.Status = http.StatusBadRequest
+		result.Error = "lookup needs a Title, Author or ISBN"
+		return result
+	}
+	if isbn, ok := strings.CutPrefix(lookup.key(), "isbn:"); ok && !validISBN(isbn) {
+		result.Status = http.StatusBadRequest
+		result.Error = fmt.Sprintf("invalid ISBN %q", lookup.ISBN)
+		return result
+	}
+	if cached, ok := cache.Get(lookup.key()); ok {
+		cached.Cached = true
+		return cached
+	}
+
+	var books model.GoogleBookResponse
+	var err error
+	for attempt := 0; attempt <= batchRetries; attempt++ {
+		if err = limiter.Wait(ctx); err != nil {
+			break
+		}
+		books, err = lookup.fetch(ctx, bookClient)
+		if !errors.Is(err, client.ErrRateLimited) {
+			break
+		}
+		limiter.Backoff(limiter.backoff << attempt)
+	}
+
+	switch {
+	case errors.Is(err, client.ErrRateLimited):
+		result.Status = http.StatusTooManyRequests
+		result.Error = err.Error()
+		return result
+	case err != nil:
+		slog.Error(err.Error())
+		result.Status = http.StatusInternalServerError
+		result.Error = err.Error()
+		return result
+	case len(books.Items) == 0:
+		result.Status = http.StatusNotFound
+	default:
+		result.Status = http.StatusOK
+		result.TotalItems = books.TotalItems
+		for _, book := range books.Items {
+			var br BookResponse
+			br.fromItem(book)
+			result.Books = append(result.Books, br)
+		}
+	}
+	cache.Set(lookup.key(), result)
+	return result
+}
+
+// key identifies lookups that would fetch the same books. It is empty for a
+// lookup with nothing to look up.
+func (l BatchLookup) key() string {
+	if isbn := strings.ToUpper(strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, l.ISBN)); isbn != "" {
+		return "isbn:" + isbn
+	}
+	title := strings.ToLower(strings.TrimSpace(l.Title))
+	author := strings.ToLower(strings.TrimSpace(l.Author))
+	if title == "" && author == "" {
+		return ""
+	}
+	return "title:" + title + "|author:" + author
+}
+
+// validISBN checks the shape of an ISBN-10 or ISBN-13 with separators removed.
+func validISBN(isbn string) bool {
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return false
+	}
+	for i, r := range isbn {
+		if !unicode.IsDigit(r) && !(r == 'X' && i == 9 && len(isbn) == 10) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l BatchLookup) fetch(ctx context.Context, bookClient client.BookClientInterface) (model.GoogleBookResponse, error) {
+	switch {
+	case strings.TrimSpace(l.ISBN) != "":
+		return bookClient.ByQuery(ctx, client.GoogleBookRequest{Query: "isbn:" + strings.TrimPrefix(l.key(), "isbn:")})
+	case strings.TrimSpace(l.Title) != "":
+		return bookClient.ByTitle(ctx, client.GoogleBookRequest{Title: l.Title, Author: l.Author})
+	default:
+		return bookClient.ByAuthor(ctx, client.GoogleBookRequest{Author: l.Author})
+	}
+}
+
+// rateLimiter spaces calls at least interval apart across every goroutine
+// sharing it. It is safe for concurrent use.
+type rateLimiter struct {
+	interval time.Duration
+	// backoff is the initial pause after upstream rejects a request.
+	backoff time.Duration
+	mu      sync.Mutex
+	next    time.Time
+}
+
+func newRateLimiter(interval time.Duration, backoff time.Duration) *rateLimiter {
+	return &rateLimiter{interval: interval, backoff: backoff}
+}
+
+// Wait blocks until the caller may make its call or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Backoff holds every caller back for at least d, for when upstream has
+// said it is receiving too many requests.
+func (l *rateLimiter) Backoff(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); l.next.Before(until) {
+		l.next = until
+	}
+}
@@ -0,0 +1,152 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// BatchMockClient answers each lookup kind with its own volume and counts
+// upstream calls. The first rateLimited calls fail with ErrRateLimited.
+type BatchMockClient struct {
+	MockClient
+	calls       *atomic.Int32
+	rateLimited int32
+}
+
+func (cli BatchMockClient) respond(id string, title string) (model.GoogleBookResponse, error) {
+	if cli.calls.Add(1) <= cli.rateLimited {
+		return model.GoogleBookResponse{}, client.ErrRateLimited
+	}
+	if title == "unknown" {
+		return model.GoogleBookResponse{}, nil
+	}
+	if title == "broken" {
+		return model.GoogleBookResponse{}, errors.New("test-error")
+	}
+	return model.GoogleBookResponse{TotalItems: 1, Items: []model.GoogleBookItem{
+		{ID: id, VolumeInfo: model.GoogleBookVolumeInfo{Title: title}},
+	}}, nil
+}
+
+func (cli BatchMockClient) ByTitle(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return cli.respond("by-title", request.Title)
+}
+
+func (cli BatchMockClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return cli.respond("by-author", request.Author)
+}
+
+func (cli BatchMockClient) ByQuery(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return cli.respond("by-query", request.Query)
+}
+
+func postBatch(t *testing.T, r http.Handler, lookups []BatchLookup) (int, BatchResponse) {
+	body, _ := json.Marshal(BatchRequest{Lookups: lookups})
+	req, _ := http.NewRequest("POST", "/books/batch", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp BatchResponse
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func setupBatchRouter(cli client.BookClientInterface) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/books/batch", batchLookup(cli, newRateLimiter(time.Millisecond, 50*time.Millisecond), newResponseCache[BatchResult](time.Hour)))
+	return r
+}
+
+func TestBatchRouter(t *testing.T) {
+	cli := BatchMockClient{calls: &atomic.Int32{}}
+	r := setupBatchRouter(cli)
+
+	status, resp := postBatch(t, r, []BatchLookup{
+		{Title: "Count Zero", Author: "William Gibson"},
+		{Author: "William Gibson"},
+		{ISBN: "978-0-441-11773-2"},
+		{Title: "unknown"},
+		{Title: "broken"},
+		{},
+		{ISBN: "not-an-isbn"},
+		{Title: "count zero ", Author: "william gibson"},
+	})
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 8, resp.TotalItems)
+	assert.Equal(t, 4, resp.Succeeded)
+	statuses, ids := []int{}, []string{}
+	for i, result := range resp.Results {
+		assert.Equal(t, i, result.Index)
+		statuses = append(statuses, result.Status)
+		id := ""
+		if len(result.Books) > 0 {
+			id = result.Books[0].ID
+		}
+		ids = append(ids, id)
+	}
+	assert.Equal(t, []int{200, 200, 200, 404, 500, 400, 400, 200}, statuses)
+	assert.Equal(t, []string{"by-title", "by-author", "by-query", "", "", "", "", "by-title"}, ids)
+	assert.Equal(t, "test-error", resp.Results[4].Error)
+	assert.Equal(t, `invalid ISBN "not-an-isbn"`, resp.Results[6].Error)
+	assert.Equal(t, "count zero ", resp.Results[7].Title)
+	// The repeated title lookup is only fetched once
+	assert.Equal(t, int32(5), cli.calls.Load())
+
+	_, resp = postBatch(t, r, []BatchLookup{{ISBN: "9780441117732"}, {Title: "broken"}})
+
+	assert.True(t, resp.Results[0].Cached)
+	assert.False(t, resp.Results[1].Cached)
+	assert.Equal(t, int32(6), cli.calls.Load())
+}
+
+func TestBatchRouter_rateLimited(t *testing.T) {
+	retried := BatchMockClient{calls: &atomic.Int32{}, rateLimited: 1}
+	limiter := newRateLimiter(time.Millisecond, 50*time.Millisecond)
+	r := chi.NewRouter()
+	r.Post("/books/batch", batchLookup(retried, limiter, newResponseCache[BatchResult](time.Hour)))
+
+	start := time.Now()
+	_, resp := postBatch(t, r, []BatchLookup{{Title: "Count Zero"}})
+
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+	assert.Equal(t, int32(2), retried.calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestBatchRouter_invalidRequest(t *testing.T) {
+	r := setupBatchRouter(BatchMockClient{calls: &atomic.Int32{}})
+
+	status, _ := postBatch(t, r, []BatchLookup{})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = postBatch(t, r, make([]BatchLookup, maxBatchLookups+1))
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(20*time.Millisecond, time.Hour)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limiter.Wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	limiter.Backoff(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+}
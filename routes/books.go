@@ -3,17 +3,31 @@ package routes
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
-	"math"
 	"net/http"
+	"slices"
 	"strconv"
-	"sync"
+	"strings"
 
 	client "example.com/book-learn/clients"
 	model "example.com/book-learn/models"
+	"example.com/book-learn/search"
 	"github.com/go-chi/chi/v5"
 )
 
+const (
+	maxSpellingSuggestions = 5
+	// maxQueryLimit is the most results Google returns per request.
+	maxQueryLimit = 40
+	// defaultAuthorLimit is Google's page size when maxResults is not given.
+	defaultAuthorLimit = 10
+	// maxAuthorPages bounds an author search to ten concurrent upstream
+	// requests, as pages counts the pages after the first.
+	maxAuthorPages = 9
+)
+
 type AuthorResponse struct {
 	Author       string         `json:"author"`
 	TotalItems   int            `json:"totalItems"`
@@ -25,22 +39,51 @@ type TitleResponse struct {
 	Title      string         `json:"title"`
 	TotalItems int            `json:"totalItems"`
 	Books      []BookResponse `json:"books"`
+	DidYouMean []string       `json:"didYouMean,omitempty"`
+}
+
+type QueryResponse struct {
+	Query      string         `json:"query"`
+	TotalItems int            `json:"totalItems"`
+	Books      []BookResponse `json:"books"`
+	DidYouMean []string       `json:"didYouMean,omitempty"`
+}
+
+// QueryErrorResponse points at the part of a query that could not be parsed.
+type QueryErrorResponse struct {
+	Error    string `json:"error"`
+	Position int    `json:"position"`
+	End      int    `json:"end"`
 }
 
 type BookResponse struct {
-	Title               string                  `json:"title"`
-	Authors             []string                `json:"authors"`
-	PublishedDate       string                  `json:"publishedDate"`
-	Description         string                  `json:"description"`
-	PageCount           int                     `json:"pageCount"`
-	Categories          []string                `json:"categories"`
-	ContentVersion      string                  `json:"contentVersion"`
-	PanelizationSummary BookPanelizationSummary `json:"panelizationSummary"`
-	ImageLinks          BookImageLinks          `json:"imageLinks"`
-	Language            string                  `json:"language"`
-	PreviewLink         string                  `json:"previewLink"`
-	InfoLink            string                  `json:"infoLink"`
-	CanonicalVolumeLink string                  `json:"canonicalVolumeLink"`
+	ID                      string                   `json:"id"`
+	Title                   string                   `json:"title"`
+	Subtitle                string                   `json:"subtitle"`
+	Authors                 []string                 `json:"authors"`
+	Publisher               string                   `json:"publisher"`
+	PublishedDate           string                   `json:"publishedDate"`
+	PublishedDateNormalized model.PublicationDate    `json:"publishedDateNormalized"`
+	Description             string                   `json:"description"`
+	IndustryIdentifiers     []BookIndustryIdentifier `json:"industryIdentifiers"`
+	PageCount               int                      `json:"pageCount"`
+	Categories              []string                 `json:"categories"`
+	NormalizedCategories    []model.Category         `json:"normalizedCategories"`
+	ContentVersion          string                   `json:"contentVersion"`
+	PanelizationSummary     BookPanelizationSummary  `json:"panelizationSummary"`
+	ImageLinks              BookImageLinks           `json:"imageLinks"`
+	Language                string                   `json:"language"`
+	PreviewLink             string                   `json:"previewLink"`
+	InfoLink                string                   `json:"infoLink"`
+	CanonicalVolumeLink     string                   `json:"canonicalVolumeLink"`
+	Series                  *model.Series            `json:"series,omitempty"`
+	Relevance               *model.RelevanceScore    `json:"relevance,omitempty"`
+	Editions                []BookResponse           `json:"editions,omitempty"`
+}
+
+type BookIndustryIdentifier struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
 }
 
 type BookPanelizationSummary struct {
@@ -53,13 +96,31 @@ type BookImageLinks struct {
 	Thumbnail      string `json:"thumbnail"`
 }
 
+func (br *BookResponse) fromItem(item model.GoogleBookItem) {
+	br.ID = item.ID
+	br.fromVolumeInfo(item.VolumeInfo)
+	br.Series = client.DetectSeries(item)
+	for _, edition := range item.Editions {
+		var er BookResponse
+		er.fromItem(edition)
+		br.Editions = append(br.Editions, er)
+	}
+}
+
 func (br *BookResponse) fromVolumeInfo(vi model.GoogleBookVolumeInfo) {
 	br.Title = vi.Title
+	br.Subtitle = vi.Subtitle
 	br.Authors = vi.Authors
+	br.Publisher = vi.Publisher
 	br.PublishedDate = vi.PublishedDate
+	br.PublishedDateNormalized = model.ParsePublicationDate(vi.PublishedDate)
 	br.Description = vi.Description
+	for _, id := range vi.IndustryIdentifiers {
+		br.IndustryIdentifiers = append(br.IndustryIdentifiers, BookIndustryIdentifier{Type: id.Type, Identifier: id.Identifier})
+	}
 	br.PageCount = vi.PageCount
 	br.Categories = vi.Categories
+	br.NormalizedCategories = client.NormalizeCategories(vi.Categories)
 	br.ContentVersion = vi.ContentVersion
 	br.PanelizationSummary = BookPanelizationSummary{
 		ContainsEpubBubbles:  vi.PanelizationSummary.ContainsEpubBubbles,
@@ -75,9 +136,14 @@ func (br *BookResponse) fromVolumeInfo(vi model.GoogleBookVolumeInfo) {
 	br.CanonicalVolumeLink = vi.CanonicalVolumeLink
 }
 
-func BooksRouter(r chi.Router, api client.BookClientInterface) {
+// BooksRouter serves author and title searches and single volumes, with the
+// other editions the catalog, if given, has seen. When a title search finds
+// nothing the speller, if given, suggests corrections.
+func BooksRouter(r chi.Router, api client.BookClientInterface, catalog *client.Catalog, speller *search.Speller) {
 	r.Post("/books/author", queryByAuthor(api))
-	r.Post("/books/title", queryByTitle(api))
+	r.Post("/books/title", queryByTitle(api, speller))
+	r.Get("/books", queryBooks(api, speller))
+	r.Get("/books/{id}", bookByID(api, catalog))
 }
 
 func queryByAuthor(bookClient client.BookClientInterface) http.HandlerFunc {
@@ -90,53 +156,32 @@ func queryByAuthor(bookClient client.BookClientInterface) http.HandlerFunc {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
-		slog.Info("BookRequest:", "Author", bookReq.Author, "Start", strconv.Itoa(bookReq.Start), "limit", strconv.Itoa(bookReq.Limit), "Pages", strconv.Itoa(bookReq.Pages))
-
-		results := make(chan model.GoogleBookResponse, bookReq.Pages+1)
-		var wg sync.WaitGroup
-
-		fetch := func(start int) {
-			defer wg.Done()
-			// Fetch data from external API
-			req := client.GoogleBookRequest{
-				Title:  bookReq.Title,
-				Author: bookReq.Author,
-				Start:  start,
-				Limit:  bookReq.Limit,
-				Pages:  0,
-			}
-			books, err := bookClient.ByAuthor(context.Background(), req)
-			if err != nil {
-				slog.Error(err.Error())
-				http.Error(w, "", http.StatusInternalServerError)
-				return
-			}
-			slog.Info(req.Author, "Start", strconv.Itoa(req.Start), "limit", strconv.Itoa(req.Limit), "Pages", strconv.Itoa(req.Pages))
-
-			results <- books
+		if bookReq.Limit == 0 {
+			bookReq.Limit = defaultAuthorLimit
 		}
-		wg.Add(1)
-		go fetch(bookReq.Start)
-
-		for i := 0; i <= bookReq.Pages; i++ {
-			wg.Add(1)
-			go fetch(bookReq.Start + i*bookReq.Limit)
+		if err := checkAuthorPages(bookReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		if err := bookReq.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("BookRequest:", "Author", bookReq.Author, "Start", strconv.Itoa(bookReq.Start), "limit", strconv.Itoa(bookReq.Limit), "Pages", strconv.Itoa(bookReq.Pages))
 
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
-
-		var books []model.GoogleBookItem
-		totalItems := 0
+		// Stream books as their pages arrive when the client asks for it
+		if acceptsMediaType(r, ndjsonMediaType) {
+			streamAuthorNDJSON(w, r, bookClient, bookReq)
+			return
+		}
 
-		for result := range results {
-			if totalItems == 0 {
-				totalItems = result.TotalItems
-			}
-			books = append(books, result.Items...)
+		pages, err := client.FetchAuthorPages(r.Context(), bookClient, bookReq)
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
 		}
+		books, totalItems := client.MergeAuthorPages(pages, bookReq.SortOrder(client.SortPublishedDesc))
 
 		// No results
 		if len(books) == 0 {
@@ -147,24 +192,37 @@ func queryByAuthor(bookClient client.BookClientInterface) http.HandlerFunc {
 		var bookResp AuthorResponse
 		bookResp.Author = bookReq.Author
 		bookResp.TotalItems = totalItems
-		bookResp.HasMorePages = int(math.Ceil(float64(totalItems)/float64(bookReq.Limit))-float64(bookReq.Pages)) > 0
+		bookResp.HasMorePages = client.HasMorePages(totalItems, bookReq)
 		for _, book := range books {
 			var br BookResponse
-			br.fromVolumeInfo(book.VolumeInfo)
+			br.fromItem(book)
+			if bookReq.Debug {
+				br.Relevance = book.Relevance
+			}
 			bookResp.Books = append(bookResp.Books, br)
 		}
 
 		// write de jaysawn
-		encoder := json.NewEncoder(w)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(bookResp); err != nil {
-			slog.Error(err.Error())
-			http.Error(w, "", http.StatusInternalServerError)
-		}
+		render(w, r, http.StatusOK, bookResp)
 	}
 }
 
-func queryByTitle(bookClient client.BookClientInterface) http.HandlerFunc {
+// checkAuthorPages bounds the pages of an author search. Every page is
+// fetched concurrently, and a zero limit would fetch the same page each time.
+func checkAuthorPages(bookReq client.GoogleBookRequest) error {
+	if bookReq.Start < 0 {
+		return errors.New("start must not be negative")
+	}
+	if bookReq.Limit < 1 || bookReq.Limit > maxQueryLimit {
+		return fmt.Errorf("limit must be between 1 and %d", maxQueryLimit)
+	}
+	if bookReq.Pages < 0 || bookReq.Pages > maxAuthorPages {
+		return fmt.Errorf("pages must be between 0 and %d", maxAuthorPages)
+	}
+	return nil
+}
+
+func queryByTitle(bookClient client.BookClientInterface, speller *search.Speller) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse the request body
 		var bookReq client.GoogleBookRequest
@@ -174,6 +232,10 @@ func queryByTitle(bookClient client.BookClientInterface) http.HandlerFunc {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
+		if err := bookReq.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
 		// Fetch data from external API
 		books, err := bookClient.ByTitle(context.Background(), bookReq)
@@ -183,28 +245,187 @@ func queryByTitle(bookClient client.BookClientInterface) http.HandlerFunc {
 			return
 		}
 
-		// No results
-		if len(books.Items) == 0 {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-
 		// Format Response
 		var resp TitleResponse
 		resp.Title = bookReq.Title
 		resp.TotalItems = books.TotalItems
+
+		// No results, but perhaps a typo
+		if len(books.Items) == 0 {
+			resp.DidYouMean = didYouMean(speller, bookReq.Title)
+			if len(resp.DidYouMean) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			resp.TotalItems = 0
+			resp.Books = []BookResponse{}
+		}
 		for _, book := range books.Items {
 			var br BookResponse
-			br.fromVolumeInfo(book.VolumeInfo)
+			br.fromItem(book)
+			if bookReq.Debug {
+				br.Relevance = book.Relevance
+			}
 			resp.Books = append(resp.Books, br)
 		}
 
 		// Gift the findings to our user, but this is an internal api so gift to me
-		encoder := json.NewEncoder(w)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(resp); err != nil {
+		render(w, r, http.StatusOK, resp)
+	}
+}
+
+// bookByID serves a single volume with the other editions of its work the
+// catalog has seen, which ByID does not group.
+func bookByID(bookClient client.BookClientInterface, catalog *client.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		book, err := bookClient.ByID(r.Context(), chi.URLParam(r, "id"))
+		if errors.Is(err, client.ErrVolumeNotFound) {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		if err != nil {
 			slog.Error(err.Error())
 			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		if catalog != nil {
+			book.Editions = catalog.Editions(book.ID)
+		}
+		var br BookResponse
+		br.fromItem(book)
+		render(w, r, http.StatusOK, br)
+	}
+}
+
+func queryBooks(bookClient client.BookClientInterface, speller *search.Speller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bookReq, ok := parseQueryRequest(w, r)
+		if !ok {
+			return
+		}
+
+		// Fetch data from external API
+		books, err := bookClient.ByQuery(r.Context(), bookReq)
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		// Format Response
+		resp := QueryResponse{Query: bookReq.Query, TotalItems: books.TotalItems}
+		for _, book := range books.Items {
+			var br BookResponse
+			br.fromItem(book)
+			resp.Books = append(resp.Books, br)
+		}
+
+		// No results, but perhaps a typo
+		if len(resp.Books) == 0 {
+			resp.DidYouMean = didYouMeanQuery(speller, bookReq.Query)
+			if len(resp.DidYouMean) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			resp.TotalItems = 0
+			resp.Books = []BookResponse{}
+		}
+
+		render(w, r, http.StatusOK, resp)
+	}
+}
+
+// parseQueryRequest reads q, sort, limit and start for a query-language
+// search. It writes the 400 response itself and reports whether the request
+// is usable.
+func parseQueryRequest(w http.ResponseWriter, r *http.Request) (client.GoogleBookRequest, bool) {
+	bookReq := client.GoogleBookRequest{
+		Query:  r.URL.Query().Get("q"),
+		SortBy: r.URL.Query().Get("sort"),
+	}
+	if strings.TrimSpace(bookReq.Query) == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return client.GoogleBookRequest{}, false
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxQueryLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxQueryLimit), http.StatusBadRequest)
+			return client.GoogleBookRequest{}, false
+		}
+		bookReq.Limit = limit
+	}
+	if value := r.URL.Query().Get("start"); value != "" {
+		start, err := strconv.Atoi(value)
+		if err != nil || start < 0 {
+			http.Error(w, "start must not be negative", http.StatusBadRequest)
+			return client.GoogleBookRequest{}, false
+		}
+		bookReq.Start = start
+	}
+	if err := bookReq.Validate(); err != nil {
+		var syntaxErr *client.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			render(w, r, http.StatusBadRequest, QueryErrorResponse{Error: syntaxErr.Message, Position: syntaxErr.Position, End: syntaxErr.End})
+			return client.GoogleBookRequest{}, false
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return client.GoogleBookRequest{}, false
+	}
+	return bookReq, true
+}
+
+// didYouMean suggests corrections for a search that found nothing.
+func didYouMean(speller *search.Speller, query string) []string {
+	if speller == nil {
+		return nil
+	}
+	return speller.Suggest(query, maxSpellingSuggestions)
+}
+
+// didYouMeanQuery suggests corrections for a query-language search that
+// found nothing. Only its free-text terms and phrases are spelt; qualifiers,
+// ranges and negations are kept as they were.
+func didYouMeanQuery(speller *search.Speller, raw string) []string {
+	query, err := client.ParseSearchQuery(raw)
+	if speller == nil || err != nil {
+		return nil
+	}
+	text, words := []int{}, []string{}
+	for i, clause := range query.Clauses {
+		if clause.Field == client.QueryFieldText && !clause.Negated {
+			text = append(text, i)
+			words = append(words, clause.Value)
+		}
+	}
+	if len(text) == 0 {
+		return nil
+	}
+
+	suggestions := []string{}
+	for _, correction := range speller.Suggest(strings.Join(words, " "), maxSpellingSuggestions) {
+		corrected := client.SearchQuery{Clauses: slices.Clone(query.Clauses)}
+		// A correction with as many words as the text keeps its terms and
+		// phrases; anything else replaces them as one phrase
+		if replaced := strings.Fields(correction); len(replaced) == len(strings.Fields(strings.Join(words, " "))) {
+			for _, i := range text {
+				n := len(strings.Fields(corrected.Clauses[i].Value))
+				corrected.Clauses[i].Value = strings.Join(replaced[:n], " ")
+				replaced = replaced[n:]
+			}
+		} else {
+			corrected.Clauses = nil
+			for i, clause := range query.Clauses {
+				if i == text[0] {
+					clause = client.QueryClause{Value: correction, Phrase: len(replaced) > 1}
+				} else if slices.Contains(text, i) {
+					continue
+				}
+				corrected.Clauses = append(corrected.Clauses, clause)
+			}
 		}
+		suggestions = append(suggestions, corrected.String())
 	}
+	return suggestions
 }
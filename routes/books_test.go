@@ -7,10 +7,13 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	client "example.com/book-learn/clients"
 	model "example.com/book-learn/models"
+	"example.com/book-learn/search"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,6 +30,20 @@ func (cli MockClient) ByAuthor(ctx context.Context, request client.GoogleBookReq
 func (cli MockClient) ByTitle(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
 	return cli.Response, cli.Err
 }
+func (cli MockClient) ByQuery(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return cli.Response, cli.Err
+}
+func (cli MockClient) ByID(ctx context.Context, id string) (model.GoogleBookItem, error) {
+	if cli.Err != nil {
+		return model.GoogleBookItem{}, cli.Err
+	}
+	for _, book := range cli.Response.Items {
+		if book.ID == id {
+			return book, nil
+		}
+	}
+	return model.GoogleBookItem{}, client.ErrVolumeNotFound
+}
 
 func setupBooksRouter(response model.GoogleBookResponse, err error) http.Handler {
 	r := chi.NewRouter()
@@ -34,7 +51,7 @@ func setupBooksRouter(response model.GoogleBookResponse, err error) http.Handler
 		Response: response,
 		Err:      err,
 	}
-	BooksRouter(r, cli)
+	BooksRouter(r, cli, nil, nil)
 	return r
 }
 
@@ -47,6 +64,10 @@ func TestBooksRouter(t *testing.T) {
 	}
 	testAuthorRequestBody, _ := json.Marshal(authorReq)
 	testTitleRequestBody, _ := json.Marshal(titleReq)
+	testInvalidDateRequestBody, _ := json.Marshal(client.GoogleBookRequest{
+		Author:         "test-author",
+		PublishedAfter: "not-a-date",
+	})
 
 	mockItems := []model.GoogleBookItem{
 		{
@@ -102,6 +123,15 @@ func TestBooksRouter(t *testing.T) {
 			expectedStatus:     http.StatusInternalServerError,
 			testRequestBody:    testAuthorRequestBody,
 		},
+		{
+			name:               "POST:/books/author with invalid published date",
+			method:             "POST",
+			path:               "/books/author",
+			mockClientResponse: model.GoogleBookResponse{},
+			mockClientError:    nil,
+			expectedStatus:     http.StatusBadRequest,
+			testRequestBody:    testInvalidDateRequestBody,
+		},
 		{
 			name:   "POST:/books/title with valid client response",
 			method: "POST",
@@ -154,3 +184,238 @@ func TestBooksRouter(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryByAuthor_bounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  client.GoogleBookRequest
+		accept   string
+		expected string
+	}{
+		{name: "negative pages", request: client.GoogleBookRequest{Author: "William Gibson", Pages: -1}, expected: "pages must be between 0 and 9"},
+		{name: "too many pages", request: client.GoogleBookRequest{Author: "William Gibson", Pages: 100000}, expected: "pages must be between 0 and 9"},
+		{name: "negative limit", request: client.GoogleBookRequest{Author: "William Gibson", Limit: -1}, expected: "limit must be between 1 and 40"},
+		{name: "limit too large", request: client.GoogleBookRequest{Author: "William Gibson", Limit: 41}, expected: "limit must be between 1 and 40"},
+		{name: "negative start", request: client.GoogleBookRequest{Author: "William Gibson", Start: -10}, expected: "start must not be negative"},
+		{name: "streamed", request: client.GoogleBookRequest{Author: "William Gibson", Pages: -1}, accept: ndjsonMediaType, expected: "pages must be between 0 and 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.request)
+			req, _ := http.NewRequest("POST", "/books/author", bytes.NewBuffer(body))
+			req.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+			setupBooksRouter(model.GoogleBookResponse{TotalItems: 42}, nil).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, tt.expected, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+// PagedMockClient serves a different response for each start index
+type PagedMockClient struct {
+	MockClient
+	Pages map[int]model.GoogleBookResponse
+}
+
+func (cli PagedMockClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return cli.Pages[request.Start], nil
+}
+
+func TestQueryByAuthor_sortsAcrossPages(t *testing.T) {
+	book := func(id string, date string) model.GoogleBookItem {
+		return model.GoogleBookItem{
+			ID: id,
+			VolumeInfo: model.GoogleBookVolumeInfo{
+				Title:         id,
+				Authors:       []string{"test-author"},
+				PublishedDate: date,
+			},
+		}
+	}
+	cli := PagedMockClient{
+		Pages: map[int]model.GoogleBookResponse{
+			0: {TotalItems: 4, Items: []model.GoogleBookItem{book("b", "1990"), book("a", "1980")}},
+			2: {TotalItems: 4, Items: []model.GoogleBookItem{book("d", "2000"), book("c", "1970")}},
+		},
+	}
+	tests := []struct {
+		name    string
+		sortBy  string
+		wantIDs []string
+	}{
+		{name: "default newest first", sortBy: "", wantIDs: []string{"d", "b", "a", "c"}},
+		{name: "oldest first", sortBy: client.SortPublishedAsc, wantIDs: []string{"c", "a", "b", "d"}},
+		{name: "title", sortBy: client.SortTitle, wantIDs: []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(client.GoogleBookRequest{Author: "test-author", Limit: 2, Pages: 1, SortBy: tt.sortBy})
+			req, _ := http.NewRequest("POST", "/books/author", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+			r := chi.NewRouter()
+			BooksRouter(r, cli, nil, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var resp AuthorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			gotIDs := []string{}
+			for _, book := range resp.Books {
+				gotIDs = append(gotIDs, book.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestQueryByTitle_didYouMean(t *testing.T) {
+	speller := search.NewSpeller()
+	speller.AddVolumes([]model.GoogleBookItem{
+		{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}}},
+	})
+	tests := []struct {
+		name           string
+		title          string
+		expectedStatus int
+		expected       []string
+	}{
+		{name: "typo", title: "Cuont Zero", expectedStatus: http.StatusOK, expected: []string{"Count Zero"}},
+		{name: "nothing close", title: "Idoru", expectedStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(client.GoogleBookRequest{Title: tt.title})
+			req, _ := http.NewRequest("POST", "/books/title", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+			r := chi.NewRouter()
+			BooksRouter(r, MockClient{Response: model.GoogleBookResponse{TotalItems: 3}}, nil, speller)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp TitleResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.title, resp.Title)
+				assert.Equal(t, 0, resp.TotalItems)
+				assert.Empty(t, resp.Books)
+				assert.Equal(t, tt.expected, resp.DidYouMean)
+			}
+		})
+	}
+}
+
+func TestQueryBooks_didYouMean(t *testing.T) {
+	speller := search.NewSpeller()
+	speller.AddVolumes([]model.GoogleBookItem{
+		{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}}},
+		{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}}},
+	})
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expected       []string
+	}{
+		{name: "qualifiers are kept", query: "author:gibson year:>1990 neuromancr", expectedStatus: http.StatusOK, expected: []string{"author:gibson year:>1990 Neuromancer"}},
+		{name: "phrases are kept", query: `"cuont zeor" -idoru`, expectedStatus: http.StatusOK, expected: []string{`"Count Zero" -idoru`}},
+		{name: "terms are kept", query: "cuont zeor lang:en", expectedStatus: http.StatusOK, expected: []string{"Count Zero lang:en"}},
+		{name: "qualifiers are not spelt", query: "author:gibsen", expectedStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/books?q="+url.QueryEscape(tt.query), nil)
+			w := httptest.NewRecorder()
+			r := chi.NewRouter()
+			BooksRouter(r, MockClient{}, nil, speller)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp QueryResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expected, resp.DidYouMean)
+			}
+		})
+	}
+}
+
+func TestQueryBooks(t *testing.T) {
+	mockItems := []model.GoogleBookItem{{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero"}}}
+	tests := []struct {
+		name           string
+		path           string
+		response       model.GoogleBookResponse
+		err            error
+		expectedStatus int
+		expectedError  *QueryErrorResponse
+	}{
+		{
+			name:           "GET:/books with a query",
+			path:           "/books?q=author:gibson+year:%3E1980+zero&limit=10",
+			response:       model.GoogleBookResponse{TotalItems: 1, Items: mockItems},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GET:/books with no results",
+			path:           "/books?q=zero",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "GET:/books with a parse error",
+			path:           "/books?q=zero+colour:blue",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  &QueryErrorResponse{Error: `unknown field "colour"`, Position: 5, End: 11},
+		},
+		{
+			name:           "GET:/books with an unterminated quote",
+			path:           "/books?q=%22count+zero",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  &QueryErrorResponse{Error: "unterminated quote", Position: 0, End: 11},
+		},
+		{
+			name:           "GET:/books without a query",
+			path:           "/books",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GET:/books with an invalid limit",
+			path:           "/books?q=zero&limit=100",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GET:/books with an invalid sort order",
+			path:           "/books?q=zero&sort=colour",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GET:/books with client error",
+			path:           "/books?q=zero",
+			err:            errors.New("test-error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r := setupBooksRouter(tt.response, tt.err)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp QueryResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "author:gibson year:>1980 zero", resp.Query)
+				assert.Equal(t, "count-zero", resp.Books[0].ID)
+			}
+			if tt.expectedError != nil {
+				var resp QueryErrorResponse
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, *tt.expectedError, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds each response cache. Once it is full the entry used
+// longest ago makes room for a new one.
+const maxCacheEntries = 1000
+
+// responseCache keeps computed responses for a fixed time so repeated
+// requests skip the upstream round trips. It holds at most size entries,
+// dropping the least recently used first. It is safe for concurrent use.
+type responseCache[V any] struct {
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+	mu   sync.Mutex
+	// recent holds the entries most recently used first, and entries each
+	// key's element in it.
+	recent  *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func newResponseCache[V any](ttl time.Duration) *responseCache[V] {
+	return &responseCache[V]{ttl: ttl, size: maxCacheEntries, now: time.Now, recent: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *responseCache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok || c.now().After(element.Value.(cacheEntry[V]).expires) {
+		if ok {
+			c.remove(element)
+		}
+		var zero V
+		return zero, false
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(cacheEntry[V]).value, true
+}
+
+// Set stores the value, dropping expired entries from the least recently
+// used end and then the least recently used entries while over size.
+func (c *responseCache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	entry := cacheEntry[V]{key: key, value: value, expires: now.Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+	} else {
+		c.entries[key] = c.recent.PushFront(entry)
+	}
+	for oldest := c.recent.Back(); oldest != nil && (c.recent.Len() > c.size || now.After(oldest.Value.(cacheEntry[V]).expires)); oldest = c.recent.Back() {
+		c.remove(oldest)
+	}
+}
+
+// Len returns the number of entries held, including any that have expired
+// but not yet been dropped.
+func (c *responseCache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recent.Len()
+}
+
+func (c *responseCache[V]) remove(element *list.Element) {
+	c.recent.Remove(element)
+	delete(c.entries, element.Value.(cacheEntry[V]).key)
+}
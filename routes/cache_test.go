@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newResponseCache[string](time.Minute)
+	cache.now = func() time.Time { return now }
+
+	_, ok := cache.Get("key")
+	assert.False(t, ok)
+
+	cache.Set("key", "value")
+	got, ok := cache.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "value", got)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+}
+
+func TestResponseCache_size(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newResponseCache[string](time.Minute)
+	cache.now = func() time.Time { return now }
+	cache.size = 2
+
+	cache.Set("neuromancer", "1984")
+	cache.Set("count-zero", "1986")
+	// Reading an entry keeps it over one that has not been used since
+	cache.Get("neuromancer")
+	cache.Set("mona-lisa", "1988")
+	assert.Equal(t, 2, cache.Len())
+	_, ok := cache.Get("count-zero")
+	assert.False(t, ok)
+	_, ok = cache.Get("neuromancer")
+	assert.True(t, ok)
+
+	// Expired entries are dropped when something new is stored
+	now = now.Add(2 * time.Minute)
+	cache.Set("idoru", "1996")
+	assert.Equal(t, 1, cache.Len())
+}
@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	client "example.com/book-learn/clients"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultCategoryLimit = 20
+	maxCategoryLimit     = 40
+)
+
+type CategoriesResponse struct {
+	Categories []CategoryNode `json:"categories"`
+}
+
+// CategoryNode is a taxonomy category with the number of works seen in it,
+// including works in its subcategories.
+type CategoryNode struct {
+	Slug       string         `json:"slug"`
+	Name       string         `json:"name"`
+	Count      int            `json:"count"`
+	Categories []CategoryNode `json:"categories,omitempty"`
+}
+
+type CategoryBooksResponse struct {
+	Slug       string         `json:"slug"`
+	Name       string         `json:"name"`
+	Path       []string       `json:"path"`
+	TotalItems int            `json:"totalItems"`
+	Start      int            `json:"start"`
+	Books      []BookResponse `json:"books"`
+}
+
+// CategoriesRouter serves the category taxonomy and browses the works in the
+// catalog filed under each category, a page at a time with start and limit.
+func CategoriesRouter(r chi.Router, catalog *client.Catalog) {
+	r.Get("/categories", listCategories(catalog))
+	r.Get("/categories/{slug}/books", browseCategory(catalog))
+}
+
+func listCategories(catalog *client.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		counts := catalog.CategoryCounts()
+		roots, children := categoryTree()
+
+		var node func(slug string) CategoryNode
+		node = func(slug string) CategoryNode {
+			category, _ := client.LookupCategory(slug)
+			n := CategoryNode{Slug: slug, Name: category.Name, Count: counts[slug]}
+			for _, child := range children[slug] {
+				n.Categories = append(n.Categories, node(child))
+			}
+			return n
+		}
+
+		var resp CategoriesResponse
+		for _, slug := range roots {
+			resp.Categories = append(resp.Categories, node(slug))
+		}
+
+		render(w, r, http.StatusOK, resp)
+	}
+}
+
+// categoryTree returns the top-level category slugs and the slugs of each
+// category's subcategories, in taxonomy order.
+func categoryTree() (roots []string, children map[string][]string) {
+	children = map[string][]string{}
+	for _, category := range client.Categories() {
+		if category.Parent == "" {
+			roots = append(roots, category.Slug)
+		} else {
+			children[category.Parent] = append(children[category.Parent], category.Slug)
+		}
+	}
+	return roots, children
+}
+
+func browseCategory(catalog *client.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		category, ok := client.LookupCategory(chi.URLParam(r, "slug"))
+		if !ok {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		start, limit := 0, defaultCategoryLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > maxCategoryLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxCategoryLimit), http.StatusBadRequest)
+				return
+			}
+		}
+		if value := r.URL.Query().Get("start"); value != "" {
+			var err error
+			start, err = strconv.Atoi(value)
+			if err != nil || start < 0 {
+				http.Error(w, "start must not be negative", http.StatusBadRequest)
+				return
+			}
+		}
+
+		books, total := catalog.Category(category.Slug, start, limit)
+		// No results
+		if total == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Format response
+		resp := CategoryBooksResponse{
+			Slug:       category.Slug,
+			Name:       category.Name,
+			Path:       category.Path,
+			TotalItems: total,
+			Start:      start,
+			Books:      []BookResponse{},
+		}
+		for _, book := range books {
+			var br BookResponse
+			br.fromItem(book)
+			resp.Books = append(resp.Books, br)
+		}
+
+		render(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCategoriesRouter(items ...model.GoogleBookItem) http.Handler {
+	r := chi.NewRouter()
+	catalog := client.NewCatalog()
+	catalog.Add(items...)
+	CategoriesRouter(r, catalog)
+	return r
+}
+
+func TestCategoriesRouter(t *testing.T) {
+	r := setupCategoriesRouter(
+		model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Categories: []string{"Cyberpunk fiction"}}},
+	)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "GET:/categories", path: "/categories", expectedStatus: http.StatusOK},
+		{name: "GET:/categories/{slug}/books with books", path: "/categories/science-fiction/books", expectedStatus: http.StatusOK},
+		{name: "GET:/categories/{slug}/books without books", path: "/categories/poetry/books", expectedStatus: http.StatusNoContent},
+		{name: "GET:/categories/{slug}/books with an unknown slug", path: "/categories/missing/books", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.NotEmpty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCategoriesRouter_tree(t *testing.T) {
+	r := setupCategoriesRouter(
+		model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Categories: []string{"Cyberpunk fiction"}}},
+	)
+	req, _ := http.NewRequest("GET", "/categories", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp CategoriesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	fiction := resp.Categories[0]
+	assert.Equal(t, "fiction", fiction.Slug)
+	assert.Equal(t, 1, fiction.Count)
+	assert.Equal(t, "science-fiction", fiction.Categories[0].Slug)
+	assert.Equal(t, 1, fiction.Categories[0].Count)
+	assert.Equal(t, "cyberpunk", fiction.Categories[0].Categories[0].Slug)
+}
+
+func TestCategoriesRouter_books(t *testing.T) {
+	r := setupCategoriesRouter(
+		model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Categories: []string{"Cyberpunk fiction"}}},
+	)
+	req, _ := http.NewRequest("GET", "/categories/fiction/books", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp CategoryBooksResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"fiction"}, resp.Path)
+	assert.Equal(t, 1, resp.TotalItems)
+	assert.Equal(t, "cyberpunk", resp.Books[0].NormalizedCategories[0].Slug)
+}
+
+func TestCategoriesRouter_booksPaged(t *testing.T) {
+	r := setupCategoriesRouter(
+		model.GoogleBookItem{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Categories: []string{"Cyberpunk fiction"}}},
+		model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Categories: []string{"Cyberpunk fiction"}}},
+		model.GoogleBookItem{ID: "dune", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Dune", Categories: []string{"Space opera"}}},
+	)
+
+	req, _ := http.NewRequest("GET", "/categories/science-fiction/books?start=1&limit=1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp CategoryBooksResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 3, resp.TotalItems)
+	assert.Equal(t, 1, resp.Start)
+	assert.Len(t, resp.Books, 1)
+	assert.Equal(t, "count-zero", resp.Books[0].ID)
+
+	for _, path := range []string{
+		"/categories/science-fiction/books?limit=0",
+		"/categories/science-fiction/books?limit=41",
+		"/categories/science-fiction/books?start=-1",
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
@@ -0,0 +1,307 @@
+package routes
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	model "example.com/book-learn/models"
+	"example.com/book-learn/search"
+)
+
+const (
+	bibtexMediaType = "application/x-bibtex"
+	risMediaType    = "application/x-research-info-systems"
+	cslMediaType    = "application/vnd.citationstyles.csl+json"
+)
+
+// citationSource is implemented by responses holding a single book, which
+// can be cited on its own as well as in a list.
+type citationSource interface {
+	citationBooks() []BookResponse
+}
+
+func (br BookResponse) citationBooks() []BookResponse { return []BookResponse{br} }
+
+// isbn returns the book's ISBN-13, or its ISBN-10 when that is all it has.
+func (br BookResponse) isbn() string {
+	for _, kind := range []string{"ISBN_13", "ISBN_10"} {
+		for _, id := range br.IndustryIdentifiers {
+			if id.Type == kind {
+				return id.Identifier
+			}
+		}
+	}
+	return ""
+}
+
+// fullTitle joins the title and subtitle the way citations print them.
+func (br BookResponse) fullTitle() string {
+	if br.Subtitle == "" {
+		return br.Title
+	}
+	return br.Title + ": " + br.Subtitle
+}
+
+var citationKeyStopWords = []string{"a", "an", "the", "of", "on", "in"}
+
+// citationKeys makes a key such as "gibson1986count" for each book, from the
+// first author's family name, the year and the first significant word of
+// the title. Keys that would repeat within the list get a, b, c... appended.
+func citationKeys(books []BookResponse) []string {
+	keys := make([]string, len(books))
+	seen := map[string]int{}
+	for i, book := range books {
+		key := ""
+		if len(book.Authors) > 0 {
+			key = asciiWord(model.ParsePersonName(book.Authors[0]).Family)
+		}
+		if key == "" {
+			key = "anon"
+		}
+		if year := book.PublishedDateNormalized.ExactYear(); year != 0 {
+			key += strconv.Itoa(year)
+		}
+		for _, word := range search.Tokenize(book.Title) {
+			if word = asciiWord(word); word != "" && !slices.Contains(citationKeyStopWords, word) {
+				key += word
+				break
+			}
+		}
+		keys[i] = key
+		seen[key]++
+	}
+
+	suffixed := map[string]int{}
+	for i, key := range keys {
+		if seen[key] > 1 {
+			n := suffixed[key]
+			suffixed[key]++
+			if n < 26 {
+				keys[i] = key + string(rune('a'+n))
+			} else {
+				keys[i] = key + strconv.Itoa(n)
+			}
+		}
+	}
+	return keys
+}
+
+// asciiWord folds text to the lowercase ASCII letters and digits that are
+// safe in a citation key.
+func asciiWord(text string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.Join(search.Tokenize(text), ""))
+}
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+// writeBibTeX writes one @book entry per book. Values are braced with the
+// characters TeX treats specially escaped.
+func writeBibTeX(w io.Writer, books []BookResponse) error {
+	keys := citationKeys(books)
+	for i, book := range books {
+		authors := []string{}
+		for _, author := range book.Authors {
+			person := model.ParsePersonName(bibtexEscaper.Replace(author))
+			switch {
+			case person.Given == "":
+				// Braced so BibTeX does not read it as a given name
+				authors = append(authors, "{"+person.Family+"}")
+			case person.Suffix != "":
+				authors = append(authors, person.Family+", "+person.Suffix+", "+person.Given)
+			default:
+				authors = append(authors, person.Family+", "+person.Given)
+			}
+		}
+		fields := [][2]string{
+			{"title", book.fullTitle()},
+			{"author", strings.Join(authors, " and ")},
+			{"publisher", book.Publisher},
+			{"year", optionalInt(book.PublishedDateNormalized.ExactYear())},
+			{"month", optionalInt(book.PublishedDateNormalized.Month)},
+			{"date", bibtexDateRange(book.PublishedDateNormalized)},
+			{"isbn", book.isbn()},
+			{"pagetotal", optionalInt(book.PageCount)},
+			{"language", book.Language},
+			{"keywords", strings.Join(book.Categories, ", ")},
+			{"url", book.InfoLink},
+			{"abstract", strings.Join(strings.Fields(book.Description), " ")},
+		}
+		if _, err := fmt.Fprintf(w, "@book{%s,\n", keys[i]); err != nil {
+			return err
+		}
+		for _, field := range fields {
+			if field[1] == "" {
+				continue
+			}
+			// Authors are escaped as they are joined, URLs are verbatim
+			value := field[1]
+			if field[0] != "author" && field[0] != "url" {
+				value = bibtexEscaper.Replace(value)
+			}
+			if _, err := fmt.Fprintf(w, "  %s = {%s},\n", field[0], value); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "}\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRIS writes one TY/ER record per book. RIS has no escaping, so line
+// breaks inside values are folded into spaces to keep one tag per line.
+func writeRIS(w io.Writer, books []BookResponse) error {
+	for _, book := range books {
+		lines := [][2]string{{"TY", "BOOK"}, {"ID", book.ID}, {"TI", book.Title}, {"T2", book.Subtitle}}
+		for _, author := range book.Authors {
+			person := model.ParsePersonName(author)
+			name := person.Family
+			if person.Given != "" {
+				name += ", " + person.Given
+			}
+			if person.Suffix != "" {
+				name += ", " + person.Suffix
+			}
+			lines = append(lines, [2]string{"AU", name})
+		}
+		lines = append(lines,
+			[2]string{"PY", optionalInt(book.PublishedDateNormalized.ExactYear())},
+			[2]string{"DA", risDate(book.PublishedDateNormalized)},
+			[2]string{"PB", book.Publisher},
+			[2]string{"SN", book.isbn()},
+			[2]string{"SP", optionalInt(book.PageCount)},
+			[2]string{"LA", book.Language},
+		)
+		for _, category := range book.Categories {
+			lines = append(lines, [2]string{"KW", category})
+		}
+		lines = append(lines, [2]string{"AB", book.Description}, [2]string{"UR", book.InfoLink}, [2]string{"ER", ""})
+
+		for _, line := range lines {
+			value := strings.Join(strings.Fields(line[1]), " ")
+			if value == "" && line[0] != "ER" {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "%s  - %s\r\n", line[0], value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bibtexDateRange writes a decade or century as a biblatex date range such
+// as "1980/1989", since year only holds exact years.
+func bibtexDateRange(date model.PublicationDate) string {
+	if date.IsZero() || date.ExactYear() != 0 {
+		return ""
+	}
+	return fmt.Sprintf("%04d/%04d", date.Start().Year(), date.End().Year())
+}
+
+// risDate formats a date as YYYY/MM/DD/, leaving out the parts not known.
+// RIS has no form for a decade or century, so those are left out.
+func risDate(date model.PublicationDate) string {
+	if date.ExactYear() == 0 {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("%04d", date.Year), "", "", ""}
+	if date.Month != 0 {
+		parts[1] = fmt.Sprintf("%02d", date.Month)
+	}
+	if date.Day != 0 {
+		parts[2] = fmt.Sprintf("%02d", date.Day)
+	}
+	return strings.Join(parts, "/")
+}
+
+// CSLItem is a book as a CSL-JSON item, the input format of citeproc
+// processors and reference managers such as Zotero.
+type CSLItem struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	CitationKey   string    `json:"citation-key"`
+	Title         string    `json:"title"`
+	Author        []CSLName `json:"author,omitempty"`
+	Issued        *CSLDate  `json:"issued,omitempty"`
+	Publisher     string    `json:"publisher,omitempty"`
+	ISBN          string    `json:"ISBN,omitempty"`
+	NumberOfPages string    `json:"number-of-pages,omitempty"`
+	Language      string    `json:"language,omitempty"`
+	Abstract      string    `json:"abstract,omitempty"`
+	URL           string    `json:"URL,omitempty"`
+}
+
+type CSLName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Suffix  string `json:"suffix,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type CSLDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// writeCSL writes the books as a CSL-JSON array.
+func writeCSL(w io.Writer, books []BookResponse) error {
+	keys := citationKeys(books)
+	items := []CSLItem{}
+	for i, book := range books {
+		item := CSLItem{
+			ID:            book.ID,
+			Type:          "book",
+			CitationKey:   keys[i],
+			Title:         book.fullTitle(),
+			Publisher:     book.Publisher,
+			ISBN:          book.isbn(),
+			NumberOfPages: optionalInt(book.PageCount),
+			Language:      book.Language,
+			Abstract:      book.Description,
+			URL:           book.InfoLink,
+		}
+		for _, author := range book.Authors {
+			person := model.ParsePersonName(author)
+			if person.Given == "" {
+				item.Author = append(item.Author, CSLName{Literal: person.Family})
+				continue
+			}
+			item.Author = append(item.Author, CSLName{Family: person.Family, Given: person.Given, Suffix: person.Suffix})
+		}
+		// A decade or century is issued as a range of years
+		if date := book.PublishedDateNormalized; date.ExactYear() == 0 && !date.IsZero() {
+			item.Issued = &CSLDate{DateParts: [][]int{{date.Start().Year()}, {date.End().Year()}}}
+		} else if !date.IsZero() {
+			parts := []int{date.Year}
+			if date.Month != 0 {
+				parts = append(parts, date.Month)
+				if date.Day != 0 {
+					parts = append(parts, date.Day)
+				}
+			}
+			item.Issued = &CSLDate{DateParts: [][]int{parts}}
+		}
+		items = append(items, item)
+	}
+	return writeJSON(w, items)
+}
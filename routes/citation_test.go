@@ -0,0 +1,245 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func citationBooks() []BookResponse {
+	return []BookResponse{
+		{
+			ID:                      "id-1",
+			Title:                   "Count Zero",
+			Subtitle:                "A Novel",
+			Authors:                 []string{"William Gibson"},
+			Publisher:               "Ace & Sons",
+			PublishedDate:           "1986-03-15",
+			PublishedDateNormalized: model.ParsePublicationDate("1986-03-15"),
+			IndustryIdentifiers: []BookIndustryIdentifier{
+				{Type: "ISBN_10", Identifier: "0441117732"},
+				{Type: "ISBN_13", Identifier: "9780441117734"},
+			},
+			PageCount:   256,
+			Categories:  []string{"Fiction"},
+			Language:    "en",
+			Description: "50% cyberpunk, {braces} and_underscores.\nSecond line.",
+			InfoLink:    "https://books.example/count_zero?id=1&x=2",
+		},
+		{
+			ID:                      "id-2",
+			Title:                   "The Difference Engine",
+			Authors:                 []string{"William Gibson", "Bruce Sterling"},
+			PublishedDate:           "1986",
+			PublishedDateNormalized: model.ParsePublicationDate("1986"),
+		},
+		{
+			ID:      "id-3",
+			Title:   "Odyssey",
+			Authors: []string{"Homer"},
+		},
+	}
+}
+
+func TestCitationKeys(t *testing.T) {
+	books := citationBooks()
+	books = append(books,
+		BookResponse{Title: "Count Zero", Authors: []string{"William Gibson"}, PublishedDateNormalized: model.ParsePublicationDate("1986")},
+		BookResponse{Title: "天空"},
+		BookResponse{Title: "Ørsted", Authors: []string{"Søren Ærø"}},
+	)
+	assert.Equal(t, []string{"gibson1986counta", "gibson1986difference", "homerodyssey", "gibson1986countb", "anon", "aeroorsted"}, citationKeys(books))
+}
+
+func TestWriteBibTeX(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeBibTeX(&buf, citationBooks()))
+
+	want := `@book{gibson1986count,
+  title = {Count Zero: A Novel},
+  author = {Gibson, William},
+  publisher = {Ace \& Sons},
+  year = {1986},
+  month = {3},
+  isbn = {9780441117734},
+  pagetotal = {256},
+  language = {en},
+  keywords = {Fiction},
+  url = {https://books.example/count_zero?id=1&x=2},
+  abstract = {50\% cyberpunk, \{braces\} and\_underscores. Second line.},
+}
+
+@book{gibson1986difference,
+  title = {The Difference Engine},
+  author = {Gibson, William and Sterling, Bruce},
+  year = {1986},
+}
+
+@book{homerodyssey,
+  title = {Odyssey},
+  author = {{Homer}},
+}
+
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestWriteRIS(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeRIS(&buf, citationBooks()[:2]))
+
+	want := strings.Join([]string{
+		"TY  - BOOK",
+		"ID  - id-1",
+		"TI  - Count Zero",
+		"T2  - A Novel",
+		"AU  - Gibson, William",
+		"PY  - 1986",
+		"DA  - 1986/03/15/",
+		"PB  - Ace & Sons",
+		"SN  - 9780441117734",
+		"SP  - 256",
+		"LA  - en",
+		"KW  - Fiction",
+		"AB  - 50% cyberpunk, {braces} and_underscores. Second line.",
+		"UR  - https://books.example/count_zero?id=1&x=2",
+		"ER  - ",
+		"TY  - BOOK",
+		"ID  - id-2",
+		"TI  - The Difference Engine",
+		"AU  - Gibson, William",
+		"AU  - Sterling, Bruce",
+		"PY  - 1986",
+		"DA  - 1986///",
+		"ER  - ",
+	}, "\r\n") + "\r\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestWriteCSL(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeCSL(&buf, citationBooks()))
+
+	var items []CSLItem
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &items))
+	assert.Len(t, items, 3)
+	assert.Equal(t, CSLItem{
+		ID:            "id-1",
+		Type:          "book",
+		CitationKey:   "gibson1986count",
+		Title:         "Count Zero: A Novel",
+		Author:        []CSLName{{Family: "Gibson", Given: "William"}},
+		Issued:        &CSLDate{DateParts: [][]int{{1986, 3, 15}}},
+		Publisher:     "Ace & Sons",
+		ISBN:          "9780441117734",
+		NumberOfPages: "256",
+		Language:      "en",
+		Abstract:      "50% cyberpunk, {braces} and_underscores.\nSecond line.",
+		URL:           "https://books.example/count_zero?id=1&x=2",
+	}, items[0])
+	assert.Equal(t, &CSLDate{DateParts: [][]int{{1986}}}, items[1].Issued)
+	assert.Equal(t, []CSLName{{Literal: "Homer"}}, items[2].Author)
+	assert.Nil(t, items[2].Issued)
+	assert.Contains(t, buf.String(), `"date-parts"`)
+}
+
+func TestRender_citations(t *testing.T) {
+	items := []model.GoogleBookItem{
+		{ID: "id-1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}, PublishedDate: "1986"}},
+	}
+	body, _ := json.Marshal(client.GoogleBookRequest{Title: "test-title"})
+
+	tests := []struct {
+		name            string
+		query           string
+		accept          string
+		wantContentType string
+		wantPrefix      string
+	}{
+		{name: "bibtex by accept", accept: bibtexMediaType, wantContentType: bibtexMediaType + "; charset=utf-8", wantPrefix: "@book{gibson1986count,"},
+		{name: "ris by format", query: "?format=ris", wantContentType: risMediaType + "; charset=utf-8", wantPrefix: "TY  - BOOK\r\n"},
+		{name: "csl by accept", accept: cslMediaType, wantContentType: cslMediaType, wantPrefix: "[\n  {\n    \"id\": \"id-1\""},
+		{name: "csl by format", query: "?format=csl-json", wantContentType: cslMediaType, wantPrefix: "["},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupBooksRouter(model.GoogleBookResponse{TotalItems: len(items), Items: items}, nil)
+			req := httptest.NewRequest(http.MethodPost, "/books/title"+tt.query, bytes.NewReader(body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			assert.True(t, strings.HasPrefix(rec.Body.String(), tt.wantPrefix), rec.Body.String())
+		})
+	}
+}
+
+func TestBookByID_citations(t *testing.T) {
+	items := []model.GoogleBookItem{
+		{ID: "id-1", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}, PublishedDate: "1986"}},
+	}
+	router := setupBooksRouter(model.GoogleBookResponse{TotalItems: len(items), Items: items}, nil)
+
+	tests := []struct {
+		name            string
+		query           string
+		accept          string
+		wantContentType string
+		wantPrefix      string
+	}{
+		{name: "bibtex by format", query: "?format=bibtex", wantContentType: bibtexMediaType + "; charset=utf-8", wantPrefix: "@book{gibson1986count,"},
+		{name: "ris by accept", accept: risMediaType, wantContentType: risMediaType + "; charset=utf-8", wantPrefix: "TY  - BOOK\r\nID  - id-1\r\n"},
+		{name: "csl by accept", accept: cslMediaType, wantContentType: cslMediaType, wantPrefix: "[\n  {\n    \"id\": \"id-1\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/books/id-1"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			assert.True(t, strings.HasPrefix(rec.Body.String(), tt.wantPrefix), rec.Body.String())
+		})
+	}
+}
+
+func TestCitations_impreciseDates(t *testing.T) {
+	books := []BookResponse{
+		{ID: "id-1", Title: "Neuromancer", Authors: []string{"William Gibson"}, PublishedDateNormalized: model.ParsePublicationDate("198*")},
+		{ID: "id-2", Title: "Odyssey", Authors: []string{"Homer"}, PublishedDateNormalized: model.ParsePublicationDate("18??")},
+	}
+	assert.Equal(t, []string{"gibsonneuromancer", "homerodyssey"}, citationKeys(books))
+
+	var bibtex bytes.Buffer
+	assert.NoError(t, writeBibTeX(&bibtex, books))
+	assert.NotContains(t, bibtex.String(), "year =")
+	assert.Contains(t, bibtex.String(), "  date = {1980/1989},\n")
+	assert.Contains(t, bibtex.String(), "  date = {1800/1899},\n")
+
+	var ris bytes.Buffer
+	assert.NoError(t, writeRIS(&ris, books))
+	assert.NotContains(t, ris.String(), "PY  -")
+	assert.NotContains(t, ris.String(), "DA  -")
+
+	var csl bytes.Buffer
+	assert.NoError(t, writeCSL(&csl, books))
+	var items []CSLItem
+	assert.NoError(t, json.Unmarshal(csl.Bytes(), &items))
+	assert.Equal(t, &CSLDate{DateParts: [][]int{{1980}, {1989}}}, items[0].Issued)
+	assert.Equal(t, &CSLDate{DateParts: [][]int{{1800}, {1899}}}, items[1].Issued)
+}
@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	client "example.com/book-learn/clients"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	eventStreamMediaType = "text/event-stream"
+)
+
+// Server-sent event names for an author search, in the order they can occur.
+const (
+	EventPageFetched   = "page-fetched"
+	EventItem          = "item"
+	EventFilteredCount = "filtered-count"
+	EventDone          = "done"
+)
+
+type PageFetchedEvent struct {
+	Page       int    `json:"page"`
+	Start      int    `json:"start"`
+	Items      int    `json:"items"`
+	TotalItems int    `json:"totalItems"`
+	Error      string `json:"error,omitempty"`
+}
+
+type FilteredCountEvent struct {
+	Page          int `json:"page"`
+	Filtered      int `json:"filtered"`
+	TotalFiltered int `json:"totalFiltered"`
+}
+
+// AuthorEventsRouter serves the author search as server-sent events so a UI
+// can render deep crawls progressively. It is a GET so browsers can use
+// EventSource.
+func AuthorEventsRouter(r chi.Router, api client.BookClientInterface) {
+	r.Get("/books/author/events", authorEvents(api))
+}
+
+func authorEvents(bookClient client.BookClientInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse the query string
+		bookReq, err := authorRequestFromQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := bookReq.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", eventStreamMediaType)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		events := &eventWriter{w: w}
+		events.flusher, _ = w.(http.Flusher)
+
+		done := AuthorStreamTrailer{Trailer: true, Author: bookReq.Author}
+		sent := map[string]bool{}
+		var errs []error
+		pages := client.StreamAuthorPages(r.Context(), bookClient, bookReq)
+		for {
+			var page client.AuthorPage
+			var ok bool
+			select {
+			case <-r.Context().Done():
+				// The client went away, stop writing
+				return
+			case page, ok = <-pages:
+			}
+			if !ok {
+				break
+			}
+
+			fetched := PageFetchedEvent{
+				Page:       page.Index,
+				Start:      bookReq.Start + page.Index*bookReq.Limit,
+				Items:      len(page.Response.Items),
+				TotalItems: page.Response.TotalItems,
+			}
+			if page.Err != nil {
+				errs = append(errs, page.Err)
+				fetched.Error = page.Err.Error()
+			}
+			if done.TotalItems == 0 {
+				done.TotalItems = page.Response.TotalItems
+			}
+			if err := events.send(EventPageFetched, fetched); err != nil {
+				slog.Error(err.Error())
+				return
+			}
+			for _, br := range newPageBooks(page.Response, sent, bookReq.Debug) {
+				if err := events.send(EventItem, br); err != nil {
+					slog.Error(err.Error())
+					return
+				}
+				done.Streamed++
+			}
+			done.Filtered += page.Response.Filtered
+			filtered := FilteredCountEvent{Page: page.Index, Filtered: page.Response.Filtered, TotalFiltered: done.Filtered}
+			if err := events.send(EventFilteredCount, filtered); err != nil {
+				slog.Error(err.Error())
+				return
+			}
+		}
+
+		done.HasMorePages = client.HasMorePages(done.TotalItems, bookReq)
+		if err := errors.Join(errs...); err != nil {
+			slog.Error(err.Error())
+			done.Error = err.Error()
+		}
+		if err := events.send(EventDone, done); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
+
+// authorRequestFromQuery reads an author search from query parameters named
+// like the fields of the POST body.
+func authorRequestFromQuery(r *http.Request) (client.GoogleBookRequest, error) {
+	query := r.URL.Query()
+	bookReq := client.GoogleBookRequest{
+		Author:          query.Get("author"),
+		PublishedAfter:  query.Get("publishedAfter"),
+		PublishedBefore: query.Get("publishedBefore"),
+		SortBy:          query.Get("sortBy"),
+		Limit:           defaultAuthorLimit,
+		Debug:           query.Get("debug") == "true",
+	}
+	if bookReq.Author == "" {
+		return bookReq, errors.New("author is required")
+	}
+	numbers := []struct {
+		name  string
+		field *int
+	}{
+		{name: "start", field: &bookReq.Start},
+		{name: "limit", field: &bookReq.Limit},
+		{name: "pages", field: &bookReq.Pages},
+	}
+	for _, number := range numbers {
+		if value := query.Get(number.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return bookReq, fmt.Errorf("%s must be a number of at least 0", number.name)
+			}
+			*number.field = n
+		}
+	}
+	return bookReq, checkAuthorPages(bookReq)
+}
+
+// eventWriter writes server-sent events, numbering them so a client can tell
+// where a dropped stream stopped.
+type eventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	id      int
+}
+
+func (e *eventWriter) send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	e.id++
+	if _, err := fmt.Fprintf(e.w, "id: %d\nevent: %s\ndata: %s\n\n", e.id, event, payload); err != nil {
+		return err
+	}
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type serverSentEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+func readEvents(body string) []serverSentEvent {
+	events := []serverSentEvent{}
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event serverSentEvent
+		for _, line := range strings.Split(block, "\n") {
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				event.ID = value
+			case "event":
+				event.Event = value
+			case "data":
+				event.Data = value
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestAuthorEventsRouter(t *testing.T) {
+	r := chi.NewRouter()
+	AuthorEventsRouter(r, ErrorPageMockClient{PagedMockClient{Pages: authorPages()}})
+
+	req, _ := http.NewRequest("GET", "/books/author/events?author=test-author&limit=2&pages=1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, eventStreamMediaType, w.Header().Get("Content-Type"))
+	events := readEvents(w.Body.String())
+	names := []string{}
+	for i, event := range events {
+		names = append(names, event.Event)
+		assert.Equal(t, strconv.Itoa(i+1), event.ID)
+	}
+	// The failing second page can arrive before or after the first
+	assert.Contains(t, [][]string{
+		{EventPageFetched, EventItem, EventItem, EventFilteredCount, EventPageFetched, EventFilteredCount, EventDone},
+		{EventPageFetched, EventFilteredCount, EventPageFetched, EventItem, EventItem, EventFilteredCount, EventDone},
+	}, names)
+
+	var done AuthorStreamTrailer
+	assert.NoError(t, json.Unmarshal([]byte(events[len(events)-1].Data), &done))
+	assert.Equal(t, AuthorStreamTrailer{Trailer: true, Author: "test-author", TotalItems: 5, Streamed: 2, HasMorePages: true, Error: "test-error"}, done)
+	for _, event := range events {
+		if event.Event == EventPageFetched {
+			var fetched PageFetchedEvent
+			assert.NoError(t, json.Unmarshal([]byte(event.Data), &fetched))
+			assert.Equal(t, fetched.Page*2, fetched.Start)
+		}
+	}
+}
+
+func TestAuthorEventsRouter_filteredCount(t *testing.T) {
+	pages := authorPages()
+	first := pages[0]
+	first.Filtered = 3
+	pages[0] = first
+	second := pages[2]
+	second.Filtered = 1
+	pages[2] = second
+	r := chi.NewRouter()
+	AuthorEventsRouter(r, PagedMockClient{Pages: pages})
+
+	req, _ := http.NewRequest("GET", "/books/author/events?author=test-author&limit=2&pages=1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	totals := []int{}
+	for _, event := range readEvents(w.Body.String()) {
+		if event.Event == EventFilteredCount {
+			var filtered FilteredCountEvent
+			assert.NoError(t, json.Unmarshal([]byte(event.Data), &filtered))
+			totals = append(totals, filtered.TotalFiltered)
+		}
+		if event.Event == EventDone {
+			var done AuthorStreamTrailer
+			assert.NoError(t, json.Unmarshal([]byte(event.Data), &done))
+			assert.Equal(t, 4, done.Filtered)
+			assert.Equal(t, 3, done.Streamed)
+		}
+	}
+	assert.Len(t, totals, 2)
+	assert.Equal(t, 4, totals[1])
+}
+
+func TestAuthorEventsRouter_clientDisconnects(t *testing.T) {
+	// The second page never arrives, so only a disconnect ends the stream
+	cli := GatedMockClient{PagedMockClient: PagedMockClient{Pages: authorPages()}, release: make(chan struct{})}
+	defer close(cli.release)
+	r := chi.NewRouter()
+	AuthorEventsRouter(r, cli)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/books/author/events?author=test-author&limit=2&pages=1", nil)
+	w := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, req)
+		close(finished)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("stream kept running after the client disconnected")
+	}
+	for _, event := range readEvents(w.Body.String()) {
+		assert.NotEqual(t, EventDone, event.Event)
+	}
+}
+
+func TestAuthorEventsRouter_invalidRequest(t *testing.T) {
+	r := chi.NewRouter()
+	AuthorEventsRouter(r, PagedMockClient{})
+
+	for _, path := range []string{
+		"/books/author/events",
+		"/books/author/events?author=test-author&pages=lots",
+		"/books/author/events?author=test-author&publishedAfter=not-a-date",
+		"/books/author/events?author=test-author&limit=0",
+		"/books/author/events?author=test-author&limit=41",
+		"/books/author/events?author=test-author&pages=100000",
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/opds"
+	"example.com/book-learn/syndication"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	authorFeedSize     = 20
+	authorFeedCacheTTL = 30 * time.Minute
+	// authorFeedTagPrefix starts the tag: URI (RFC 4151) identifying an
+	// author's feed, which must not change with the host or proxy.
+	authorFeedTagPrefix = "tag:book-learn.example.com,2024:authors/"
+)
+
+// authorFeedHandler serves an author's newest books as an Atom or RSS feed.
+// Feeds are cached for authorFeedCacheTTL and answer conditional requests
+// through ETag and Last-Modified, so readers polling an unchanged feed get a
+// 304.
+func authorFeedHandler(bookClient client.BookClientInterface, feeds *responseCache[[]model.GoogleBookItem], mediaType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := url.PathUnescape(chi.URLParam(r, "name"))
+		if err != nil || strings.TrimSpace(name) == "" {
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+
+		books, ok := feeds.Get(name)
+		if !ok {
+			books, err = fetchAuthorFeed(r, bookClient, name)
+			if err != nil {
+				slog.Error(err.Error())
+				http.Error(w, "", http.StatusInternalServerError)
+				return
+			}
+			// Hits must not extend the entry, or a reader polling more often
+			// than the TTL would never see new books
+			if len(books) > 0 {
+				feeds.Set(name, books)
+			}
+		}
+
+		// Unknown author
+		if len(books) == 0 {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		feed := newAuthorFeed(r, name, books)
+		var body bytes.Buffer
+		if mediaType == syndication.RSSMediaType {
+			err = feed.WriteRSS(&body)
+		} else {
+			err = feed.WriteAtom(&body)
+		}
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		sum := sha256.Sum256(body.Bytes())
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(authorFeedCacheTTL.Seconds())))
+		// ServeContent answers If-None-Match and If-Modified-Since. Books
+		// announced for a later date must not make the feed modified in the
+		// future.
+		lastModified := feed.Updated
+		if now := time.Now(); lastModified.After(now) {
+			lastModified = now
+		}
+		http.ServeContent(w, r, "", lastModified, bytes.NewReader(body.Bytes()))
+	}
+}
+
+// fetchAuthorFeed asks Google for the author's newest books and keeps those
+// with a publication date, which entries need, newest first.
+func fetchAuthorFeed(r *http.Request, bookClient client.BookClientInterface, name string) ([]model.GoogleBookItem, error) {
+	books, err := bookClient.ByAuthor(r.Context(), client.GoogleBookRequest{
+		Author: name,
+		Limit:  maxQueryLimit,
+		SortBy: client.SortPublishedDesc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client.SortItems(books.Items, client.SortPublishedDesc)
+
+	dated := []model.GoogleBookItem{}
+	for _, book := range books.Items {
+		if model.ParsePublicationDate(book.VolumeInfo.PublishedDate).IsZero() {
+			continue
+		}
+		dated = append(dated, book)
+		if len(dated) == authorFeedSize {
+			break
+		}
+	}
+	return dated, nil
+}
+
+// newAuthorFeed dates each entry by its publication, so an entry only looks
+// new to a reader when the book is, and the feed by its newest entry.
+func newAuthorFeed(r *http.Request, name string, books []model.GoogleBookItem) syndication.Feed {
+	self := requestOrigin(r) + r.URL.EscapedPath()
+	profile := self[:strings.LastIndex(self, "/")]
+	feed := syndication.Feed{
+		ID:          authorFeedID(name),
+		Title:       "New books by " + name,
+		Description: "The newest books by " + name + ".",
+		Author:      name,
+		Self:        self,
+		Link:        profile,
+		TTL:         authorFeedCacheTTL,
+	}
+	for _, book := range books {
+		info := book.VolumeInfo
+		entry := syndication.Entry{
+			ID:         opds.EntryID(book.ID),
+			Title:      info.Title,
+			Link:       info.CanonicalVolumeLink,
+			Updated:    model.ParsePublicationDate(info.PublishedDate).Start(),
+			Authors:    info.Authors,
+			Summary:    info.Description,
+			Categories: info.Categories,
+		}
+		if info.Subtitle != "" {
+			entry.Title += ": " + info.Subtitle
+		}
+		if entry.Link == "" {
+			entry.Link = info.InfoLink
+		}
+		if entry.Link == "" {
+			entry.Link = entry.ID
+		}
+		if entry.Updated.After(feed.Updated) {
+			feed.Updated = entry.Updated
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// authorFeedID identifies an author's feed by the author alone, so readers
+// see the same feed whichever host or scheme they subscribed through.
+func authorFeedID(name string) string {
+	return authorFeedTagPrefix + url.PathEscape(name) + "/feed"
+}
+
+// requestOrigin is the scheme and host the client used to reach the service,
+// for links that must be absolute.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,184 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/syndication"
+	"github.com/go-chi/chi/v5"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorFeed(t *testing.T) {
+	book := func(id string, title string, date string) model.GoogleBookItem {
+		return model.GoogleBookItem{
+			ID: id,
+			VolumeInfo: model.GoogleBookVolumeInfo{
+				Title:               title,
+				Authors:             []string{"William Gibson"},
+				PublishedDate:       date,
+				CanonicalVolumeLink: "https://books.example/" + id,
+			},
+		}
+	}
+	cli := CountingMockClient{
+		calls: &atomic.Int32{},
+		PagedMockClient: PagedMockClient{
+			Pages: map[int]model.GoogleBookResponse{
+				0: {TotalItems: 4, Items: []model.GoogleBookItem{
+					book("neuromancer", "Neuromancer", "1984-07-01"),
+					book("agency", "Agency", "2020-01-21"),
+					book("undated", "Burning Chrome", ""),
+					book("peripheral", "The Peripheral", "2014-10"),
+				}},
+			},
+		},
+	}
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		AuthorsRouter(r, cli, client.NewAuthorGraph())
+	})
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("atom", func(t *testing.T) {
+		w := get("/api/authors/William%20Gibson/feed.atom", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "public, max-age=1800", w.Header().Get("Cache-Control"))
+		assert.Equal(t, "Tue, 21 Jan 2020 00:00:00 GMT", w.Header().Get("Last-Modified"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+
+		feed, err := gofeed.NewParser().ParseString(w.Body.String())
+		assert.NoError(t, err)
+		assert.Equal(t, "atom", feed.FeedType)
+		assert.Equal(t, "New books by William Gibson", feed.Title)
+		assert.Equal(t, "http://example.com/api/authors/William%20Gibson/feed.atom", feed.FeedLink)
+		assert.Equal(t, "http://example.com/api/authors/William%20Gibson", feed.Link)
+		// Newest first, without the undated volume
+		var ids []string
+		for _, item := range feed.Items {
+			ids = append(ids, item.GUID)
+		}
+		assert.Equal(t, []string{
+			"https://books.google.com/books?id=agency",
+			"https://books.google.com/books?id=peripheral",
+			"https://books.google.com/books?id=neuromancer",
+		}, ids)
+		assert.Equal(t, "https://books.example/agency", feed.Items[0].Link)
+		assert.Equal(t, "2014-10-01T00:00:00Z", feed.Items[1].Updated)
+	})
+
+	t.Run("stable id", func(t *testing.T) {
+		const id = "<id>tag:book-learn.example.com,2024:authors/William%20Gibson/feed</id>"
+		assert.Contains(t, get("/api/authors/William%20Gibson/feed.atom", nil).Body.String(), id)
+		// The feed keeps its ID behind another host or a TLS proxy
+		w := get("/api/authors/William%20Gibson/feed.atom", http.Header{"X-Forwarded-Proto": {"https"}})
+		assert.Contains(t, w.Body.String(), id)
+	})
+
+	t.Run("rss", func(t *testing.T) {
+		w := get("/api/authors/William%20Gibson/feed.rss", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/rss+xml; charset=utf-8", w.Header().Get("Content-Type"))
+
+		feed, err := gofeed.NewParser().ParseString(w.Body.String())
+		assert.NoError(t, err)
+		assert.Equal(t, "rss", feed.FeedType)
+		assert.Len(t, feed.Items, 3)
+		assert.Equal(t, "https://books.google.com/books?id=agency", feed.Items[0].GUID)
+		assert.Equal(t, "Tue, 21 Jan 2020 00:00:00 +0000", feed.Items[0].Published)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		// Both formats and every request above share one upstream fetch
+		assert.Equal(t, int32(1), cli.calls.Load())
+	})
+
+	t.Run("if-none-match", func(t *testing.T) {
+		etag := get("/api/authors/William%20Gibson/feed.atom", nil).Header().Get("ETag")
+		w := get("/api/authors/William%20Gibson/feed.atom", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		// The RSS document is a different representation
+		w = get("/api/authors/William%20Gibson/feed.rss", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("if-modified-since", func(t *testing.T) {
+		w := get("/api/authors/William%20Gibson/feed.rss", http.Header{"If-Modified-Since": {"Wed, 22 Jan 2020 00:00:00 GMT"}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		w = get("/api/authors/William%20Gibson/feed.rss", http.Header{"If-Modified-Since": {"Mon, 20 Jan 2020 00:00:00 GMT"}})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAuthorFeed_expires(t *testing.T) {
+	cli := CountingMockClient{
+		calls: &atomic.Int32{},
+		PagedMockClient: PagedMockClient{Pages: map[int]model.GoogleBookResponse{
+			0: {TotalItems: 1, Items: []model.GoogleBookItem{{ID: "agency", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Agency", PublishedDate: "2020-01-21"}}}},
+		}},
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feeds := newResponseCache[[]model.GoogleBookItem](authorFeedCacheTTL)
+	feeds.now = func() time.Time { return now }
+	r := chi.NewRouter()
+	r.Get("/authors/{name}/feed.atom", authorFeedHandler(cli, feeds, syndication.AtomMediaType))
+
+	get := func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/authors/William%20Gibson/feed.atom", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	get()
+	// A reader polling within the TTL does not keep the entry alive
+	now = now.Add(authorFeedCacheTTL / 2)
+	get()
+	assert.Equal(t, int32(1), cli.calls.Load())
+	now = now.Add(authorFeedCacheTTL/2 + time.Second)
+	get()
+	assert.Equal(t, int32(2), cli.calls.Load())
+}
+
+func TestAuthorFeed_errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		response       model.GoogleBookResponse
+		err            error
+		expectedStatus int
+	}{
+		{name: "unknown author", expectedStatus: http.StatusNotFound},
+		{name: "no dated books", response: model.GoogleBookResponse{TotalItems: 1, Items: []model.GoogleBookItem{{ID: "undated"}}}, expectedStatus: http.StatusNotFound},
+		{name: "client error", err: errors.New("test-error"), expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			AuthorsRouter(r, MockClient{Response: tt.response, Err: tt.err}, client.NewAuthorGraph())
+			for _, path := range []string{"/authors/Nobody/feed.atom", "/authors/Nobody/feed.rss"} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"example.com/book-learn/graph"
+	"github.com/go-chi/chi/v5"
+)
+
+// GraphQLRouter serves the GraphQL schema over HTTP. Queries can be sent as
+// a JSON body or, for caching, as GET parameters with variables as JSON.
+func GraphQLRouter(r chi.Router, schema *graph.Schema) {
+	r.Get("/graphql", graphQL(schema))
+	r.Post("/graphql", graphQL(schema))
+}
+
+func graphQL(schema *graph.Schema) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse the request
+		var req graph.Request
+		if r.Method == http.MethodGet {
+			query := r.URL.Query()
+			req.Query = query.Get("query")
+			req.OperationName = query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					http.Error(w, "variables must be a JSON object", http.StatusBadRequest)
+					return
+				}
+			}
+		} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+
+		result := schema.Execute(r.Context(), req)
+
+		// Requests that could not run have no data
+		status := http.StatusOK
+		if result.Data == nil {
+			status = http.StatusBadRequest
+		}
+		w.Header().Set("Content-Type", jsonMediaType)
+		w.WriteHeader(status)
+		if err := writeJSON(w, result); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
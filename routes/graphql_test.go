@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	"example.com/book-learn/graph"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/recommend"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQLRouter(t *testing.T) {
+	catalog := client.NewCatalog()
+	authorGraph := client.NewAuthorGraph()
+	api := MockClient{Response: model.GoogleBookResponse{TotalItems: 1, Items: []model.GoogleBookItem{cyberpunkBook(1)}}}
+	schema, err := graph.New(api, catalog, authorGraph, recommend.New(catalog, authorGraph))
+	assert.NoError(t, err)
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		GraphQLRouter(r, schema)
+	})
+
+	query := `query ($first: Int) { search(query: "gibson", first: $first) { totalCount nodes { title authors { name } } } }`
+	body, _ := json.Marshal(graph.Request{Query: query, Variables: map[string]any{"first": 5}})
+	get := url.Values{"query": {query}, "variables": {`{"first": 5}`}}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "post", method: http.MethodPost, path: "/api/graphql", body: string(body), expectedStatus: http.StatusOK, expectedBody: `"title": "Book 01"`},
+		{name: "get", method: http.MethodGet, path: "/api/graphql?" + get.Encode(), expectedStatus: http.StatusOK, expectedBody: `"name": "William Gibson"`},
+		{name: "bad body", method: http.MethodPost, path: "/api/graphql", body: "{", expectedStatus: http.StatusBadRequest},
+		{name: "bad variables", method: http.MethodGet, path: "/api/graphql?query={categories{slug}}&variables=[", expectedStatus: http.StatusBadRequest},
+		{name: "invalid query", method: http.MethodPost, path: "/api/graphql", body: `{"query": "{ shelves { id } }"}`, expectedStatus: http.StatusBadRequest, expectedBody: `Cannot query field \"shelves\" on type \"Query\".`},
+		{name: "field error", method: http.MethodPost, path: "/api/graphql", body: `{"query": "{ search(query: \"gibson\", first: 0) { totalCount } }"}`, expectedStatus: http.StatusOK, expectedBody: `"search": null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, jsonMediaType, w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+}
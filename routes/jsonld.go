@@ -0,0 +1,169 @@
+package routes
+
+import (
+	model "example.com/book-learn/models"
+)
+
+const (
+	jsonLDMediaType = "application/ld+json"
+	// maxOpenGraphDescription is about what link previews show before they
+	// cut the text off themselves.
+	maxOpenGraphDescription = 300
+)
+
+// jsonLDContext uses schema.org as the vocabulary and adds the Open Graph
+// prefixes, so the og: and book: meta fields expand to their ogp.me terms.
+var jsonLDContext = []any{
+	"https://schema.org",
+	map[string]string{"og": "https://ogp.me/ns#", "book": "https://ogp.me/ns/book#"},
+}
+
+// LinkedBook is a schema.org Book. The detail page embeds it as a JSON-LD
+// script and turns each og: and book: field into a meta tag, one tag for
+// each value of a list.
+type LinkedBook struct {
+	Context             any                 `json:"@context,omitempty"`
+	Type                string              `json:"@type"`
+	ID                  string              `json:"@id,omitempty"`
+	URL                 string              `json:"url,omitempty"`
+	Name                string              `json:"name"`
+	AlternativeHeadline string              `json:"alternativeHeadline,omitempty"`
+	Authors             []LinkedPerson      `json:"author,omitempty"`
+	Publisher           *LinkedOrganization `json:"publisher,omitempty"`
+	DatePublished       string              `json:"datePublished,omitempty"`
+	ISBN                string              `json:"isbn,omitempty"`
+	NumberOfPages       int                 `json:"numberOfPages,omitempty"`
+	InLanguage          string              `json:"inLanguage,omitempty"`
+	Genre               []string            `json:"genre,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	Image               string              `json:"image,omitempty"`
+	IsPartOf            *LinkedSeries       `json:"isPartOf,omitempty"`
+	Position            int                 `json:"position,omitempty"`
+	WorkExample         []LinkedBook        `json:"workExample,omitempty"`
+	*OpenGraph
+}
+
+type LinkedPerson struct {
+	Type            string `json:"@type"`
+	Name            string `json:"name"`
+	GivenName       string `json:"givenName,omitempty"`
+	FamilyName      string `json:"familyName,omitempty"`
+	HonorificSuffix string `json:"honorificSuffix,omitempty"`
+}
+
+type LinkedOrganization struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type LinkedSeries struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// OpenGraph holds the meta fields link previews read, from the og: and
+// book: namespaces.
+type OpenGraph struct {
+	Type        string   `json:"og:type"`
+	Title       string   `json:"og:title"`
+	Description string   `json:"og:description,omitempty"`
+	URL         string   `json:"og:url,omitempty"`
+	Image       string   `json:"og:image,omitempty"`
+	Authors     []string `json:"book:author,omitempty"`
+	ISBN        string   `json:"book:isbn,omitempty"`
+	ReleaseDate string   `json:"book:release_date,omitempty"`
+	Tags        []string `json:"book:tag,omitempty"`
+}
+
+// jsonLDSource is implemented by responses that describe a single book for
+// search engines.
+type jsonLDSource interface {
+	linkedData() LinkedBook
+}
+
+// linkedData describes the book as a work. When other editions are known,
+// each edition, this one first, is listed as a workExample. The work has its
+// own @id, as JSON-LD would merge it with the edition sharing its URL.
+func (br BookResponse) linkedData() LinkedBook {
+	book := linkedEdition(br)
+	if book.ID != "" {
+		book.ID += "#work"
+	}
+	book.Context = jsonLDContext
+	book.AlternativeHeadline = br.Subtitle
+	for _, author := range br.Authors {
+		book.Authors = append(book.Authors, linkedPerson(author))
+	}
+	book.Genre = br.Categories
+	book.Description = br.Description
+	if br.Series != nil && br.Series.Title != "" {
+		book.IsPartOf = &LinkedSeries{Type: "BookSeries", Name: br.Series.Title}
+		book.Position = br.Series.Position
+	}
+	if len(br.Editions) > 0 {
+		book.WorkExample = append(book.WorkExample, linkedEdition(br))
+		for _, edition := range br.Editions {
+			book.WorkExample = append(book.WorkExample, linkedEdition(edition))
+		}
+	}
+	book.OpenGraph = &OpenGraph{
+		Type:        "book",
+		Title:       br.fullTitle(),
+		Description: truncateText(marcText(br.Description), maxOpenGraphDescription),
+		URL:         book.URL,
+		Image:       book.Image,
+		Authors:     br.Authors,
+		ISBN:        book.ISBN,
+		ReleaseDate: book.DatePublished,
+		Tags:        br.Categories,
+	}
+	return book
+}
+
+// linkedEdition holds what differs between editions of the same work.
+func linkedEdition(br BookResponse) LinkedBook {
+	book := LinkedBook{
+		Type:          "Book",
+		ID:            bookURL(br),
+		URL:           bookURL(br),
+		Name:          br.Title,
+		DatePublished: isoDate(br.PublishedDateNormalized),
+		ISBN:          br.isbn(),
+		NumberOfPages: br.PageCount,
+		InLanguage:    br.Language,
+		Image:         br.ImageLinks.Thumbnail,
+	}
+	if br.Publisher != "" {
+		book.Publisher = &LinkedOrganization{Type: "Organization", Name: br.Publisher}
+	}
+	return book
+}
+
+func linkedPerson(name string) LinkedPerson {
+	parsed := model.ParsePersonName(name)
+	return LinkedPerson{
+		Type:            "Person",
+		Name:            name,
+		GivenName:       parsed.Given,
+		FamilyName:      parsed.Family,
+		HonorificSuffix: parsed.Suffix,
+	}
+}
+
+// bookURL is the public page for the volume, preferring the canonical link.
+func bookURL(br BookResponse) string {
+	if br.CanonicalVolumeLink != "" {
+		return br.CanonicalVolumeLink
+	}
+	return br.InfoLink
+}
+
+// isoDate formats the date as ISO 8601 to its precision, leaving out decades
+// and centuries, which ISO 8601 dates cannot express.
+func isoDate(date model.PublicationDate) string {
+	switch date.Precision {
+	case model.DatePrecisionYear, model.DatePrecisionMonth, model.DatePrecisionDay:
+		return date.String()
+	}
+	return ""
+}
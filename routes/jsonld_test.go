@@ -0,0 +1,153 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkedData(t *testing.T) {
+	book := BookResponse{
+		ID:                      "VJvQDSqL3f8C",
+		Title:                   "Count Zero",
+		Subtitle:                "Sprawl Trilogy 2",
+		Authors:                 []string{"William Gibson", "Homer"},
+		Publisher:               "Ace",
+		PublishedDate:           "1987-04",
+		PublishedDateNormalized: model.ParsePublicationDate("1987-04"),
+		Description:             "Turner wakes up.\nEverything changes.",
+		IndustryIdentifiers:     []BookIndustryIdentifier{{Type: "ISBN_10", Identifier: "0441117732"}, {Type: "ISBN_13", Identifier: "9780441117734"}},
+		PageCount:               256,
+		Categories:              []string{"Fiction"},
+		ImageLinks:              BookImageLinks{Thumbnail: "http://books.example/cover"},
+		Language:                "en",
+		InfoLink:                "http://books.example/info",
+		CanonicalVolumeLink:     "https://books.example/VJvQDSqL3f8C",
+		Series:                  &model.Series{ID: "sprawl", Title: "Sprawl", Position: 2},
+	}
+	linked := book.linkedData()
+
+	assert.Equal(t, jsonLDContext, linked.Context)
+	assert.Equal(t, "Book", linked.Type)
+	assert.Equal(t, "https://books.example/VJvQDSqL3f8C#work", linked.ID)
+	assert.Equal(t, "https://books.example/VJvQDSqL3f8C", linked.URL)
+	assert.Equal(t, "Sprawl Trilogy 2", linked.AlternativeHeadline)
+	assert.Equal(t, []LinkedPerson{
+		{Type: "Person", Name: "William Gibson", GivenName: "William", FamilyName: "Gibson"},
+		{Type: "Person", Name: "Homer", FamilyName: "Homer"},
+	}, linked.Authors)
+	assert.Equal(t, &LinkedOrganization{Type: "Organization", Name: "Ace"}, linked.Publisher)
+	assert.Equal(t, "1987-04", linked.DatePublished)
+	assert.Equal(t, "9780441117734", linked.ISBN)
+	assert.Equal(t, &LinkedSeries{Type: "BookSeries", Name: "Sprawl"}, linked.IsPartOf)
+	assert.Equal(t, 2, linked.Position)
+
+	assert.Empty(t, linked.WorkExample)
+
+	assert.Equal(t, &OpenGraph{
+		Type:        "book",
+		Title:       "Count Zero: Sprawl Trilogy 2",
+		Description: "Turner wakes up. Everything changes.",
+		URL:         "https://books.example/VJvQDSqL3f8C",
+		Image:       "http://books.example/cover",
+		Authors:     []string{"William Gibson", "Homer"},
+		ISBN:        "9780441117734",
+		ReleaseDate: "1987-04",
+		Tags:        []string{"Fiction"},
+	}, linked.OpenGraph)
+}
+
+func TestLinkedData_sparseVolume(t *testing.T) {
+	linked := BookResponse{ID: "id", Title: "Untitled", Description: strings.Repeat("word ", 100)}.linkedData()
+
+	assert.Empty(t, linked.ID)
+	assert.Nil(t, linked.Publisher)
+	assert.Nil(t, linked.IsPartOf)
+	assert.Empty(t, linked.WorkExample)
+	assert.LessOrEqual(t, len(linked.OpenGraph.Description), maxOpenGraphDescription)
+}
+
+func TestBookByID_jsonLD(t *testing.T) {
+	item := marcItem()
+	item.VolumeInfo.Description = "</script><script>alert(1)</script>"
+	router := setupBooksRouter(model.GoogleBookResponse{Items: []model.GoogleBookItem{item}}, nil)
+
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{name: "accept header", path: "/books/hNgmLwEACAAJ", accept: "text/html;q=0.9, application/ld+json"},
+		{name: "format", path: "/books/hNgmLwEACAAJ?format=jsonld"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, jsonLDMediaType, rec.Header().Get("Content-Type"))
+			assert.NotContains(t, rec.Body.String(), "</script>")
+
+			var doc map[string]any
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+			assert.Equal(t, []any{"https://schema.org", map[string]any{"og": "https://ogp.me/ns#", "book": "https://ogp.me/ns/book#"}}, doc["@context"])
+			assert.Equal(t, "Book", doc["@type"])
+			assert.Equal(t, "The Difference Engine", doc["name"])
+			assert.Equal(t, "book", doc["og:type"])
+			assert.Equal(t, "The Difference Engine: A Novel", doc["og:title"])
+			assert.Equal(t, []any{"William Gibson", "Bruce Sterling"}, doc["book:author"])
+		})
+	}
+}
+
+func TestBookByID_jsonLDEditions(t *testing.T) {
+	edition := func(id string, title string, date string) model.GoogleBookItem {
+		return model.GoogleBookItem{ID: id, VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:               title,
+			Authors:             []string{"William Gibson"},
+			PublishedDate:       date,
+			CanonicalVolumeLink: "https://books.example/" + id,
+		}}
+	}
+	catalog := client.NewCatalog()
+	catalog.Add(edition("count-zero", "Count Zero", "1986"), edition("count-zero-reissue", "Count Zero", "2006"), edition("idoru", "Idoru", "1996"))
+	r := chi.NewRouter()
+	BooksRouter(r, MockClient{Response: model.GoogleBookResponse{Items: []model.GoogleBookItem{edition("count-zero", "Count Zero", "1986")}}}, catalog, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books/count-zero", nil)
+	req.Header.Set("Accept", jsonLDMediaType)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var linked LinkedBook
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &linked))
+	assert.Equal(t, "https://books.example/count-zero#work", linked.ID)
+	ids := []string{}
+	for _, example := range linked.WorkExample {
+		ids = append(ids, example.ID)
+	}
+	// The requested edition comes first and none shares the work's @id
+	assert.Equal(t, []string{"https://books.example/count-zero", "https://books.example/count-zero-reissue"}, ids)
+}
+
+func TestRender_jsonLDNotOffered(t *testing.T) {
+	router := setupBooksRouter(model.GoogleBookResponse{TotalItems: 1, Items: []model.GoogleBookItem{marcItem()}}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/books?q=gibson", nil)
+	req.Header.Set("Accept", jsonLDMediaType)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, jsonMediaType, rec.Header().Get("Content-Type"))
+}
@@ -0,0 +1,210 @@
+package routes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"example.com/book-learn/marc"
+	model "example.com/book-learn/models"
+)
+
+const (
+	marcXMLMediaType = "application/marcxml+xml"
+	marcMediaType    = "application/marc"
+	// maxMARCDescription keeps the 520 summary within ISO 2709's limit of
+	// 9999 bytes a field.
+	maxMARCDescription = 8000
+)
+
+// marcSource is implemented by responses that can be handed to library
+// systems as MARC 21 records.
+type marcSource interface {
+	marcBooks() []BookResponse
+}
+
+func (br BookResponse) marcBooks() []BookResponse { return []BookResponse{br} }
+
+// marcBooks returns the books found by successful lookups, each once.
+func (resp BatchResponse) marcBooks() []BookResponse {
+	books := []BookResponse{}
+	seen := map[string]bool{}
+	for _, result := range resp.Results {
+		for _, book := range result.Books {
+			if !seen[book.ID] {
+				seen[book.ID] = true
+				books = append(books, book)
+			}
+		}
+	}
+	return books
+}
+
+// marcRecord describes a volume as a MARC 21 bibliographic record with ISBD
+// punctuation, so it can be loaded by a library system as it is.
+func marcRecord(book BookResponse) marc.Record {
+	record := marc.NewRecord()
+	record.Add(marc.ControlField("001", book.ID), marc.ControlField("008", marcFixedData(book)))
+
+	for _, id := range book.IndustryIdentifiers {
+		if id.Type == "ISBN_13" || id.Type == "ISBN_10" {
+			record.Add(marc.DataField("020", ' ', ' ', subfield('a', id.Identifier)))
+		}
+	}
+
+	people := []model.PersonName{}
+	for _, author := range book.Authors {
+		people = append(people, model.ParsePersonName(marcText(author)))
+	}
+	for i, person := range people {
+		tag := "700"
+		if i == 0 {
+			tag = "100"
+		}
+		ind1 := byte('1')
+		name := person.Family
+		if person.Given == "" {
+			// A forename only, such as Homer
+			ind1 = '0'
+		} else {
+			name += ", " + person.Given
+		}
+		if person.Suffix != "" {
+			name += ", " + person.Suffix
+		}
+		record.Add(marc.DataField(tag, ind1, ' ', subfield('a', name+","), subfield('e', "author.")))
+	}
+
+	title, subtitle := marcText(book.Title), marcText(book.Subtitle)
+	statement := strings.Join(book.Authors, ", ")
+	switch {
+	case subtitle != "":
+		title += " :"
+	case statement != "":
+		title += " /"
+	}
+	if subtitle != "" && statement != "" {
+		subtitle += " /"
+	}
+	if statement != "" {
+		statement = marcText(statement) + "."
+	}
+	titleAdded := byte('0')
+	if len(people) > 0 {
+		titleAdded = '1'
+	}
+	record.Add(marc.DataField("245", titleAdded, nonfilingCharacters(book.Title),
+		subfield('a', title), subfield('b', subtitle), subfield('c', statement)))
+
+	publisher := marcText(book.Publisher)
+	_, year := marcDates(book.PublishedDateNormalized)
+	if publisher != "" && year != "" {
+		publisher += ","
+	}
+	record.Add(marc.DataField("264", ' ', '1', subfield('b', publisher), subfield('c', year)))
+
+	if book.PageCount > 0 {
+		record.Add(marc.DataField("300", ' ', ' ', subfield('a', fmt.Sprintf("%d pages", book.PageCount))))
+	}
+	record.Add(marc.DataField("520", ' ', ' ', subfield('a', truncateText(marcText(book.Description), maxMARCDescription))))
+
+	// Google categories such as "Fiction / Science Fiction / General" become
+	// a heading with its subdivisions, from no particular thesaurus.
+	for _, category := range book.Categories {
+		parts := strings.Split(category, "/")
+		heading := marc.DataField("650", ' ', '4', subfield('a', marcText(parts[0])))
+		for _, part := range parts[1:] {
+			heading.Subfields = append(heading.Subfields, subfield('x', marcText(part)))
+		}
+		record.Add(heading)
+	}
+	return record
+}
+
+func marcRecords(books []BookResponse) []marc.Record {
+	records := []marc.Record{}
+	for _, book := range books {
+		records = append(records, marcRecord(book))
+	}
+	return records
+}
+
+// marcFixedData builds the 40 character 008 field for a book.
+func marcFixedData(book BookResponse) string {
+	// Date entered, dates, place, book details, language, source
+	data := []byte("||||||" + "nuuuu    " + "xx " + "           000 0 " + "und" + " d")
+	if date1, _ := marcDates(book.PublishedDateNormalized); date1 != "" {
+		copy(data[6:], "s"+date1)
+	}
+	literaryForm := byte('0')
+	if slices.ContainsFunc(book.Categories, func(category string) bool {
+		return strings.HasPrefix(strings.ToLower(category), "fiction")
+	}) {
+		literaryForm = '1'
+	}
+	data[33] = literaryForm
+	if language := model.BibliographicLanguage(book.Language); language != "" {
+		copy(data[35:], language)
+	}
+	return string(data)
+}
+
+// marcDates formats a date for 008 Date 1 and for 264 $c. Unknown digits of
+// a decade or century are "u" in 008 and "-" in a bracketed, supplied 264
+// date, so "198*" becomes "198u" and "[198-]".
+func marcDates(date model.PublicationDate) (string, string) {
+	if date.IsZero() || date.Year <= 0 || date.Year >= 10000 {
+		return "", ""
+	}
+	year := fmt.Sprintf("%04d", date.Year)
+	switch date.Precision {
+	case model.DatePrecisionCentury:
+		return year[:2] + "uu", "[" + year[:2] + "--]"
+	case model.DatePrecisionDecade:
+		return year[:3] + "u", "[" + year[:3] + "-]"
+	}
+	return year, year
+}
+
+// nonfilingCharacters is the 245 second indicator, the number of leading
+// characters of an article that filing should skip.
+func nonfilingCharacters(title string) byte {
+	for _, article := range []string{"The ", "An ", "A "} {
+		if strings.HasPrefix(title, article) {
+			return byte('0' + len(article))
+		}
+	}
+	return '0'
+}
+
+// marcText folds line breaks and strips control characters, which would
+// otherwise clash with the ISO 2709 delimiters.
+func marcText(text string) string {
+	return strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text)), " ")
+}
+
+// truncateText shortens text to at most limit bytes, at a word boundary.
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := strings.LastIndex(text[:limit-len("...")], " ")
+	if cut < 0 {
+		cut = limit - len("...")
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return text[:cut] + "..."
+}
+
+func subfield(code byte, value string) marc.Subfield {
+	return marc.Subfield{Code: code, Value: value}
+}
@@ -0,0 +1,214 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	"example.com/book-learn/marc"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func marcItem() model.GoogleBookItem {
+	return model.GoogleBookItem{
+		ID: "hNgmLwEACAAJ",
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:         "The Difference Engine",
+			Subtitle:      "A Novel",
+			Authors:       []string{"William Gibson", "Bruce Sterling"},
+			Publisher:     "Bantam",
+			PublishedDate: "1991-03",
+			Description:   "Victorian London,\nrunning on\x1fsteam.",
+			IndustryIdentifiers: []model.GoogleBookIndustryIdentifier{
+				{Type: "ISBN_10", Identifier: "0553290967"},
+				{Type: "OTHER", Identifier: "OCLC:22710930"},
+			},
+			PageCount:  429,
+			Categories: []string{"Fiction / Science Fiction / Steampunk"},
+			Language:   "en",
+		},
+	}
+}
+
+func TestMarcRecord(t *testing.T) {
+	var book BookResponse
+	book.fromItem(marcItem())
+	record := marcRecord(book)
+
+	assert.Equal(t, "hNgmLwEACAAJ", record.Get("001")[0].Value)
+	fixed := record.Get("008")[0].Value
+	assert.Len(t, fixed, 40)
+	assert.Equal(t, "s1991", fixed[6:11])
+	assert.Equal(t, "1", fixed[33:34])
+	assert.Equal(t, "eng", fixed[35:38])
+
+	tags := []string{}
+	for _, field := range record.Fields {
+		tags = append(tags, field.Tag)
+	}
+	assert.Equal(t, []string{"001", "008", "020", "100", "700", "245", "264", "300", "520", "650"}, tags)
+
+	assert.Equal(t, []marc.Subfield{{Code: 'a', Value: "0553290967"}}, record.Get("020")[0].Subfields)
+	assert.Equal(t, marc.DataField("100", '1', ' ', subfield('a', "Gibson, William,"), subfield('e', "author.")), record.Get("100")[0])
+	assert.Equal(t, marc.DataField("700", '1', ' ', subfield('a', "Sterling, Bruce,"), subfield('e', "author.")), record.Get("700")[0])
+	assert.Equal(t, marc.DataField("245", '1', '4',
+		subfield('a', "The Difference Engine :"),
+		subfield('b', "A Novel /"),
+		subfield('c', "William Gibson, Bruce Sterling.")), record.Get("245")[0])
+	assert.Equal(t, marc.DataField("264", ' ', '1', subfield('b', "Bantam,"), subfield('c', "1991")), record.Get("264")[0])
+	assert.Equal(t, "429 pages", record.Get("300")[0].Subfield('a'))
+	assert.Equal(t, "Victorian London, running on steam.", record.Get("520")[0].Subfield('a'))
+	assert.Equal(t, marc.DataField("650", ' ', '4',
+		subfield('a', "Fiction"), subfield('x', "Science Fiction"), subfield('x', "Steampunk")), record.Get("650")[0])
+}
+
+func TestMarcRecord_sparseVolume(t *testing.T) {
+	record := marcRecord(BookResponse{ID: "id", Title: "Odyssey", Authors: []string{"Homer"}, Description: strings.Repeat("word ", 3000)})
+
+	assert.Equal(t, "nuuuu", record.Get("008")[0].Value[6:11])
+	assert.Equal(t, "und", record.Get("008")[0].Value[35:38])
+	assert.Equal(t, marc.DataField("100", '0', ' ', subfield('a', "Homer,"), subfield('e', "author.")), record.Get("100")[0])
+	assert.Equal(t, marc.DataField("245", '1', '0', subfield('a', "Odyssey /"), subfield('c', "Homer.")), record.Get("245")[0])
+	assert.Empty(t, record.Get("264"))
+	assert.Empty(t, record.Get("300"))
+	assert.LessOrEqual(t, len(record.Get("520")[0].Subfield('a')), maxMARCDescription)
+
+	_, err := record.MarshalBinary()
+	assert.NoError(t, err)
+}
+
+func TestMarcRecord_imprecise(t *testing.T) {
+	tests := []struct {
+		date      string
+		wantDate1 string
+		want264   string
+	}{
+		{date: "198*", wantDate1: "s198u", want264: "[198-]"},
+		{date: "18??", wantDate1: "s18uu", want264: "[18--]"},
+		{date: "1984-07", wantDate1: "s1984", want264: "1984"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			record := marcRecord(BookResponse{ID: "id", Title: "Neuromancer", Authors: []string{"William Gibson"},
+				Publisher: "Ace", PublishedDateNormalized: model.ParsePublicationDate(tt.date)})
+
+			assert.Equal(t, tt.wantDate1, record.Get("008")[0].Value[6:11])
+			assert.Equal(t, marc.DataField("264", ' ', '1', subfield('b', "Ace,"), subfield('c', tt.want264)), record.Get("264")[0])
+		})
+	}
+}
+
+func TestBookByID_marc(t *testing.T) {
+	item := marcItem()
+	var book BookResponse
+	book.fromItem(item)
+	want := marcRecord(book)
+	router := setupBooksRouter(model.GoogleBookResponse{Items: []model.GoogleBookItem{item}}, nil)
+
+	get := func(path string, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("binary round trip", func(t *testing.T) {
+		rec := get("/books/hNgmLwEACAAJ", marcMediaType)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, marcMediaType, rec.Header().Get("Content-Type"))
+		records, err := marc.ReadBinary(rec.Body.Bytes())
+		assert.NoError(t, err)
+		assert.Len(t, records, 1)
+		assert.Equal(t, want.Fields, records[0].Fields)
+	})
+
+	t.Run("xml round trip", func(t *testing.T) {
+		rec := get("/books/hNgmLwEACAAJ?format=marcxml", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, marcXMLMediaType, rec.Header().Get("Content-Type"))
+		records, err := marc.ReadXML(rec.Body)
+		assert.NoError(t, err)
+		assert.Len(t, records, 1)
+		assert.Equal(t, want, records[0])
+	})
+
+	t.Run("json by default", func(t *testing.T) {
+		rec := get("/books/hNgmLwEACAAJ", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp BookResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "Bantam", resp.Publisher)
+		assert.Equal(t, []BookIndustryIdentifier{{Type: "ISBN_10", Identifier: "0553290967"}, {Type: "OTHER", Identifier: "OCLC:22710930"}}, resp.IndustryIdentifiers)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/books/missing", marcMediaType).Code)
+	})
+}
+
+func TestBookByID_marcRecordTooLong(t *testing.T) {
+	item := marcItem()
+	item.VolumeInfo.Title = strings.Repeat("Steam ", 2000)
+	router := setupBooksRouter(model.GoogleBookResponse{Items: []model.GoogleBookItem{item}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books/hNgmLwEACAAJ", nil)
+	req.Header.Set("Accept", marcMediaType)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// Nothing is written before the record is known to fit
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotEqual(t, marcMediaType, rec.Header().Get("Content-Type"))
+	_, err := marc.ReadBinary(rec.Body.Bytes())
+	assert.Error(t, err)
+}
+
+func TestBookByID_error(t *testing.T) {
+	router := setupBooksRouter(model.GoogleBookResponse{}, errors.New("test-error"))
+	req := httptest.NewRequest(http.MethodGet, "/books/id", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestBatchLookup_marc(t *testing.T) {
+	router := setupBatchRouter(BatchMockClient{calls: &atomic.Int32{}})
+	body, _ := json.Marshal(BatchRequest{Lookups: []BatchLookup{
+		{Title: "Count Zero"},
+		{Title: "unknown"},
+		{ISBN: "9780441117734"},
+		{Title: "count zero "},
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/books/batch", bytes.NewReader(body))
+	req.Header.Set("Accept", marcMediaType)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	records, err := marc.ReadBinary(rec.Body.Bytes())
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, record := range records {
+		ids = append(ids, record.Get("001")[0].Value)
+	}
+	assert.Equal(t, []string{"by-title", "by-query"}, ids)
+}
+
+func TestRender_marcNotOffered(t *testing.T) {
+	router := setupBooksRouter(model.GoogleBookResponse{TotalItems: 1, Items: []model.GoogleBookItem{marcItem()}}, nil)
+	body, _ := json.Marshal(client.GoogleBookRequest{Title: "test-title"})
+	req := httptest.NewRequest(http.MethodPost, "/books/title?format=marc", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, jsonMediaType, rec.Header().Get("Content-Type"))
+}
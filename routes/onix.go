@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	client "example.com/book-learn/clients"
+	"example.com/book-learn/onix"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	onixMediaType = "application/xml"
+	onixSender    = "book-learn"
+)
+
+// OnixRouter serves search results as ONIX 3.0 messages for retail
+// partners. The feed takes the same q, sort, limit and start parameters as
+// GET /books?q=.
+func OnixRouter(r chi.Router, api client.BookClientInterface) {
+	r.Get("/feeds/onix", onixFeed(api, time.Now))
+}
+
+func onixFeed(bookClient client.BookClientInterface, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bookReq, ok := parseQueryRequest(w, r)
+		if !ok {
+			return
+		}
+
+		// Fetch data from external API
+		books, err := bookClient.ByQuery(r.Context(), bookReq)
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		// No results
+		if len(books.Items) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", onixMediaType+"; charset=utf-8")
+		if err := onix.NewMessage(onixSender, now().UTC(), books.Items).Write(w); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	model "example.com/book-learn/models"
+	"example.com/book-learn/onix"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnixFeed(t *testing.T) {
+	sent := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		path           string
+		response       model.GoogleBookResponse
+		err            error
+		expectedStatus int
+	}{
+		{name: "feed", path: "/feeds/onix?q=author:gibson", response: model.GoogleBookResponse{TotalItems: 1, Items: []model.GoogleBookItem{marcItem()}}, expectedStatus: http.StatusOK},
+		{name: "no results", path: "/feeds/onix?q=author:nobody", expectedStatus: http.StatusNoContent},
+		{name: "missing query", path: "/feeds/onix", expectedStatus: http.StatusBadRequest},
+		{name: "bad limit", path: "/feeds/onix?q=gibson&limit=100", expectedStatus: http.StatusBadRequest},
+		{name: "syntax error", path: `/feeds/onix?q="gibson`, expectedStatus: http.StatusBadRequest},
+		{name: "client error", path: "/feeds/onix?q=gibson", err: errors.New("test-error"), expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Get("/feeds/onix", onixFeed(MockClient{Response: tt.response, Err: tt.err}, func() time.Time { return sent }))
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "application/xml; charset=utf-8", rec.Header().Get("Content-Type"))
+			var message onix.Message
+			assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &message))
+			assert.Equal(t, "20260301T0930Z", message.Header.SentDateTime)
+			assert.Len(t, message.Products, 1)
+			assert.Equal(t, "com.google.books.hNgmLwEACAAJ", message.Products[0].RecordReference)
+		})
+	}
+}
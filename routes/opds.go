@@ -0,0 +1,358 @@
+package routes
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/opds"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	opdsPageSize = 20
+	opdsTitle    = "book-learn"
+	// opdsFormat is the ?format= value that asks for OPDS 2.0. Links in a
+	// JSON feed carry it so the client stays on JSON.
+	opdsFormat = "opds2"
+)
+
+// OPDSRouter serves the catalog to e-reader apps as OPDS 1.2 Atom or, when
+// asked for application/opds+json, OPDS 2.0. The start feed lists the
+// categories, leaf categories and authors are acquisition feeds, and search
+// takes the query language of /books through OpenSearch. Empty pages are
+// still feeds, as readers expect, rather than 204s.
+func OPDSRouter(r chi.Router, api client.BookClientInterface, catalog *client.Catalog) {
+	r.Get("/opds", opdsStart(catalog, time.Now))
+	r.Get("/opds/categories/{slug}", opdsCategory(catalog, time.Now))
+	r.Get("/opds/categories/{slug}/books", opdsCategoryBooks(catalog, time.Now))
+	r.Get("/opds/authors/{name}", opdsAuthor(api, time.Now))
+	r.Get("/opds/search", opdsSearch(api, time.Now))
+	r.Get("/opds/opensearch.xml", opdsOpenSearch())
+}
+
+// opdsLinks builds absolute links to the catalog's own feeds, in the format
+// the client negotiated.
+type opdsLinks struct {
+	prefix string
+	json   bool
+}
+
+func newOPDSLinks(r *http.Request, mediaType string) opdsLinks {
+	// The router may be mounted anywhere, so the prefix comes from the
+	// matched pattern rather than a constant.
+	pattern := chi.RouteContext(r.Context()).RoutePattern()
+	mount := pattern[:strings.Index(pattern, "/opds")]
+	return opdsLinks{prefix: requestOrigin(r) + mount + "/opds", json: mediaType == opds.JSONMediaType}
+}
+
+func (l opdsLinks) feed(rel string, path string, query url.Values, kind opds.Kind, title string) opds.Link {
+	query = cloneValues(query)
+	mediaType := opds.NavigationMediaType
+	if kind == opds.Acquisition {
+		mediaType = opds.AcquisitionMediaType
+	}
+	if l.json {
+		mediaType = opds.JSONMediaType
+		query.Set("format", opdsFormat)
+	}
+	href := l.prefix + path
+	if len(query) > 0 {
+		href += "?" + query.Encode()
+	}
+	return opds.Link{Rel: rel, Href: href, Type: mediaType, Title: title}
+}
+
+// search points Atom clients at the OpenSearch description and gives JSON
+// clients the URI template directly.
+func (l opdsLinks) search() opds.Link {
+	if l.json {
+		return opds.Link{Rel: "search", Href: l.prefix + "/search?format=" + opdsFormat + "{&q}", Type: opds.JSONMediaType, Templated: true}
+	}
+	return opds.Link{Rel: "search", Href: l.prefix + "/opensearch.xml", Type: opds.OpenSearchMediaType, Title: "Search"}
+}
+
+func (l opdsLinks) author(name string) opds.Link {
+	return l.feed("related", "/authors/"+url.PathEscape(name), nil, opds.Acquisition, "More by "+name)
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := url.Values{}
+	for key, value := range values {
+		clone[key] = append([]string(nil), value...)
+	}
+	return clone
+}
+
+// newOPDSFeed starts a feed with the links every page has: itself, the start
+// feed and search.
+func newOPDSFeed(links opdsLinks, title string, kind opds.Kind, path string, query url.Values, now time.Time) opds.Feed {
+	self := links.feed("self", path, query, kind, "")
+	return opds.Feed{
+		ID:      self.Href,
+		Title:   title,
+		Author:  opdsTitle,
+		Kind:    kind,
+		Updated: now,
+		Links:   []opds.Link{self, links.feed("start", "", nil, opds.Navigation, opdsTitle), links.search()},
+	}
+}
+
+// paginate adds the OpenSearch counts and the first, previous, next and last
+// links around the page starting at start, which counts from 1.
+func paginate(feed *opds.Feed, links opdsLinks, path string, query url.Values, start int, total int) {
+	feed.TotalResults, feed.ItemsPerPage, feed.StartIndex = total, opdsPageSize, start
+	page := func(rel string, index int) opds.Link {
+		pageQuery := cloneValues(query)
+		if index > 1 {
+			pageQuery.Set("startIndex", strconv.Itoa(index))
+		}
+		return links.feed(rel, path, pageQuery, opds.Acquisition, "")
+	}
+	last := max(total-1, 0)/opdsPageSize*opdsPageSize + 1
+	feed.Links = append(feed.Links, page("first", 1))
+	if start > 1 {
+		feed.Links = append(feed.Links, page("previous", max(start-opdsPageSize, 1)))
+	}
+	if start+opdsPageSize <= total {
+		feed.Links = append(feed.Links, page("next", start+opdsPageSize))
+	}
+	feed.Links = append(feed.Links, page("last", last))
+}
+
+// parseStartIndex reads the OpenSearch startIndex, which counts from 1.
+func parseStartIndex(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("startIndex")
+	if value == "" {
+		return 1, true
+	}
+	start, err := strconv.Atoi(value)
+	if err != nil || start < 1 {
+		http.Error(w, "startIndex must be at least 1", http.StatusBadRequest)
+		return 0, false
+	}
+	return start, true
+}
+
+// pageQuery keeps only the named parameters, leaving format to the links.
+func pageQuery(r *http.Request, keys ...string) url.Values {
+	query := url.Values{}
+	for _, key := range keys {
+		if value := r.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+	return query
+}
+
+func opdsMediaType(r *http.Request) string {
+	return negotiate(r, opds.AtomMediaType, opds.JSONMediaType)
+}
+
+func opdsPublications(links opdsLinks, items []model.GoogleBookItem) []opds.Publication {
+	publications := []opds.Publication{}
+	for _, item := range items {
+		publication := opds.NewPublication(item)
+		for _, author := range item.VolumeInfo.Authors {
+			publication.Links = append(publication.Links, links.author(author))
+		}
+		publications = append(publications, publication)
+	}
+	return publications
+}
+
+func writeOPDS(w http.ResponseWriter, mediaType string, feed opds.Feed) {
+	w.Header().Add("Vary", "Accept")
+	var err error
+	switch {
+	case mediaType == opds.JSONMediaType:
+		w.Header().Set("Content-Type", opds.JSONMediaType)
+		err = feed.WriteJSON(w)
+	case feed.Kind == opds.Acquisition:
+		w.Header().Set("Content-Type", opds.AcquisitionMediaType+";charset=utf-8")
+		err = feed.WriteAtom(w)
+	default:
+		w.Header().Set("Content-Type", opds.NavigationMediaType+";charset=utf-8")
+		err = feed.WriteAtom(w)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// categoryLinks lists categories as navigation entries. Categories with
+// subcategories lead to another navigation feed and the others straight to
+// their books.
+func categoryLinks(links opdsLinks, slugs []string, children map[string][]string, counts map[string]int) []opds.Link {
+	entries := []opds.Link{}
+	for _, slug := range slugs {
+		category, _ := client.LookupCategory(slug)
+		var link opds.Link
+		if len(children[slug]) > 0 {
+			link = links.feed("subsection", "/categories/"+slug, nil, opds.Navigation, category.Name)
+		} else {
+			link = links.feed("subsection", "/categories/"+slug+"/books", nil, opds.Acquisition, category.Name)
+		}
+		link.Count = counts[slug]
+		entries = append(entries, link)
+	}
+	return entries
+}
+
+func opdsStart(catalog *client.Catalog, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaType := opdsMediaType(r)
+		links := newOPDSLinks(r, mediaType)
+		roots, children := categoryTree()
+
+		feed := newOPDSFeed(links, opdsTitle, opds.Navigation, "", nil, now())
+		feed.Navigation = categoryLinks(links, roots, children, catalog.CategoryCounts())
+		writeOPDS(w, mediaType, feed)
+	}
+}
+
+func opdsCategory(catalog *client.Catalog, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		category, ok := client.LookupCategory(chi.URLParam(r, "slug"))
+		if !ok {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		mediaType := opdsMediaType(r)
+		links := newOPDSLinks(r, mediaType)
+		_, children := categoryTree()
+		counts := catalog.CategoryCounts()
+
+		feed := newOPDSFeed(links, category.Name, opds.Navigation, "/categories/"+category.Slug, nil, now())
+		feed.Links = append(feed.Links, categoryUp(links, category))
+		all := links.feed("subsection", "/categories/"+category.Slug+"/books", nil, opds.Acquisition, "All "+category.Name)
+		all.Count = counts[category.Slug]
+		feed.Navigation = append([]opds.Link{all}, categoryLinks(links, children[category.Slug], children, counts)...)
+		writeOPDS(w, mediaType, feed)
+	}
+}
+
+func categoryUp(links opdsLinks, category model.Category) opds.Link {
+	if category.Parent == "" {
+		return links.feed("up", "", nil, opds.Navigation, opdsTitle)
+	}
+	parent, _ := client.LookupCategory(category.Parent)
+	return links.feed("up", "/categories/"+parent.Slug, nil, opds.Navigation, parent.Name)
+}
+
+func opdsCategoryBooks(catalog *client.Catalog, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		category, ok := client.LookupCategory(chi.URLParam(r, "slug"))
+		if !ok {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		start, ok := parseStartIndex(w, r)
+		if !ok {
+			return
+		}
+		mediaType := opdsMediaType(r)
+		links := newOPDSLinks(r, mediaType)
+
+		page, total := catalog.Category(category.Slug, start-1, opdsPageSize)
+		path := "/categories/" + category.Slug + "/books"
+		feed := newOPDSFeed(links, category.Name, opds.Acquisition, path, pageQuery(r, "startIndex"), now())
+		feed.Links = append(feed.Links, categoryUp(links, category))
+		paginate(&feed, links, path, nil, start, total)
+		feed.Publications = opdsPublications(links, page)
+		writeOPDS(w, mediaType, feed)
+	}
+}
+
+func opdsAuthor(bookClient client.BookClientInterface, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := url.PathUnescape(chi.URLParam(r, "name"))
+		if err != nil || strings.TrimSpace(name) == "" {
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+		start, ok := parseStartIndex(w, r)
+		if !ok {
+			return
+		}
+		mediaType := opdsMediaType(r)
+		links := newOPDSLinks(r, mediaType)
+
+		// Fetch data from external API
+		books, err := bookClient.ByAuthor(r.Context(), client.GoogleBookRequest{
+			Author: name,
+			Start:  start - 1,
+			Limit:  opdsPageSize,
+			SortBy: client.SortPublishedDesc,
+		})
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		path := "/authors/" + url.PathEscape(name)
+		feed := newOPDSFeed(links, name, opds.Acquisition, path, pageQuery(r, "startIndex"), now())
+		paginate(&feed, links, path, nil, start, books.TotalItems)
+		feed.Publications = opdsPublications(links, books.Items)
+		writeOPDS(w, mediaType, feed)
+	}
+}
+
+func opdsSearch(bookClient client.BookClientInterface, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		if strings.TrimSpace(query) == "" {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		start, ok := parseStartIndex(w, r)
+		if !ok {
+			return
+		}
+		bookReq := client.GoogleBookRequest{Query: query, Start: start - 1, Limit: opdsPageSize}
+		if err := bookReq.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mediaType := opdsMediaType(r)
+		links := newOPDSLinks(r, mediaType)
+
+		// Fetch data from external API
+		books, err := bookClient.ByQuery(r.Context(), bookReq)
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		feed := newOPDSFeed(links, fmt.Sprintf("Search: %s", query), opds.Acquisition, "/search", pageQuery(r, "q", "startIndex"), now())
+		paginate(&feed, links, "/search", pageQuery(r, "q"), start, books.TotalItems)
+		feed.Publications = opdsPublications(links, books.Items)
+		writeOPDS(w, mediaType, feed)
+	}
+}
+
+func opdsOpenSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		links := newOPDSLinks(r, opds.AtomMediaType)
+		description := opds.OpenSearchDescription{
+			ShortName:   opdsTitle,
+			Description: "Search books by title, author, subject or any words.",
+			URLs: []opds.OpenSearchURL{
+				{Type: opds.AcquisitionMediaType, Template: links.prefix + "/search?q={searchTerms}&startIndex={startIndex?}"},
+				{Type: opds.JSONMediaType, Template: links.prefix + "/search?format=" + opdsFormat + "&q={searchTerms}&startIndex={startIndex?}"},
+			},
+		}
+		w.Header().Set("Content-Type", opds.OpenSearchMediaType+"; charset=utf-8")
+		if err := description.Write(w); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
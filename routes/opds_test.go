@@ -0,0 +1,235 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/opds"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type opdsFeed struct {
+	ID           string      `xml:"http://www.w3.org/2005/Atom id"`
+	Title        string      `xml:"http://www.w3.org/2005/Atom title"`
+	TotalResults int         `xml:"http://a9.com/-/spec/opensearch/1.1/ totalResults"`
+	StartIndex   int         `xml:"http://a9.com/-/spec/opensearch/1.1/ startIndex"`
+	Links        []opdsLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Entries      []opdsEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type opdsLink struct {
+	Rel   string `xml:"rel,attr"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr"`
+	Title string `xml:"title,attr"`
+	Count int    `xml:"http://purl.org/syndication/thread/1.0 count,attr"`
+}
+
+type opdsEntry struct {
+	Title string     `xml:"http://www.w3.org/2005/Atom title"`
+	Links []opdsLink `xml:"http://www.w3.org/2005/Atom link"`
+}
+
+func (f opdsFeed) link(rel string) opdsLink {
+	return findOPDSLink(f.Links, rel)
+}
+
+func findOPDSLink(links []opdsLink, rel string) opdsLink {
+	for _, link := range links {
+		if link.Rel == rel {
+			return link
+		}
+	}
+	return opdsLink{}
+}
+
+func cyberpunkBook(n int) model.GoogleBookItem {
+	return model.GoogleBookItem{
+		ID: fmt.Sprintf("id-%02d", n),
+		VolumeInfo: model.GoogleBookVolumeInfo{
+			Title:      fmt.Sprintf("Book %02d", n),
+			Authors:    []string{"William Gibson"},
+			Categories: []string{"Fiction / Science Fiction / Cyberpunk"},
+		},
+	}
+}
+
+func setupOPDSRouter(api client.BookClientInterface, catalog *client.Catalog) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		OPDSRouter(r, api, catalog)
+	})
+	return r
+}
+
+func getOPDS(t *testing.T, router http.Handler, path string, accept string) (*httptest.ResponseRecorder, opdsFeed) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var feed opdsFeed
+	if rec.Code == http.StatusOK && accept != opds.JSONMediaType {
+		assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &feed))
+	}
+	return rec, feed
+}
+
+func TestOPDS_navigation(t *testing.T) {
+	catalog := client.NewCatalog()
+	for n := 1; n <= 3; n++ {
+		catalog.Add(cyberpunkBook(n))
+	}
+	router := setupOPDSRouter(MockClient{}, catalog)
+
+	t.Run("start", func(t *testing.T) {
+		rec, feed := getOPDS(t, router, "/api/opds", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, opds.NavigationMediaType+";charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "http://example.com/api/opds", feed.ID)
+		assert.Equal(t, opdsLink{Rel: "search", Href: "http://example.com/api/opds/opensearch.xml", Type: opds.OpenSearchMediaType, Title: "Search"}, feed.link("search"))
+		assert.Equal(t, "Fiction", feed.Entries[0].Title)
+		assert.Equal(t, opdsLink{Rel: "subsection", Href: "http://example.com/api/opds/categories/fiction", Type: opds.NavigationMediaType, Title: "Fiction", Count: 3}, feed.Entries[0].Links[0])
+	})
+
+	t.Run("category", func(t *testing.T) {
+		rec, feed := getOPDS(t, router, "/api/opds/categories/science-fiction", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "http://example.com/api/opds/categories/fiction", feed.link("up").Href)
+		assert.Equal(t, "All Science Fiction", feed.Entries[0].Title)
+		assert.Equal(t, "http://example.com/api/opds/categories/science-fiction/books", feed.Entries[0].Links[0].Href)
+		// Leaf categories lead straight to their books
+		assert.Equal(t, "Cyberpunk", feed.Entries[1].Title)
+		assert.Equal(t, opdsLink{Rel: "subsection", Href: "http://example.com/api/opds/categories/cyberpunk/books", Type: opds.AcquisitionMediaType, Title: "Cyberpunk", Count: 3}, feed.Entries[1].Links[0])
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		rec, _ := getOPDS(t, router, "/api/opds/categories/unknown", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestOPDS_categoryBooks(t *testing.T) {
+	catalog := client.NewCatalog()
+	for n := 1; n <= 45; n++ {
+		catalog.Add(cyberpunkBook(n))
+	}
+	router := setupOPDSRouter(MockClient{}, catalog)
+
+	rec, feed := getOPDS(t, router, "/api/opds/categories/cyberpunk/books?startIndex=21", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, opds.AcquisitionMediaType+";charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 45, feed.TotalResults)
+	assert.Equal(t, 21, feed.StartIndex)
+	assert.Len(t, feed.Entries, opdsPageSize)
+	assert.Equal(t, "http://example.com/api/opds/categories/cyberpunk/books?startIndex=21", feed.link("self").Href)
+	assert.Equal(t, "http://example.com/api/opds/categories/cyberpunk/books", feed.link("first").Href)
+	assert.Equal(t, "http://example.com/api/opds/categories/cyberpunk/books", feed.link("previous").Href)
+	assert.Equal(t, "http://example.com/api/opds/categories/cyberpunk/books?startIndex=41", feed.link("next").Href)
+	assert.Equal(t, "http://example.com/api/opds/categories/cyberpunk/books?startIndex=41", feed.link("last").Href)
+	assert.Equal(t, "http://example.com/api/opds/categories/science-fiction", feed.link("up").Href)
+
+	_, last := getOPDS(t, router, "/api/opds/categories/cyberpunk/books?startIndex=41", "")
+	assert.Len(t, last.Entries, 5)
+	assert.Empty(t, last.link("next").Href)
+}
+
+func TestOPDS_search(t *testing.T) {
+	bc := client.GoogleBookClient{PactMode: true, Catalog: client.NewCatalog()}
+	pact, err := bc.ByAuthor(context.Background(), client.GoogleBookRequest{Author: "William Gibson"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		path           string
+		response       model.GoogleBookResponse
+		err            error
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "results", path: "/api/opds/search?q=gibson", response: model.GoogleBookResponse{TotalItems: 45, Items: pact.Items[:20]}, expectedStatus: http.StatusOK, expectedCount: 20},
+		{name: "no results", path: "/api/opds/search?q=nobody", expectedStatus: http.StatusOK},
+		{name: "missing query", path: "/api/opds/search", expectedStatus: http.StatusBadRequest},
+		{name: "bad start index", path: "/api/opds/search?q=gibson&startIndex=0", expectedStatus: http.StatusBadRequest},
+		{name: "syntax error", path: `/api/opds/search?q="gibson`, expectedStatus: http.StatusBadRequest},
+		{name: "client error", path: "/api/opds/search?q=gibson", err: errors.New("test-error"), expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupOPDSRouter(MockClient{Response: tt.response, Err: tt.err}, client.NewCatalog())
+			rec, feed := getOPDS(t, router, tt.path, "")
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Len(t, feed.Entries, tt.expectedCount)
+			if tt.expectedCount > 0 {
+				assert.Equal(t, "http://example.com/api/opds/search?q=gibson&startIndex=21", feed.link("next").Href)
+				assert.Equal(t, "http://example.com/api/opds/authors/William%20Gibson", findOPDSLink(feed.Entries[0].Links, "related").Href)
+			}
+		})
+	}
+}
+
+func TestOPDS_json(t *testing.T) {
+	item := cyberpunkBook(1)
+	item.AccessInfo = model.GoogleBookAccessInfo{PublicDomain: true, Epub: model.GoogleBookEpubInfo{DownloadLink: "http://books.example/book.epub"}}
+	router := setupOPDSRouter(MockClient{Response: model.GoogleBookResponse{TotalItems: 1, Items: []model.GoogleBookItem{item}}}, client.NewCatalog())
+
+	for _, path := range []string{"/api/opds/search?q=gibson", "/api/opds/search?q=gibson&format=opds2"} {
+		rec, _ := getOPDS(t, router, path, opds.JSONMediaType)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, opds.JSONMediaType, rec.Header().Get("Content-Type"))
+
+		var doc struct {
+			Links        []map[string]any `json:"links"`
+			Publications []struct {
+				Links []map[string]any `json:"links"`
+			} `json:"publications"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+		assert.Equal(t, "http://example.com/api/opds/search?format=opds2&q=gibson", doc.Links[0]["href"])
+		assert.Equal(t, map[string]any{"rel": "search", "href": "http://example.com/api/opds/search?format=opds2{&q}", "type": opds.JSONMediaType, "templated": true}, doc.Links[2])
+		assert.Equal(t, map[string]any{"rel": opds.RelAcquisitionOpenAccess, "href": "http://books.example/book.epub", "type": opds.EpubMediaType}, doc.Publications[0].Links[0])
+		assert.Equal(t, opds.JSONMediaType, doc.Publications[0].Links[1]["type"])
+	}
+}
+
+func TestOPDS_author(t *testing.T) {
+	cli := PagedMockClient{Pages: map[int]model.GoogleBookResponse{
+		20: {TotalItems: 25, Items: []model.GoogleBookItem{cyberpunkBook(21)}},
+	}}
+	router := setupOPDSRouter(cli, client.NewCatalog())
+
+	rec, feed := getOPDS(t, router, "/api/opds/authors/William%20Gibson?startIndex=21", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "William Gibson", feed.Title)
+	assert.Len(t, feed.Entries, 1)
+	assert.Equal(t, "http://example.com/api/opds/authors/William%20Gibson", feed.link("previous").Href)
+	assert.Empty(t, feed.link("next").Href)
+}
+
+func TestOPDS_openSearch(t *testing.T) {
+	router := setupOPDSRouter(MockClient{}, client.NewCatalog())
+	req := httptest.NewRequest(http.MethodGet, "/api/opds/opensearch.xml", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var description opds.OpenSearchDescription
+	assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &description))
+	assert.Equal(t, []opds.OpenSearchURL{
+		{Type: opds.AcquisitionMediaType, Template: "https://example.com/api/opds/search?q={searchTerms}&startIndex={startIndex?}"},
+		{Type: opds.JSONMediaType, Template: "https://example.com/api/opds/search?format=opds2&q={searchTerms}&startIndex={startIndex?}"},
+	}, description.URLs)
+}
@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	client "example.com/book-learn/clients"
+	"example.com/book-learn/recommend"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+)
+
+type SimilarBooksResponse struct {
+	ID    string        `json:"id"`
+	Title string        `json:"title"`
+	Books []SimilarBook `json:"books"`
+}
+
+type SimilarBook struct {
+	BookResponse
+	Score   float64            `json:"score"`
+	Reasons []recommend.Reason `json:"reasons"`
+}
+
+// RecommendationsRouter serves content-based recommendations computed from
+// the volumes the service has already seen.
+func RecommendationsRouter(r chi.Router, api client.BookClientInterface, recommender *recommend.Recommender) {
+	r.Get("/books/{id}/similar", similarBooks(api, recommender))
+}
+
+func similarBooks(bookClient client.BookClientInterface, recommender *recommend.Recommender) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultSimilarLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > maxSimilarLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxSimilarLimit), http.StatusBadRequest)
+				return
+			}
+		}
+
+		seed, err := bookClient.ByID(r.Context(), chi.URLParam(r, "id"))
+		if errors.Is(err, client.ErrVolumeNotFound) {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.Error(err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		recommendations := recommender.Similar(seed, limit)
+		// No results
+		if len(recommendations) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Format response
+		resp := SimilarBooksResponse{ID: seed.ID, Title: seed.VolumeInfo.Title}
+		for _, recommendation := range recommendations {
+			similar := SimilarBook{Score: recommendation.Score, Reasons: recommendation.Reasons}
+			similar.fromItem(recommendation.Book)
+			resp.Books = append(resp.Books, similar)
+		}
+
+		render(w, r, http.StatusOK, resp)
+	}
+}
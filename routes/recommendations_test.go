@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/recommend"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRecommendationsRouter(err error, items ...model.GoogleBookItem) http.Handler {
+	r := chi.NewRouter()
+	catalog := client.NewCatalog()
+	catalog.Add(items...)
+	cli := MockClient{Response: model.GoogleBookResponse{Items: items}, Err: err}
+	RecommendationsRouter(r, cli, recommend.New(catalog, nil))
+	return r
+}
+
+func TestRecommendationsRouter(t *testing.T) {
+	items := []model.GoogleBookItem{
+		{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}, Categories: []string{"Cyberpunk"}}},
+		{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}, Categories: []string{"Cyberpunk"}}},
+		{ID: "schismatrix", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Schismatrix", Authors: []string{"Bruce Sterling"}, Categories: []string{"Science Fiction"}}},
+		{ID: "emma", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Emma", Authors: []string{"Jane Austen"}}},
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		err            error
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{
+			name:           "GET:/books/{id}/similar with a known volume",
+			path:           "/books/neuromancer/similar",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"count-zero", "schismatrix"},
+		},
+		{
+			name:           "GET:/books/{id}/similar with a limit",
+			path:           "/books/neuromancer/similar?limit=1",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"count-zero"},
+		},
+		{
+			name:           "GET:/books/{id}/similar with nothing similar",
+			path:           "/books/emma/similar",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "GET:/books/{id}/similar with an unknown volume",
+			path:           "/books/unknown/similar",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "GET:/books/{id}/similar with an invalid limit",
+			path:           "/books/neuromancer/similar?limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GET:/books/{id}/similar when the lookup fails",
+			path:           "/books/neuromancer/similar",
+			err:            errors.New("upstream unavailable"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupRecommendationsRouter(tt.err, items...)
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp SimilarBooksResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "Neuromancer", resp.Title)
+				ids := []string{}
+				for _, book := range resp.Books {
+					ids = append(ids, book.ID)
+					assert.NotEmpty(t, book.Reasons)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+		})
+	}
+}
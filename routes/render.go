@@ -0,0 +1,306 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"example.com/book-learn/marc"
+	"example.com/book-learn/opds"
+)
+
+const (
+	jsonMediaType = "application/json"
+	csvMediaType  = "text/csv"
+	tsvMediaType  = "text/tab-separated-values"
+)
+
+// formatMediaTypes maps the ?format= shorthand to a media type, for clients
+// such as browsers that cannot set an Accept header.
+var formatMediaTypes = map[string]string{
+	"json":     jsonMediaType,
+	"csv":      csvMediaType,
+	"tsv":      tsvMediaType,
+	"bibtex":   bibtexMediaType,
+	"bib":      bibtexMediaType,
+	"ris":      risMediaType,
+	"csl":      cslMediaType,
+	"csl-json": cslMediaType,
+	"marcxml":  marcXMLMediaType,
+	"marc":     marcMediaType,
+	"mrc":      marcMediaType,
+	"jsonld":   jsonLDMediaType,
+	"atom":     opds.AtomMediaType,
+	"opds2":    opds.JSONMediaType,
+}
+
+// bookTable is implemented by responses that are a list of books, which can
+// be rendered as a flat table with one row per book or as citations.
+type bookTable interface {
+	tableBooks() []BookResponse
+}
+
+func (resp AuthorResponse) tableBooks() []BookResponse        { return resp.Books }
+func (resp TitleResponse) tableBooks() []BookResponse         { return resp.Books }
+func (resp QueryResponse) tableBooks() []BookResponse         { return resp.Books }
+func (resp SeriesResponse) tableBooks() []BookResponse        { return resp.Books }
+func (resp CategoryBooksResponse) tableBooks() []BookResponse { return resp.Books }
+
+func (resp SearchResponse) tableBooks() []BookResponse {
+	books := []BookResponse{}
+	for _, result := range resp.Books {
+		books = append(books, result.BookResponse)
+	}
+	return books
+}
+
+func (resp SimilarBooksResponse) tableBooks() []BookResponse {
+	books := []BookResponse{}
+	for _, similar := range resp.Books {
+		books = append(books, similar.BookResponse)
+	}
+	return books
+}
+
+// render writes resp with the status in the representation the client asked
+// for with ?format= or the Accept header. Lists of books can also be rendered
+// as CSV or TSV, with ?columns= choosing the columns, lists and single
+// volumes as BibTeX, RIS or CSL-JSON citations, single volumes and batches
+// as MARCXML or ISO 2709 MARC 21, and single volumes as schema.org JSON-LD.
+// Everything else, and anything the client asks for that is not on offer,
+// is indented JSON.
+func render(w http.ResponseWriter, r *http.Request, status int, resp any) {
+	offers := []string{jsonMediaType}
+	var cited func() []BookResponse
+	table, isTable := resp.(bookTable)
+	if isTable {
+		offers = append(offers, csvMediaType, tsvMediaType)
+		cited = table.tableBooks
+	}
+	if source, ok := resp.(citationSource); ok {
+		cited = source.citationBooks
+	}
+	if cited != nil {
+		offers = append(offers, bibtexMediaType, risMediaType, cslMediaType)
+	}
+	records, isMARC := resp.(marcSource)
+	if isMARC {
+		offers = append(offers, marcXMLMediaType, marcMediaType)
+	}
+	linked, isLinked := resp.(jsonLDSource)
+	if isLinked {
+		offers = append(offers, jsonLDMediaType)
+	}
+	w.Header().Add("Vary", "Accept")
+
+	mediaType := negotiate(r, offers...)
+	var write func(io.Writer) error
+	switch mediaType {
+	case csvMediaType, tsvMediaType:
+		columns, err := tableColumns(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		write = func(out io.Writer) error { return writeTable(out, mediaType, columns, table.tableBooks()) }
+	case bibtexMediaType:
+		write = func(out io.Writer) error { return writeBibTeX(out, cited()) }
+	case risMediaType:
+		write = func(out io.Writer) error { return writeRIS(out, cited()) }
+	case cslMediaType:
+		write = func(out io.Writer) error { return writeCSL(out, cited()) }
+	case marcXMLMediaType:
+		write = func(out io.Writer) error { return marc.WriteXML(out, marcRecords(records.marcBooks())) }
+	case marcMediaType:
+		write = func(out io.Writer) error { return marc.WriteBinary(out, marcRecords(records.marcBooks())) }
+	case jsonLDMediaType:
+		write = func(out io.Writer) error { return writeJSON(out, linked.linkedData()) }
+	default:
+		write = func(out io.Writer) error { return writeJSON(out, resp) }
+	}
+
+	// Encode before writing the headers, so a record MARC cannot hold is a
+	// 500 rather than a 200 with a truncated body
+	var body bytes.Buffer
+	if err := write(&body); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	switch mediaType {
+	case jsonMediaType, jsonLDMediaType, cslMediaType, marcXMLMediaType, marcMediaType:
+		w.Header().Set("Content-Type", mediaType)
+	default:
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	if _, err := body.WriteTo(w); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// writeJSON writes indented JSON. HTML characters stay escaped, so the
+// output can be embedded in a script element as it is.
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// negotiate picks the offer the client prefers. An explicit ?format= wins,
+// then the Accept header's quality values, and the first offer is the
+// default.
+func negotiate(r *http.Request, offers ...string) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if mediaType, ok := formatMediaTypes[strings.ToLower(format)]; ok && slices.Contains(offers, mediaType) {
+			return mediaType
+		}
+		return offers[0]
+	}
+
+	best, bestQuality := offers[0], 0.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+		for _, offer := range offers {
+			if quality > bestQuality && mediaTypeMatches(mediaType, offer) {
+				best, bestQuality = offer, quality
+			}
+		}
+	}
+	return best
+}
+
+// mediaTypeMatches reports whether an Accept entry, which may be a wildcard
+// such as text/* or */*, covers the offer. A wildcard settles on the
+// first matching offer, so */* keeps the default.
+func mediaTypeMatches(accepted string, offer string) bool {
+	if accepted == "*/*" || accepted == offer {
+		return true
+	}
+	acceptedType, acceptedSubtype, _ := strings.Cut(accepted, "/")
+	offerType, _, _ := strings.Cut(offer, "/")
+	return acceptedSubtype == "*" && acceptedType == offerType
+}
+
+// tableColumn renders one field of a book as a table cell. Multi-valued
+// fields are joined with "; ".
+type tableColumn struct {
+	name  string
+	value func(BookResponse) string
+}
+
+var bookColumns = []tableColumn{
+	{name: "id", value: func(b BookResponse) string { return b.ID }},
+	{name: "title", value: func(b BookResponse) string { return b.Title }},
+	{name: "subtitle", value: func(b BookResponse) string { return b.Subtitle }},
+	{name: "authors", value: func(b BookResponse) string { return strings.Join(b.Authors, "; ") }},
+	{name: "publisher", value: func(b BookResponse) string { return b.Publisher }},
+	{name: "isbn", value: func(b BookResponse) string { return b.isbn() }},
+	{name: "publishedDate", value: func(b BookResponse) string { return b.PublishedDate }},
+	{name: "year", value: func(b BookResponse) string { return optionalInt(b.PublishedDateNormalized.ExactYear()) }},
+	{name: "pageCount", value: func(b BookResponse) string { return optionalInt(b.PageCount) }},
+	{name: "categories", value: func(b BookResponse) string { return strings.Join(b.Categories, "; ") }},
+	{name: "normalizedCategories", value: func(b BookResponse) string {
+		names := []string{}
+		for _, category := range b.NormalizedCategories {
+			names = append(names, category.Name)
+		}
+		return strings.Join(names, "; ")
+	}},
+	{name: "series", value: func(b BookResponse) string {
+		if b.Series == nil {
+			return ""
+		}
+		return b.Series.Title
+	}},
+	{name: "seriesPosition", value: func(b BookResponse) string {
+		if b.Series == nil {
+			return ""
+		}
+		return optionalInt(b.Series.Position)
+	}},
+	{name: "editions", value: func(b BookResponse) string { return strconv.Itoa(len(b.Editions) + 1) }},
+	{name: "language", value: func(b BookResponse) string { return b.Language }},
+	{name: "description", value: func(b BookResponse) string { return b.Description }},
+	{name: "thumbnail", value: func(b BookResponse) string { return b.ImageLinks.Thumbnail }},
+	{name: "previewLink", value: func(b BookResponse) string { return b.PreviewLink }},
+	{name: "infoLink", value: func(b BookResponse) string { return b.InfoLink }},
+}
+
+var defaultColumns = []string{"id", "title", "authors", "publishedDate", "pageCount", "categories", "language", "description"}
+
+// tableColumns reads the comma separated ?columns= list, defaulting to
+// defaultColumns.
+func tableColumns(r *http.Request) ([]tableColumn, error) {
+	names := defaultColumns
+	if value := r.URL.Query().Get("columns"); value != "" {
+		names = strings.Split(value, ",")
+	}
+	columns := []tableColumn{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(bookColumns, func(c tableColumn) bool { return strings.EqualFold(c.name, name) })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns = append(columns, bookColumns[i])
+	}
+	return columns, nil
+}
+
+// writeTable writes a header row and one row per book. Cells holding the
+// separator, quotes or newlines, as descriptions often do, are quoted, and
+// cells a spreadsheet would run as a formula are escaped.
+func writeTable(w io.Writer, mediaType string, columns []tableColumn, books []BookResponse) error {
+	writer := csv.NewWriter(w)
+	if mediaType == tsvMediaType {
+		writer.Comma = '\t'
+	}
+	header := []string{}
+	for _, column := range columns {
+		header = append(header, column.name)
+	}
+	writer.Write(header)
+	for _, book := range books {
+		row := []string{}
+		for _, column := range columns {
+			row = append(row, spreadsheetCell(column.value(book)))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// spreadsheetCell prefixes a cell starting with a formula character with a
+// quote, so spreadsheets show it as text rather than evaluating it, as
+// OWASP recommends against CSV injection.
+func spreadsheetCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func optionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
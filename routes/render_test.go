@@ -0,0 +1,201 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender_bookTables(t *testing.T) {
+	items := []model.GoogleBookItem{
+		{
+			ID: "id-1",
+			VolumeInfo: model.GoogleBookVolumeInfo{
+				Title:         "Count Zero",
+				Authors:       []string{"William Gibson", "Someone Else"},
+				PublishedDate: "1986",
+				PageCount:     256,
+				Categories:    []string{"Fiction", "Science Fiction"},
+				Language:      "en",
+				Description:   "Turner, a mercenary, wakes up \"new\".\nThen, everything changes.",
+			},
+		},
+		{
+			ID:         "id-2",
+			VolumeInfo: model.GoogleBookVolumeInfo{Title: "Mona Lisa Overdrive", Authors: []string{"William Gibson"}},
+		},
+	}
+	body, _ := json.Marshal(client.GoogleBookRequest{Title: "test-title"})
+
+	tests := []struct {
+		name            string
+		query           string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantRows        [][]string
+		wantComma       rune
+	}{
+		{
+			name:            "defaults to json",
+			wantStatus:      http.StatusOK,
+			wantContentType: jsonMediaType,
+		},
+		{
+			name:            "wildcard keeps json",
+			accept:          "*/*",
+			wantStatus:      http.StatusOK,
+			wantContentType: jsonMediaType,
+		},
+		{
+			name:            "csv with configured columns",
+			query:           "?columns=id,authors,description",
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantComma:       ',',
+			wantRows: [][]string{
+				{"id", "authors", "description"},
+				{"id-1", "William Gibson; Someone Else", "Turner, a mercenary, wakes up \"new\".\nThen, everything changes."},
+				{"id-2", "William Gibson", ""},
+			},
+		},
+		{
+			name:            "tsv preferred by quality",
+			query:           "?columns=title,categories,pageCount",
+			accept:          "application/json;q=0.5, text/tab-separated-values",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/tab-separated-values; charset=utf-8",
+			wantComma:       '\t',
+			wantRows: [][]string{
+				{"title", "categories", "pageCount"},
+				{"Count Zero", "Fiction; Science Fiction", "256"},
+				{"Mona Lisa Overdrive", "", ""},
+			},
+		},
+		{
+			name:            "format parameter overrides accept",
+			query:           "?format=csv&columns=title",
+			accept:          "application/json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantComma:       ',',
+			wantRows:        [][]string{{"title"}, {"Count Zero"}, {"Mona Lisa Overdrive"}},
+		},
+		{
+			name:       "unknown column",
+			query:      "?columns=title,price",
+			accept:     "text/csv",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupBooksRouter(model.GoogleBookResponse{TotalItems: len(items), Items: items}, nil)
+			req := httptest.NewRequest(http.MethodPost, "/books/title"+tt.query, bytes.NewReader(body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			if tt.wantRows == nil {
+				var resp TitleResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Len(t, resp.Books, len(items))
+				return
+			}
+			reader := csv.NewReader(rec.Body)
+			reader.Comma = tt.wantComma
+			rows, err := reader.ReadAll()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRows, rows)
+		})
+	}
+}
+
+func TestRender_jsonOnlyResponses(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/suggest?format=csv", nil)
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+
+	render(rec, req, http.StatusOK, SuggestResponse{Prefix: "neu"})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, jsonMediaType, rec.Header().Get("Content-Type"))
+	var resp SuggestResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "neu", resp.Prefix)
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{jsonMediaType, csvMediaType, tsvMediaType}
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "no header", accept: "", want: jsonMediaType},
+		{name: "exact", accept: "text/csv", want: csvMediaType},
+		{name: "parameters ignored", accept: "text/csv; charset=utf-8", want: csvMediaType},
+		{name: "quality wins", accept: "text/csv;q=0.4, text/tab-separated-values;q=0.9", want: tsvMediaType},
+		{name: "type wildcard", accept: "text/*", want: csvMediaType},
+		{name: "unsupported", accept: "application/xml", want: jsonMediaType},
+		{name: "malformed entries skipped", accept: "nonsense, text/csv", want: csvMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.accept)
+			assert.Equal(t, tt.want, negotiate(req, offers...))
+		})
+	}
+}
+
+func TestWriteTable_formulaCells(t *testing.T) {
+	books := []BookResponse{
+		{ID: "id-1", Title: "=HYPERLINK(\"http://evil.example\")", Description: "-2+3"},
+		{ID: "id-2", Title: "@SUM(A1)", Description: "+1 for cyberpunk"},
+		{ID: "id-3", Title: "Count Zero", Description: "Turner = mercenary"},
+	}
+	columns, _ := tableColumns(httptest.NewRequest(http.MethodGet, "/?columns=title,description", nil))
+
+	var out bytes.Buffer
+	assert.NoError(t, writeTable(&out, csvMediaType, columns, books))
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"title", "description"},
+		{`'=HYPERLINK("http://evil.example")`, "'-2+3"},
+		{"'@SUM(A1)", "'+1 for cyberpunk"},
+		{"Count Zero", "Turner = mercenary"},
+	}, rows)
+}
+
+func TestWriteTable_yearPrecision(t *testing.T) {
+	books := []BookResponse{
+		{ID: "exact", PublishedDateNormalized: model.ParsePublicationDate("1984-07")},
+		{ID: "decade", PublishedDateNormalized: model.ParsePublicationDate("198*")},
+	}
+	columns, _ := tableColumns(httptest.NewRequest(http.MethodGet, "/?columns=id,year", nil))
+
+	var out bytes.Buffer
+	assert.NoError(t, writeTable(&out, csvMediaType, columns, books))
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"id", "year"}, {"exact", "1984"}, {"decade", ""}}, rows)
+}
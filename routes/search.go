@@ -0,0 +1,110 @@
+package routes
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	client "example.com/book-learn/clients"
+	"example.com/book-learn/search"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 40
+	// minLocalHits is how many local matches a search needs before Google is
+	// no longer asked.
+	minLocalHits = 3
+
+	SourceLocal    = "local"
+	SourceUpstream = "upstream"
+)
+
+type SearchResponse struct {
+	Query      string         `json:"query"`
+	TotalItems int            `json:"totalItems"`
+	Books      []SearchResult `json:"books"`
+	DidYouMean []string       `json:"didYouMean,omitempty"`
+}
+
+type SearchResult struct {
+	BookResponse
+	Score  float64 `json:"score,omitempty"`
+	Source string  `json:"source"`
+}
+
+// SearchRouter serves full-text search over the local index, falling back to
+// Google when the index has too few matches. When nothing is found the
+// speller, if given, suggests corrections.
+func SearchRouter(r chi.Router, api client.BookClientInterface, index *search.Index, speller *search.Speller) {
+	r.Get("/search", searchBooks(api, index, speller))
+}
+
+func searchBooks(bookClient client.BookClientInterface, index *search.Index, speller *search.Speller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := search.ParseQuery(r.URL.Query().Get("q"))
+		if query.IsEmpty() {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		limit := defaultSearchLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > maxSearchLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit), http.StatusBadRequest)
+				return
+			}
+		}
+
+		resp := SearchResponse{Query: query.String()}
+		seen := map[string]bool{}
+		for _, hit := range index.Search(query, limit) {
+			result := SearchResult{Score: hit.Score, Source: SourceLocal}
+			result.fromItem(hit.Book)
+			resp.Books = append(resp.Books, result)
+			seen[hit.Book.ID] = true
+		}
+
+		// Low recall, ask Google
+		if len(resp.Books) < min(limit, minLocalHits) {
+			upstream, err := bookClient.ByQuery(r.Context(), client.GoogleBookRequest{
+				Query: query.String(),
+				Limit: limit,
+			})
+			if err != nil {
+				slog.Error(err.Error())
+				// Local results are better than none
+				if len(resp.Books) == 0 {
+					http.Error(w, "", http.StatusInternalServerError)
+					return
+				}
+				upstream.Items = nil
+			}
+			for _, item := range upstream.Items {
+				if seen[item.ID] || len(resp.Books) == limit {
+					continue
+				}
+				result := SearchResult{Source: SourceUpstream}
+				result.fromItem(item)
+				resp.Books = append(resp.Books, result)
+				seen[item.ID] = true
+			}
+		}
+
+		// No results, but perhaps a typo
+		if len(resp.Books) == 0 {
+			resp.DidYouMean = didYouMean(speller, r.URL.Query().Get("q"))
+			if len(resp.DidYouMean) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			resp.Books = []SearchResult{}
+		}
+		resp.TotalItems = len(resp.Books)
+
+		render(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,175 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"example.com/book-learn/search"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSearchRouter(upstream model.GoogleBookResponse, err error, local ...model.GoogleBookItem) http.Handler {
+	r := chi.NewRouter()
+	index := search.NewIndex()
+	index.Add(local)
+	SearchRouter(r, MockClient{Response: upstream, Err: err}, index, nil)
+	return r
+}
+
+func TestSearchRouter(t *testing.T) {
+	local := []model.GoogleBookItem{
+		{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}}},
+		{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}}},
+		{ID: "mona-lisa", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Mona Lisa Overdrive", Authors: []string{"William Gibson"}}},
+	}
+	upstream := model.GoogleBookResponse{Items: []model.GoogleBookItem{
+		{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer"}},
+		{ID: "burning-chrome", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Burning Chrome"}},
+	}}
+
+	tests := []struct {
+		name            string
+		path            string
+		err             error
+		expectedStatus  int
+		expectedIDs     []string
+		expectedSources []string
+	}{
+		{
+			name:            "GET:/search answered locally",
+			path:            "/search?q=gibson",
+			err:             errors.New("upstream should not be called"),
+			expectedStatus:  http.StatusOK,
+			expectedIDs:     []string{"neuromancer", "count-zero", "mona-lisa"},
+			expectedSources: []string{SourceLocal, SourceLocal, SourceLocal},
+		},
+		{
+			name:            "GET:/search falls back upstream on low recall",
+			path:            "/search?q=neuromancer",
+			expectedStatus:  http.StatusOK,
+			expectedIDs:     []string{"neuromancer", "burning-chrome"},
+			expectedSources: []string{SourceLocal, SourceUpstream},
+		},
+		{
+			name:            "GET:/search keeps local results when upstream fails",
+			path:            "/search?q=neuromancer",
+			err:             errors.New("upstream unavailable"),
+			expectedStatus:  http.StatusOK,
+			expectedIDs:     []string{"neuromancer"},
+			expectedSources: []string{SourceLocal},
+		},
+		{
+			name:            "GET:/search with a limit",
+			path:            "/search?q=gibson&limit=2",
+			expectedStatus:  http.StatusOK,
+			expectedIDs:     []string{"neuromancer", "count-zero"},
+			expectedSources: []string{SourceLocal, SourceLocal},
+		},
+		{
+			name:           "GET:/search fails when nothing is found and upstream fails",
+			path:           "/search?q=idoru",
+			err:            errors.New("upstream unavailable"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "GET:/search without a query",
+			path:           "/search",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GET:/search with an invalid limit",
+			path:           "/search?q=gibson&limit=100",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupSearchRouter(upstream, tt.err, local...)
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp SearchResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				ids, sources := []string{}, []string{}
+				for _, book := range resp.Books {
+					ids = append(ids, book.ID)
+					sources = append(sources, book.Source)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+				assert.Equal(t, tt.expectedSources, sources)
+				assert.Equal(t, len(ids), resp.TotalItems)
+			}
+		})
+	}
+}
+
+func TestSearchRouter_noResults(t *testing.T) {
+	r := setupSearchRouter(model.GoogleBookResponse{}, nil)
+	req, _ := http.NewRequest("GET", "/search?q=idoru", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestSearchRouter_didYouMean(t *testing.T) {
+	r := chi.NewRouter()
+	index := search.NewIndex()
+	speller := search.NewSpeller()
+	items := []model.GoogleBookItem{{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero"}}}
+	index.Add(items)
+	speller.AddVolumes(items)
+	SearchRouter(r, MockClient{}, index, speller)
+
+	req, _ := http.NewRequest("GET", "/search?q=cuont+zeor", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp SearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 0, resp.TotalItems)
+	assert.Empty(t, resp.Books)
+	assert.Equal(t, []string{"Count Zero"}, resp.DidYouMean)
+}
+
+// queryClient answers only plain query searches and records the query sent.
+type queryClient struct {
+	MockClient
+	queries *[]string
+}
+
+func (cli queryClient) ByTitle(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return model.GoogleBookResponse{}, errors.New("title search should not be called")
+}
+
+func (cli queryClient) ByQuery(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	*cli.queries = append(*cli.queries, request.Query)
+	return cli.Response, cli.Err
+}
+
+func TestSearchRouter_upstreamQuery(t *testing.T) {
+	r := chi.NewRouter()
+	queries := []string{}
+	upstream := model.GoogleBookResponse{Items: []model.GoogleBookItem{
+		{ID: "burning-chrome", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Burning Chrome"}},
+	}}
+	SearchRouter(r, queryClient{MockClient: MockClient{Response: upstream}, queries: &queries}, search.NewIndex(), nil)
+
+	req, _ := http.NewRequest("GET", `/search?q=gibson+"short+stories"`, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{`"short stories" gibson`}, queries)
+}
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"net/http"
+
+	client "example.com/book-learn/clients"
+	"github.com/go-chi/chi/v5"
+)
+
+type SeriesResponse struct {
+	ID         string         `json:"id"`
+	Title      string         `json:"title"`
+	TotalItems int            `json:"totalItems"`
+	Books      []BookResponse `json:"books"`
+}
+
+// SeriesRouter serves series built from the volumes in the catalog, so a
+// series only lists the books that have already been fetched.
+func SeriesRouter(r chi.Router, catalog *client.Catalog) {
+	r.Get("/series/{id}", getSeries(catalog))
+}
+
+func getSeries(catalog *client.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		books := catalog.Series(id)
+
+		// Unknown series
+		if len(books) == 0 {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		// Format response in reading order
+		var resp SeriesResponse
+		resp.ID = id
+		resp.TotalItems = len(books)
+		for _, book := range books {
+			var br BookResponse
+			br.fromItem(book)
+			if resp.Title == "" && br.Series != nil {
+				resp.Title = br.Series.Title
+			}
+			resp.Books = append(resp.Books, br)
+		}
+
+		render(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSeriesRouter(items ...model.GoogleBookItem) http.Handler {
+	r := chi.NewRouter()
+	catalog := client.NewCatalog()
+	catalog.Add(items...)
+	SeriesRouter(r, catalog)
+	return r
+}
+
+func TestSeriesRouter(t *testing.T) {
+	r := setupSeriesRouter(
+		model.GoogleBookItem{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero (Sprawl #2)"}},
+		model.GoogleBookItem{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer (Sprawl #1)"}},
+	)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{
+			name:           "GET:/series/{id} with a known series",
+			path:           "/series/sprawl",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"neuromancer", "count-zero"},
+		},
+		{
+			name:           "GET:/series/{id} with an unknown series",
+			path:           "/series/bridge",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp SeriesResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "Sprawl", resp.Title)
+				ids := []string{}
+				for i, book := range resp.Books {
+					ids = append(ids, book.ID)
+					assert.Equal(t, i+1, book.Series.Position)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+		})
+	}
+}
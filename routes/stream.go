@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+)
+
+const ndjsonMediaType = "application/x-ndjson"
+
+// AuthorStreamTrailer is the last record of a streamed author search, and the
+// data of the SSE done event. Books are streamed before the totals are known,
+// so they are reported here.
+type AuthorStreamTrailer struct {
+	Trailer      bool   `json:"trailer"`
+	Author       string `json:"author"`
+	TotalItems   int    `json:"totalItems"`
+	Streamed     int    `json:"streamed"`
+	Filtered     int    `json:"filtered"`
+	HasMorePages bool   `json:"hasMorePages"`
+	Error        string `json:"error,omitempty"`
+}
+
+// acceptsMediaType reports whether the Accept header lists the media type.
+func acceptsMediaType(r *http.Request, mediaType string) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if parsed, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && parsed == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// streamAuthorNDJSON writes one BookResponse per line as each page arrives,
+// then an AuthorStreamTrailer. Books keep their order within a page but pages
+// are written in the order they arrive, and editions split across pages are
+// not regrouped. Upstream failures after the stream has started are reported
+// in the trailer.
+func streamAuthorNDJSON(w http.ResponseWriter, r *http.Request, bookClient client.BookClientInterface, bookReq client.GoogleBookRequest) {
+	w.Header().Set("Content-Type", ndjsonMediaType)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	trailer := AuthorStreamTrailer{Trailer: true, Author: bookReq.Author}
+	sent := map[string]bool{}
+	var errs []error
+	for page := range client.StreamAuthorPages(r.Context(), bookClient, bookReq) {
+		if page.Err != nil {
+			errs = append(errs, page.Err)
+			continue
+		}
+		if trailer.TotalItems == 0 {
+			trailer.TotalItems = page.Response.TotalItems
+		}
+		trailer.Filtered += page.Response.Filtered
+		for _, br := range newPageBooks(page.Response, sent, bookReq.Debug) {
+			if err := encoder.Encode(br); err != nil {
+				slog.Error(err.Error())
+				return
+			}
+			trailer.Streamed++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	trailer.HasMorePages = client.HasMorePages(trailer.TotalItems, bookReq)
+	if err := errors.Join(errs...); err != nil {
+		slog.Error(err.Error())
+		trailer.Error = err.Error()
+	}
+	if err := encoder.Encode(trailer); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// newPageBooks converts the works on a page that have not been sent yet.
+func newPageBooks(page model.GoogleBookResponse, sent map[string]bool, debug bool) []BookResponse {
+	books := []BookResponse{}
+	for _, book := range client.UnsentWorks(page, sent) {
+		var br BookResponse
+		br.fromItem(book)
+		if debug {
+			br.Relevance = book.Relevance
+		}
+		books = append(books, br)
+	}
+	return books
+}
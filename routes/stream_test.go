@@ -0,0 +1,129 @@
+package routes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// GatedMockClient holds back every page after the first until release is
+// closed.
+type GatedMockClient struct {
+	PagedMockClient
+	release chan struct{}
+}
+
+func (cli GatedMockClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	if request.Start > 0 {
+		<-cli.release
+	}
+	return cli.PagedMockClient.ByAuthor(ctx, request)
+}
+
+// ErrorPageMockClient fails every page after the first.
+type ErrorPageMockClient struct {
+	PagedMockClient
+}
+
+func (cli ErrorPageMockClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	if request.Start > 0 {
+		return model.GoogleBookResponse{}, errors.New("test-error")
+	}
+	return cli.PagedMockClient.ByAuthor(ctx, request)
+}
+
+func authorPages() map[int]model.GoogleBookResponse {
+	book := func(id string) model.GoogleBookItem {
+		return model.GoogleBookItem{ID: id, VolumeInfo: model.GoogleBookVolumeInfo{Title: id, Authors: []string{"test-author"}}}
+	}
+	return map[int]model.GoogleBookResponse{
+		0: {TotalItems: 5, Items: []model.GoogleBookItem{book("a"), book("b")}},
+		2: {TotalItems: 5, Items: []model.GoogleBookItem{book("c"), book("a")}},
+	}
+}
+
+func authorStreamRequest(t *testing.T, url string, accept string) *http.Request {
+	body, _ := json.Marshal(client.GoogleBookRequest{Author: "test-author", Limit: 2, Pages: 1})
+	req, err := http.NewRequest("POST", url+"/books/author", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Accept", accept)
+	return req
+}
+
+func readNDJSON(t *testing.T, body string) ([]string, AuthorStreamTrailer) {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	ids := []string{}
+	for _, line := range lines[:len(lines)-1] {
+		var book BookResponse
+		assert.NoError(t, json.Unmarshal([]byte(line), &book))
+		ids = append(ids, book.ID)
+	}
+	var trailer AuthorStreamTrailer
+	assert.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &trailer))
+	return ids, trailer
+}
+
+func TestQueryByAuthor_ndjson(t *testing.T) {
+	r := chi.NewRouter()
+	BooksRouter(r, PagedMockClient{Pages: authorPages()}, nil, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, authorStreamRequest(t, "", "application/json;q=0.5, application/x-ndjson"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ndjsonMediaType, w.Header().Get("Content-Type"))
+	ids, trailer := readNDJSON(t, w.Body.String())
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, ids)
+	assert.Equal(t, AuthorStreamTrailer{Trailer: true, Author: "test-author", TotalItems: 5, Streamed: 3, HasMorePages: true}, trailer)
+}
+
+func TestQueryByAuthor_ndjsonPageError(t *testing.T) {
+	r := chi.NewRouter()
+	BooksRouter(r, ErrorPageMockClient{PagedMockClient{Pages: authorPages()}}, nil, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, authorStreamRequest(t, "", ndjsonMediaType))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	ids, trailer := readNDJSON(t, w.Body.String())
+	assert.Equal(t, []string{"a", "b"}, ids)
+	assert.Equal(t, 2, trailer.Streamed)
+	assert.Equal(t, "test-error", trailer.Error)
+}
+
+func TestQueryByAuthor_ndjsonStreamsFirstPageEarly(t *testing.T) {
+	cli := GatedMockClient{PagedMockClient: PagedMockClient{Pages: authorPages()}, release: make(chan struct{})}
+	r := chi.NewRouter()
+	BooksRouter(r, cli, nil, nil)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Do(authorStreamRequest(t, server.URL, ndjsonMediaType))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The first page arrives while the second is still held back
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	var book BookResponse
+	assert.NoError(t, json.Unmarshal([]byte(line), &book))
+	assert.Equal(t, "a", book.ID)
+
+	close(cli.release)
+	rest := new(strings.Builder)
+	_, err = reader.WriteTo(rest)
+	assert.NoError(t, err)
+	ids, trailer := readNDJSON(t, rest.String())
+	assert.Equal(t, []string{"b", "c"}, ids)
+	assert.Equal(t, 3, trailer.Streamed)
+}
@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/book-learn/search"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = search.MaxSuggestions
+)
+
+type SuggestResponse struct {
+	Prefix      string              `json:"prefix"`
+	Language    string              `json:"language,omitempty"`
+	Suggestions []search.Suggestion `json:"suggestions"`
+}
+
+// SuggestRouter serves type-ahead suggestions from titles and authors the
+// service has already seen. It never calls Google.
+func SuggestRouter(r chi.Router, suggester *search.Suggester) {
+	r.Get("/suggest", suggest(suggester))
+}
+
+func suggest(suggester *search.Suggester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefix := r.URL.Query().Get("prefix")
+		if strings.TrimSpace(prefix) == "" {
+			http.Error(w, "prefix is required", http.StatusBadRequest)
+			return
+		}
+		limit := defaultSuggestLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > maxSuggestLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxSuggestLimit), http.StatusBadRequest)
+				return
+			}
+		}
+		language := r.URL.Query().Get("lang")
+
+		suggestions := suggester.Suggest(prefix, language, limit)
+		// No results
+		if len(suggestions) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		resp := SuggestResponse{Prefix: prefix, Language: language, Suggestions: suggestions}
+		render(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	model "example.com/book-learn/models"
+	"example.com/book-learn/search"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSuggestRouter(items ...model.GoogleBookItem) http.Handler {
+	r := chi.NewRouter()
+	suggester := search.NewSuggester()
+	suggester.AddVolumes(items)
+	suggester.Record(items[:1])
+	SuggestRouter(r, suggester)
+	return r
+}
+
+func TestSuggestRouter(t *testing.T) {
+	r := setupSuggestRouter(
+		model.GoogleBookItem{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}, Language: "en"}},
+		model.GoogleBookItem{ID: "nova", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Nova", Authors: []string{"Samuel R. Delany"}, Language: "en"}},
+		model.GoogleBookItem{ID: "neuromante", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromante", Authors: []string{"William Gibson"}, Language: "es"}},
+	)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expected       []string
+	}{
+		{
+			name:           "GET:/suggest ranks by popularity",
+			path:           "/suggest?prefix=n",
+			expectedStatus: http.StatusOK,
+			expected:       []string{"Neuromancer", "Nova", "Neuromante"},
+		},
+		{
+			name:           "GET:/suggest with a language",
+			path:           "/suggest?prefix=neuro&lang=es",
+			expectedStatus: http.StatusOK,
+			expected:       []string{"Neuromante"},
+		},
+		{
+			name:           "GET:/suggest with a limit",
+			path:           "/suggest?prefix=n&limit=1",
+			expectedStatus: http.StatusOK,
+			expected:       []string{"Neuromancer"},
+		},
+		{
+			name:           "GET:/suggest with no match",
+			path:           "/suggest?prefix=idoru",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "GET:/suggest without a prefix",
+			path:           "/suggest",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "GET:/suggest with an invalid limit",
+			path:           "/suggest?prefix=n&limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp SuggestResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				texts := []string{}
+				for _, suggestion := range resp.Suggestions {
+					texts = append(texts, suggestion.Text)
+				}
+				assert.Equal(t, tt.expected, texts)
+			}
+		})
+	}
+}
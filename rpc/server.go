@@ -0,0 +1,234 @@
+// Package rpc serves the book client over gRPC for backend services. Requests
+// are validated, filtered and paged the same way as the REST routes.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"example.com/book-learn/bookpb"
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultLimit is Google's page size when maxResults is not given.
+	defaultLimit = 10
+	// maxLimit is the most results Google returns per request.
+	maxLimit = 40
+	// maxPages bounds an author search to ten concurrent upstream requests,
+	// as pages counts the pages after the first.
+	maxPages = 9
+)
+
+type server struct {
+	bookpb.UnimplementedBookServiceServer
+	api client.BookClientInterface
+}
+
+// Register adds the BookService to a gRPC server.
+func Register(registrar grpc.ServiceRegistrar, api client.BookClientInterface) {
+	bookpb.RegisterBookServiceServer(registrar, &server{api: api})
+}
+
+func (s *server) SearchByAuthor(ctx context.Context, req *bookpb.SearchByAuthorRequest) (*bookpb.SearchResponse, error) {
+	bookReq, err := authorRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := client.FetchAuthorPages(ctx, s.api, bookReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	books, totalItems := client.MergeAuthorPages(pages, bookReq.SortOrder(client.SortPublishedDesc))
+	return &bookpb.SearchResponse{
+		TotalItems:   int32(totalItems),
+		Books:        toBooks(books),
+		HasMorePages: client.HasMorePages(totalItems, bookReq),
+	}, nil
+}
+
+func (s *server) SearchByTitle(ctx context.Context, req *bookpb.SearchByTitleRequest) (*bookpb.SearchResponse, error) {
+	if strings.TrimSpace(req.GetTitle()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	bookReq := client.GoogleBookRequest{
+		Title:           req.GetTitle(),
+		PublishedAfter:  req.GetPublishedAfter(),
+		PublishedBefore: req.GetPublishedBefore(),
+		SortBy:          req.GetSortBy(),
+	}
+	if err := setPage(&bookReq, req.GetStart(), req.GetLimit(), 0); err != nil {
+		return nil, err
+	}
+
+	books, err := s.api.ByTitle(ctx, bookReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &bookpb.SearchResponse{
+		TotalItems:   int32(books.TotalItems),
+		Books:        toBooks(books.Items),
+		HasMorePages: bookReq.Start+bookReq.Limit < books.TotalItems,
+	}, nil
+}
+
+func (s *server) GetVolume(ctx context.Context, req *bookpb.GetVolumeRequest) (*bookpb.Book, error) {
+	if strings.TrimSpace(req.GetId()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	book, err := s.api.ByID(ctx, req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toBook(book), nil
+}
+
+// StreamAuthorBooks sends works as their pages arrive, like the NDJSON and
+// SSE author streams, then a summary with the totals.
+func (s *server) StreamAuthorBooks(req *bookpb.SearchByAuthorRequest, stream bookpb.BookService_StreamAuthorBooksServer) error {
+	bookReq, err := authorRequest(req)
+	if err != nil {
+		return err
+	}
+
+	summary := &bookpb.StreamSummary{}
+	sent := map[string]bool{}
+	var errs []error
+	for page := range client.StreamAuthorPages(stream.Context(), s.api, bookReq) {
+		if page.Err != nil {
+			errs = append(errs, page.Err)
+			continue
+		}
+		if summary.TotalItems == 0 {
+			summary.TotalItems = int32(page.Response.TotalItems)
+		}
+		summary.Filtered += int32(page.Response.Filtered)
+		for _, work := range client.UnsentWorks(page.Response, sent) {
+			book := &bookpb.StreamAuthorBooksResponse{Result: &bookpb.StreamAuthorBooksResponse_Book{Book: toBook(work)}}
+			if err := stream.Send(book); err != nil {
+				return err
+			}
+			summary.Streamed++
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		slog.Error(err.Error())
+		return status.Errorf(codes.Unavailable, "%d of %d pages could not be fetched", len(errs), bookReq.Pages+1)
+	}
+	summary.HasMorePages = client.HasMorePages(int(summary.TotalItems), bookReq)
+	return stream.Send(&bookpb.StreamAuthorBooksResponse{Result: &bookpb.StreamAuthorBooksResponse_Summary{Summary: summary}})
+}
+
+func authorRequest(req *bookpb.SearchByAuthorRequest) (client.GoogleBookRequest, error) {
+	if strings.TrimSpace(req.GetAuthor()) == "" {
+		return client.GoogleBookRequest{}, status.Error(codes.InvalidArgument, "author is required")
+	}
+	bookReq := client.GoogleBookRequest{
+		Author:          req.GetAuthor(),
+		PublishedAfter:  req.GetPublishedAfter(),
+		PublishedBefore: req.GetPublishedBefore(),
+		SortBy:          req.GetSortBy(),
+	}
+	if err := setPage(&bookReq, req.GetStart(), req.GetLimit(), req.GetPages()); err != nil {
+		return client.GoogleBookRequest{}, err
+	}
+	return bookReq, nil
+}
+
+// setPage checks the paging fields and validates the finished request, so
+// a bad request is INVALID_ARGUMENT before anything is fetched.
+func setPage(bookReq *client.GoogleBookRequest, start int32, limit int32, pages int32) error {
+	if start < 0 {
+		return status.Error(codes.InvalidArgument, "start must not be negative")
+	}
+	if limit < 0 || limit > maxLimit {
+		return status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxLimit)
+	}
+	if pages < 0 || pages > maxPages {
+		return status.Errorf(codes.InvalidArgument, "pages must be between 0 and %d", maxPages)
+	}
+	bookReq.Start = int(start)
+	bookReq.Limit = int(limit)
+	if bookReq.Limit == 0 {
+		bookReq.Limit = defaultLimit
+	}
+	bookReq.Pages = int(pages)
+
+	if err := bookReq.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// statusError maps book client errors to gRPC codes. Unexpected errors are
+// logged and their details kept from the caller, as the REST routes do.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, client.ErrVolumeNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, client.ErrRateLimited):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	slog.Error(err.Error())
+	return status.Error(codes.Internal, "books could not be fetched")
+}
+
+func toBooks(items []model.GoogleBookItem) []*bookpb.Book {
+	books := make([]*bookpb.Book, 0, len(items))
+	for _, item := range items {
+		books = append(books, toBook(item))
+	}
+	return books
+}
+
+func toBook(item model.GoogleBookItem) *bookpb.Book {
+	vi := item.VolumeInfo
+	book := &bookpb.Book{
+		Id:                  item.ID,
+		Title:               vi.Title,
+		Subtitle:            vi.Subtitle,
+		Authors:             vi.Authors,
+		Publisher:           vi.Publisher,
+		PublishedDate:       vi.PublishedDate,
+		Description:         vi.Description,
+		PageCount:           int32(vi.PageCount),
+		Categories:          vi.Categories,
+		Language:            vi.Language,
+		PreviewLink:         vi.PreviewLink,
+		InfoLink:            vi.InfoLink,
+		CanonicalVolumeLink: vi.CanonicalVolumeLink,
+		ImageLinks: &bookpb.ImageLinks{
+			SmallThumbnail: vi.ImageLinks.SmallThumbnail,
+			Thumbnail:      vi.ImageLinks.Thumbnail,
+		},
+		Editions: toBooks(item.Editions),
+	}
+	if date := model.ParsePublicationDate(vi.PublishedDate); date.Precision >= model.DatePrecisionYear {
+		book.PublishedYear = int32(date.Year)
+	}
+	for _, id := range vi.IndustryIdentifiers {
+		book.IndustryIdentifiers = append(book.IndustryIdentifiers, &bookpb.IndustryIdentifier{Type: id.Type, Identifier: id.Identifier})
+	}
+	for _, category := range client.NormalizeCategories(vi.Categories) {
+		book.NormalizedCategories = append(book.NormalizedCategories, &bookpb.Category{
+			Slug:   category.Slug,
+			Name:   category.Name,
+			Parent: category.Parent,
+			Path:   category.Path,
+		})
+	}
+	if series := client.DetectSeries(item); series != nil {
+		book.Series = &bookpb.Series{Id: series.ID, Title: series.Title, Position: int32(series.Position), Source: series.Source}
+	}
+	return book
+}
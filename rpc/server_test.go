@@ -0,0 +1,250 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"example.com/book-learn/bookpb"
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// mockClient answers author searches from Pages, keyed by request.Start,
+// and fails the pages in Failing.
+type mockClient struct {
+	Pages   map[int]model.GoogleBookResponse
+	Failing map[int]error
+	Titles  model.GoogleBookResponse
+	Volumes map[string]model.GoogleBookItem
+	Err     error
+}
+
+func (cli mockClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	if err := cli.Failing[request.Start]; err != nil {
+		return model.GoogleBookResponse{}, err
+	}
+	return cli.Pages[request.Start], nil
+}
+
+func (cli mockClient) ByTitle(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return cli.Titles, cli.Err
+}
+
+func (cli mockClient) ByID(ctx context.Context, id string) (model.GoogleBookItem, error) {
+	if cli.Err != nil {
+		return model.GoogleBookItem{}, cli.Err
+	}
+	if volume, ok := cli.Volumes[id]; ok {
+		return volume, nil
+	}
+	return model.GoogleBookItem{}, client.ErrVolumeNotFound
+}
+
+func (cli mockClient) ByQuery(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	return model.GoogleBookResponse{}, cli.Err
+}
+
+// dial serves the BookService over an in-memory connection.
+func dial(t *testing.T, api client.BookClientInterface) bookpb.BookServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	Register(server, api)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return bookpb.NewBookServiceClient(conn)
+}
+
+func volume(id string, title string, date string) model.GoogleBookItem {
+	return model.GoogleBookItem{ID: id, VolumeInfo: model.GoogleBookVolumeInfo{
+		Title:         title,
+		Authors:       []string{"William Gibson"},
+		PublishedDate: date,
+		Categories:    []string{"Fiction / Science Fiction / Cyberpunk"},
+		IndustryIdentifiers: []model.GoogleBookIndustryIdentifier{
+			{Type: "ISBN_13", Identifier: "9780441569595"},
+		},
+	}}
+}
+
+var authorPages = map[int]model.GoogleBookResponse{
+	0: {TotalItems: 25, Filtered: 1, Items: []model.GoogleBookItem{
+		volume("neuromancer", "Neuromancer", "1984-07-01"),
+		volume("peripheral", "The Peripheral (Jackpot #1)", "2014-10-28"),
+	}},
+	10: {TotalItems: 25, Filtered: 2, Items: []model.GoogleBookItem{
+		volume("agency", "Agency (Jackpot #2)", "2020-01-21"),
+		// Another edition of a work on the first page
+		volume("neuromancer-reissue", "Neuromancer", "2000"),
+	}},
+}
+
+func bookIDs(books []*bookpb.Book) []string {
+	ids := []string{}
+	for _, book := range books {
+		ids = append(ids, book.GetId())
+	}
+	return ids
+}
+
+func TestSearchByAuthor(t *testing.T) {
+	books := dial(t, mockClient{Pages: authorPages})
+
+	// Pages are merged, regrouped into works and sorted newest first
+	resp, err := books.SearchByAuthor(context.Background(), &bookpb.SearchByAuthorRequest{Author: "William Gibson", Pages: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(25), resp.GetTotalItems())
+	assert.True(t, resp.GetHasMorePages())
+	assert.Equal(t, []string{"agency", "peripheral", "neuromancer"}, bookIDs(resp.GetBooks()))
+
+	neuromancer := resp.GetBooks()[2]
+	assert.Equal(t, []string{"neuromancer-reissue"}, bookIDs(neuromancer.GetEditions()))
+	assert.Equal(t, int32(1984), neuromancer.GetPublishedYear())
+	assert.Equal(t, "9780441569595", neuromancer.GetIndustryIdentifiers()[0].GetIdentifier())
+	assert.Equal(t, "cyberpunk", neuromancer.GetNormalizedCategories()[0].GetSlug())
+	assert.Equal(t, &bookpb.Series{Id: "jackpot", Title: "Jackpot", Position: 1, Source: client.SeriesSourceTitle}, stripState(resp.GetBooks()[1].GetSeries()))
+
+	// Sorting is shared with the REST routes
+	resp, err = books.SearchByAuthor(context.Background(), &bookpb.SearchByAuthorRequest{Author: "William Gibson", Pages: 1, SortBy: client.SortPublishedAsc})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"neuromancer", "peripheral", "agency"}, bookIDs(resp.GetBooks()))
+}
+
+// stripState copies a series without the message state testify would compare.
+func stripState(series *bookpb.Series) *bookpb.Series {
+	return &bookpb.Series{Id: series.GetId(), Title: series.GetTitle(), Position: series.GetPosition(), Source: series.GetSource()}
+}
+
+func TestSearchByAuthor_errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		request         *bookpb.SearchByAuthorRequest
+		failing         map[int]error
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{name: "missing author", request: &bookpb.SearchByAuthorRequest{}, expectedCode: codes.InvalidArgument, expectedMessage: "author is required"},
+		{name: "bad limit", request: &bookpb.SearchByAuthorRequest{Author: "William Gibson", Limit: 41}, expectedCode: codes.InvalidArgument, expectedMessage: "limit must be between 1 and 40"},
+		{name: "bad pages", request: &bookpb.SearchByAuthorRequest{Author: "William Gibson", Pages: -1}, expectedCode: codes.InvalidArgument, expectedMessage: "pages must be between 0 and 9"},
+		{name: "bad date", request: &bookpb.SearchByAuthorRequest{Author: "William Gibson", PublishedAfter: "soon"}, expectedCode: codes.InvalidArgument, expectedMessage: `PublishedAfter: unrecognised date "soon"`},
+		{name: "bad sort", request: &bookpb.SearchByAuthorRequest{Author: "William Gibson", SortBy: "color"}, expectedCode: codes.InvalidArgument},
+		{name: "rate limited", request: &bookpb.SearchByAuthorRequest{Author: "William Gibson"}, failing: map[int]error{0: client.ErrRateLimited}, expectedCode: codes.ResourceExhausted},
+		{name: "client error", request: &bookpb.SearchByAuthorRequest{Author: "William Gibson"}, failing: map[int]error{0: errors.New("test-error")}, expectedCode: codes.Internal, expectedMessage: "books could not be fetched"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books := dial(t, mockClient{Pages: authorPages, Failing: tt.failing})
+			_, err := books.SearchByAuthor(context.Background(), tt.request)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedMessage != "" {
+				assert.Equal(t, tt.expectedMessage, status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestSearchByTitle(t *testing.T) {
+	books := dial(t, mockClient{Titles: model.GoogleBookResponse{TotalItems: 12, Items: []model.GoogleBookItem{volume("neuromancer", "Neuromancer", "1984")}}})
+
+	resp, err := books.SearchByTitle(context.Background(), &bookpb.SearchByTitleRequest{Title: "Neuromancer"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"neuromancer"}, bookIDs(resp.GetBooks()))
+	assert.True(t, resp.GetHasMorePages())
+
+	resp, err = books.SearchByTitle(context.Background(), &bookpb.SearchByTitleRequest{Title: "Neuromancer", Start: 10})
+	assert.NoError(t, err)
+	assert.False(t, resp.GetHasMorePages())
+
+	_, err = books.SearchByTitle(context.Background(), &bookpb.SearchByTitleRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetVolume(t *testing.T) {
+	books := dial(t, mockClient{Volumes: map[string]model.GoogleBookItem{"neuromancer": volume("neuromancer", "Neuromancer", "1984")}})
+
+	book, err := books.GetVolume(context.Background(), &bookpb.GetVolumeRequest{Id: "neuromancer"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Neuromancer", book.GetTitle())
+	assert.Equal(t, []string{"William Gibson"}, book.GetAuthors())
+
+	_, err = books.GetVolume(context.Background(), &bookpb.GetVolumeRequest{Id: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = books.GetVolume(context.Background(), &bookpb.GetVolumeRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	broken := dial(t, mockClient{Err: errors.New("test-error")})
+	_, err = broken.GetVolume(context.Background(), &bookpb.GetVolumeRequest{Id: "neuromancer"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+// receive reads a stream to the end, returning the books, the summary and
+// the error that ended it, if any.
+func receive(t *testing.T, stream bookpb.BookService_StreamAuthorBooksClient) ([]*bookpb.Book, *bookpb.StreamSummary, error) {
+	var books []*bookpb.Book
+	var summary *bookpb.StreamSummary
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return books, summary, nil
+		}
+		if err != nil {
+			return books, summary, err
+		}
+		if book := resp.GetBook(); book != nil {
+			assert.Nil(t, summary, "books after the summary")
+			books = append(books, book)
+		}
+		if resp.GetSummary() != nil {
+			summary = resp.GetSummary()
+		}
+	}
+}
+
+func TestStreamAuthorBooks(t *testing.T) {
+	books := dial(t, mockClient{Pages: authorPages})
+
+	stream, err := books.StreamAuthorBooks(context.Background(), &bookpb.SearchByAuthorRequest{Author: "William Gibson", Pages: 1})
+	assert.NoError(t, err)
+	streamed, summary, err := receive(t, stream)
+	assert.NoError(t, err)
+	// Pages arrive in any order and editions split across pages are not
+	// regrouped, as in the REST streams
+	assert.ElementsMatch(t, []string{"neuromancer", "neuromancer-reissue", "peripheral", "agency"}, bookIDs(streamed))
+	assert.Equal(t, int32(25), summary.GetTotalItems())
+	assert.Equal(t, int32(4), summary.GetStreamed())
+	assert.Equal(t, int32(3), summary.GetFiltered())
+	assert.True(t, summary.GetHasMorePages())
+}
+
+func TestStreamAuthorBooks_errors(t *testing.T) {
+	books := dial(t, mockClient{Pages: authorPages, Failing: map[int]error{10: errors.New("test-error")}})
+
+	stream, err := books.StreamAuthorBooks(context.Background(), &bookpb.SearchByAuthorRequest{Author: "William Gibson", Pages: 1})
+	assert.NoError(t, err)
+	streamed, summary, err := receive(t, stream)
+	// The books that could be fetched are still sent
+	assert.Equal(t, []string{"neuromancer", "peripheral"}, bookIDs(streamed))
+	assert.Nil(t, summary)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "1 of 2 pages could not be fetched", status.Convert(err).Message())
+
+	stream, err = books.StreamAuthorBooks(context.Background(), &bookpb.SearchByAuthorRequest{})
+	assert.NoError(t, err)
+	_, _, err = receive(t, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
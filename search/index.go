@@ -0,0 +1,289 @@
+// Package search keeps an in-memory full-text index of the volumes the
+// service has seen so that searches can be answered without calling Google.
+// It is pure Go and needs no external storage.
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+
+	model "example.com/book-learn/models"
+)
+
+const (
+	FieldTitle       = "title"
+	FieldAuthors     = "authors"
+	FieldDescription = "description"
+	FieldCategories  = "categories"
+)
+
+var fields = []string{FieldTitle, FieldAuthors, FieldDescription, FieldCategories}
+
+// DefaultBoosts weights matches in short, identifying fields above matches
+// buried in a description.
+var DefaultBoosts = map[string]float64{
+	FieldTitle:       3,
+	FieldAuthors:     2,
+	FieldCategories:  1.5,
+	FieldDescription: 1,
+}
+
+const (
+	// BM25 term frequency saturation and length normalisation.
+	defaultK1 = 1.2
+	defaultB  = 0.75
+)
+
+// Index is an inverted index over the title, authors, description and
+// categories of each volume, scored with BM25 per field. Register Add with
+// Catalog.OnAdd and Remove with Catalog.OnEvict to keep it up to date. It is
+// safe for concurrent use.
+type Index struct {
+	Boosts map[string]float64
+	K1     float64
+	B      float64
+
+	mu sync.RWMutex
+	// postings maps field, then term, then volume ID to the term's positions.
+	postings     map[string]map[string]map[string][]int
+	lengths      map[string]map[string]int
+	totalLengths map[string]int
+	volumes      map[string]model.GoogleBookItem
+	// sequence is the order each volume was first indexed in, which breaks
+	// ties between equal scores.
+	sequence map[string]int
+	seen     int
+}
+
+func NewIndex() *Index {
+	ix := &Index{
+		Boosts:       DefaultBoosts,
+		K1:           defaultK1,
+		B:            defaultB,
+		postings:     map[string]map[string]map[string][]int{},
+		lengths:      map[string]map[string]int{},
+		totalLengths: map[string]int{},
+		volumes:      map[string]model.GoogleBookItem{},
+		sequence:     map[string]int{},
+	}
+	for _, field := range fields {
+		ix.postings[field] = map[string]map[string][]int{}
+		ix.lengths[field] = map[string]int{}
+	}
+	return ix
+}
+
+// Hit is a volume matching a query and its score.
+type Hit struct {
+	Book  model.GoogleBookItem
+	Score float64
+}
+
+// Add indexes the volumes, replacing any earlier copy with the same ID.
+func (ix *Index) Add(items []model.GoogleBookItem) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, item := range items {
+		if item.ID == "" {
+			continue
+		}
+		if _, ok := ix.volumes[item.ID]; ok {
+			ix.remove(item.ID)
+		} else {
+			ix.sequence[item.ID] = ix.seen
+			ix.seen++
+		}
+		item.Editions = nil
+		item.Relevance = nil
+		ix.volumes[item.ID] = item
+		for field, words := range fieldTokens(item) {
+			for position, word := range words {
+				if word == "" {
+					continue
+				}
+				if ix.postings[field][word] == nil {
+					ix.postings[field][word] = map[string][]int{}
+				}
+				ix.postings[field][word][item.ID] = append(ix.postings[field][word][item.ID], position)
+			}
+			ix.lengths[field][item.ID] = len(words)
+			ix.totalLengths[field] += len(words)
+		}
+	}
+}
+
+// Remove drops the volumes with the given IDs from the index.
+func (ix *Index) Remove(ids []string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := ix.volumes[id]; !ok {
+			continue
+		}
+		ix.remove(id)
+		delete(ix.volumes, id)
+		delete(ix.sequence, id)
+	}
+}
+
+func (ix *Index) remove(id string) {
+	for field, words := range fieldTokens(ix.volumes[id]) {
+		for _, word := range words {
+			delete(ix.postings[field][word], id)
+			if len(ix.postings[field][word]) == 0 {
+				delete(ix.postings[field], word)
+			}
+		}
+		ix.totalLengths[field] -= ix.lengths[field][id]
+		delete(ix.lengths[field], id)
+	}
+}
+
+// Len returns the number of indexed volumes.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.volumes)
+}
+
+// Search returns up to limit volumes matching every term and phrase in the
+// query, best first. Ties keep the order the volumes were indexed in.
+func (ix *Index) Search(query Query, limit int) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if query.IsEmpty() {
+		return []Hit{}
+	}
+
+	hits := []Hit{}
+	for _, id := range ix.candidates(query) {
+		if !ix.matches(id, query) {
+			continue
+		}
+		score := 0.0
+		for _, word := range query.Words() {
+			score += ix.score(id, word)
+		}
+		hits = append(hits, Hit{Book: ix.volumes[id], Score: score})
+	}
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(ix.sequence[a.Book.ID], ix.sequence[b.Book.ID])
+	})
+	return hits[:min(limit, len(hits))]
+}
+
+// candidates returns the volumes containing the query's rarest required
+// word in any field. Every match must contain that word, so only these
+// volumes need to be checked and scored.
+func (ix *Index) candidates(query Query) []string {
+	required := slices.Clone(query.Terms)
+	for _, phrase := range query.Phrases {
+		required = append(required, phrase...)
+	}
+
+	var rarest map[string]bool
+	for _, word := range required {
+		documents := map[string]bool{}
+		for _, field := range fields {
+			for id := range ix.postings[field][word] {
+				documents[id] = true
+			}
+		}
+		if rarest == nil || len(documents) < len(rarest) {
+			rarest = documents
+		}
+		if len(rarest) == 0 {
+			break
+		}
+	}
+
+	ids := make([]string, 0, len(rarest))
+	for id := range rarest {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (ix *Index) matches(id string, query Query) bool {
+	for _, term := range query.Terms {
+		if !slices.ContainsFunc(fields, func(field string) bool {
+			return len(ix.postings[field][term][id]) > 0
+		}) {
+			return false
+		}
+	}
+	for _, phrase := range query.Phrases {
+		if !slices.ContainsFunc(fields, func(field string) bool {
+			return ix.containsPhrase(field, id, phrase)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsPhrase reports whether the words appear next to each other, in
+// order, in the field.
+func (ix *Index) containsPhrase(field string, id string, phrase []string) bool {
+	for _, start := range ix.postings[field][phrase[0]][id] {
+		found := true
+		for offset, word := range phrase[1:] {
+			if !slices.Contains(ix.postings[field][word][id], start+offset+1) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// score sums the boosted BM25 score of the term in each field of the volume.
+func (ix *Index) score(id string, term string) float64 {
+	total := float64(len(ix.volumes))
+	score := 0.0
+	for _, field := range fields {
+		frequency := float64(len(ix.postings[field][term][id]))
+		if frequency == 0 {
+			continue
+		}
+		documents := float64(len(ix.postings[field][term]))
+		idf := math.Log(1 + (total-documents+0.5)/(documents+0.5))
+		averageLength := float64(ix.totalLengths[field]) / total
+		norm := 1 - ix.B + ix.B*float64(ix.lengths[field][id])/averageLength
+		score += ix.Boosts[field] * idf * frequency * (ix.K1 + 1) / (frequency + ix.K1*norm)
+	}
+	return score
+}
+
+// fieldTokens tokenizes each indexed field. Multi-valued fields are joined so
+// that a phrase cannot span two authors or two categories.
+func fieldTokens(item model.GoogleBookItem) map[string][]string {
+	info := item.VolumeInfo
+	tokens := map[string][]string{
+		FieldTitle:       tokenize(info.Title + " " + info.Subtitle),
+		FieldDescription: tokenize(info.Description),
+	}
+	tokens[FieldAuthors] = joinValues(info.Authors)
+	tokens[FieldCategories] = joinValues(info.Categories)
+	return tokens
+}
+
+// joinValues tokenizes each value, leaving a gap in positions between them.
+func joinValues(values []string) []string {
+	words := []string{}
+	for i, value := range values {
+		if i > 0 {
+			words = append(words, "")
+		}
+		words = append(words, tokenize(value)...)
+	}
+	return words
+}
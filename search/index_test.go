@@ -0,0 +1,164 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func pactIndex(t *testing.T) *Index {
+	catalog := client.NewCatalog()
+	index := NewIndex()
+	catalog.OnAdd(index.Add)
+	bc := client.GoogleBookClient{PactMode: true, Catalog: catalog}
+	_, err := bc.ByAuthor(context.Background(), client.GoogleBookRequest{Author: "William Gibson"})
+	assert.NoError(t, err)
+	_, err = bc.ByTitle(context.Background(), client.GoogleBookRequest{Title: "Count Zero"})
+	assert.NoError(t, err)
+	return index
+}
+
+func hitIDs(hits []Hit) []string {
+	ids := []string{}
+	for _, hit := range hits {
+		ids = append(ids, hit.Book.ID)
+	}
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := pactIndex(t)
+	assert.Equal(t, 26, index.Len())
+
+	tests := []struct {
+		name        string
+		query       string
+		expectedIDs []string
+	}{
+		{
+			name:        "title terms",
+			query:       "count zero",
+			expectedIDs: []string{"atw7PgAACAAJ"},
+		},
+		{
+			name:        "every term must match",
+			query:       "boyology gibson",
+			expectedIDs: []string{"hNgmLwEACAAJ", "8j5RvgAACAAJ"},
+		},
+		{
+			name:        "category terms",
+			query:       "cyberpunk",
+			expectedIDs: []string{"atw7PgAACAAJ"},
+		},
+		{
+			name:        "phrase in order",
+			query:       `"two for the seesaw"`,
+			expectedIDs: []string{"P1VRswEACAAJ"},
+		},
+		{
+			name:        "phrase out of order",
+			query:       `"seesaw the for two"`,
+			expectedIDs: []string{},
+		},
+		{
+			name:        "phrase does not span authors",
+			query:       `"gibson william"`,
+			expectedIDs: []string{},
+		},
+		{
+			name:        "unknown term",
+			query:       "neuromancer",
+			expectedIDs: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedIDs, hitIDs(index.Search(ParseQuery(tt.query), 10)))
+		})
+	}
+}
+
+func TestIndex_Search_fieldBoosts(t *testing.T) {
+	index := NewIndex()
+	index.Add([]model.GoogleBookItem{
+		{ID: "mention", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Pattern Recognition", Description: "A sequel of sorts to Neuromancer."}},
+		{ID: "title", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Description: "The first Sprawl novel."}},
+	})
+
+	hits := index.Search(ParseQuery("neuromancer"), 10)
+
+	assert.Equal(t, []string{"title", "mention"}, hitIDs(hits))
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	index.Boosts = map[string]float64{FieldTitle: 1, FieldDescription: 10}
+	assert.Equal(t, []string{"mention", "title"}, hitIDs(index.Search(ParseQuery("neuromancer"), 10)))
+}
+
+func TestIndex_Add_replaces(t *testing.T) {
+	index := NewIndex()
+	index.Add([]model.GoogleBookItem{{ID: "a", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer"}}})
+	index.Add([]model.GoogleBookItem{{ID: "a", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero"}}})
+
+	assert.Equal(t, 1, index.Len())
+	assert.Empty(t, index.Search(ParseQuery("neuromancer"), 10))
+	assert.Equal(t, []string{"a"}, hitIDs(index.Search(ParseQuery("zero"), 10)))
+	assert.Empty(t, index.Search(Query{}, 10))
+}
+
+func TestIndex_candidates(t *testing.T) {
+	index := NewIndex()
+	index.Add([]model.GoogleBookItem{
+		{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}}},
+		{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}}},
+		{ID: "emma", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Emma", Authors: []string{"Jane Austen"}}},
+	})
+
+	// Only volumes holding the rarest required word are considered
+	assert.Equal(t, []string{"count-zero"}, index.candidates(ParseQuery("gibson zero")))
+	assert.ElementsMatch(t, []string{"neuromancer", "count-zero"}, index.candidates(ParseQuery(`"william gibson"`)))
+	assert.Empty(t, index.candidates(ParseQuery("gibson austen unknown")))
+}
+
+func TestIndexes_followCatalogEvictions(t *testing.T) {
+	catalog := client.NewBoundedCatalog(2)
+	graph := client.NewAuthorGraph()
+	index := NewIndex()
+	suggester := NewSuggester()
+	speller := NewSpeller()
+	catalog.OnAdd(graph.AddVolumes)
+	catalog.OnEvict(graph.RemoveVolumes)
+	catalog.OnAdd(index.Add)
+	catalog.OnEvict(index.Remove)
+	catalog.OnAdd(suggester.AddVolumes)
+	catalog.OnEvict(suggester.RemoveVolumes)
+	catalog.OnAdd(speller.AddVolumes)
+	catalog.OnEvict(speller.RemoveVolumes)
+
+	volume := func(id string, title string, author string) model.GoogleBookItem {
+		return model.GoogleBookItem{ID: id, VolumeInfo: model.GoogleBookVolumeInfo{
+			Title: title, Authors: []string{author}, Categories: []string{"Fiction"}, Language: "en",
+		}}
+	}
+	catalog.Add(volume("neuromancer", "Neuromancer", "William Gibson"), volume("snow-crash", "Snow Crash", "Neal Stephenson"))
+	assert.True(t, graph.Known("William Gibson"))
+	assert.Equal(t, 2, index.Len())
+	assert.Len(t, suggester.Suggest("neu", "en", 10), 1)
+	assert.Equal(t, []string{"Neuromancer"}, speller.Suggest("Neuromancr", 1))
+
+	// Filling the catalog past its limit drops the oldest volume everywhere
+	catalog.Add(volume("embassytown", "Embassytown", "China Miéville"))
+	assert.False(t, graph.Known("William Gibson"))
+	related := graph.Related("Neal Stephenson", 10)
+	if assert.Len(t, related, 1) {
+		assert.Equal(t, "China Miéville", related[0].Name)
+	}
+	assert.Equal(t, 2, index.Len())
+	assert.Empty(t, index.Search(ParseQuery("neuromancer"), 10))
+	assert.Empty(t, suggester.Suggest("neu", "", 10))
+	assert.Empty(t, suggester.Suggest("neu", "en", 10))
+	assert.Len(t, suggester.Suggest("embassy", "en", 10), 1)
+	assert.NotContains(t, speller.Suggest("Neuromancr", 3), "Neuromancer")
+}
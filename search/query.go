@@ -0,0 +1,109 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed search query. Every term and every phrase must match for
+// a volume to be returned.
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+}
+
+// ParseQuery splits a query into bare terms and "quoted phrases". Stop words
+// are dropped from bare terms but kept inside phrases, and an unterminated
+// quote runs to the end of the query.
+func ParseQuery(raw string) Query {
+	query := Query{}
+	parts := strings.Split(raw, `"`)
+	for i, part := range parts {
+		words := tokenize(part)
+		if i%2 == 1 {
+			switch {
+			case len(words) > 1:
+				query.Phrases = append(query.Phrases, words)
+			case len(words) == 1:
+				query.Terms = appendUnique(query.Terms, words[0])
+			}
+			continue
+		}
+		for _, word := range words {
+			if !stopWords[word] {
+				query.Terms = appendUnique(query.Terms, word)
+			}
+		}
+	}
+	return query
+}
+
+// IsEmpty reports whether the query has nothing to search for.
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// Words returns every term in the query, phrase words included, in order.
+func (q Query) Words() []string {
+	words := []string{}
+	for _, phrase := range q.Phrases {
+		for _, word := range phrase {
+			words = appendUnique(words, word)
+		}
+	}
+	for _, term := range q.Terms {
+		words = appendUnique(words, term)
+	}
+	return words
+}
+
+// String renders the query back into its textual form.
+func (q Query) String() string {
+	parts := []string{}
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `"`+strings.Join(phrase, " ")+`"`)
+	}
+	return strings.Join(append(parts, q.Terms...), " ")
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "by": true, "for": true, "in": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "with": true,
+}
+
+// tokenize lowercases text, folds common Latin accents and splits it into
+// words on anything that is not a letter or digit.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(accents.Replace(strings.ToLower(text)), isSeparator)
+}
+
+// Tokenize splits text into the words the index and suggesters match on.
+func Tokenize(text string) []string {
+	return tokenize(text)
+}
+
+var accents = func() *strings.Replacer {
+	folds := map[string]string{
+		"a": "àáâãäåā", "c": "çćč", "e": "èéêëēėę", "i": "ìíîïī", "n": "ñń",
+		"o": "òóôõöøō", "s": "śš", "u": "ùúûüū", "y": "ýÿ", "z": "źżž",
+	}
+	pairs := []string{"æ", "ae", "œ", "oe", "ß", "ss"}
+	for plain, accented := range folds {
+		for _, r := range accented {
+			pairs = append(pairs, string(r), plain)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func appendUnique(words []string, word string) []string {
+	if slices.Contains(words, word) {
+		return words
+	}
+	return append(words, word)
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected Query
+	}{
+		{
+			name:     "bare terms drop stop words and duplicates",
+			raw:      "Letters to my Son, letters",
+			expected: Query{Terms: []string{"letters", "my", "son"}},
+		},
+		{
+			name:     "quoted phrase keeps stop words",
+			raw:      `"letters to my son" gibson`,
+			expected: Query{Terms: []string{"gibson"}, Phrases: [][]string{{"letters", "to", "my", "son"}}},
+		},
+		{
+			name:     "single quoted word is a term",
+			raw:      `"Zero"`,
+			expected: Query{Terms: []string{"zero"}},
+		},
+		{
+			name:     "unterminated quote runs to the end",
+			raw:      `count "two for the`,
+			expected: Query{Terms: []string{"count"}, Phrases: [][]string{{"two", "for", "the"}}},
+		},
+		{
+			name:     "accents are folded",
+			raw:      "Comte Zéro, Œuvres",
+			expected: Query{Terms: []string{"comte", "zero", "oeuvres"}},
+		},
+		{
+			name:     "only stop words",
+			raw:      "the of",
+			expected: Query{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseQuery(tt.raw))
+		})
+	}
+}
+
+func TestQuery_String(t *testing.T) {
+	query := ParseQuery(`gibson "count zero"`)
+
+	assert.Equal(t, `"count zero" gibson`, query.String())
+	assert.Equal(t, []string{"count", "zero", "gibson"}, query.Words())
+	assert.True(t, ParseQuery("  ").IsEmpty())
+}
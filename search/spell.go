@@ -0,0 +1,269 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	model "example.com/book-learn/models"
+)
+
+const (
+	spellingAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// maxPhraseEdits caps how far a whole title or name may be from the query.
+	maxPhraseEdits = 3
+	// minWordForTwoEdits keeps short words from matching almost anything.
+	minWordForTwoEdits = 5
+	// MaxSpellingQuery is the longest query, in runes once normalized, that
+	// is spelt. Every correction is compared with the whole dictionary, so
+	// longer queries are not corrected at all.
+	MaxSpellingQuery = 100
+)
+
+type knownPhrase struct {
+	text  string
+	runes int
+	count int
+}
+
+// Speller suggests corrections for misspelt searches from a dictionary of
+// the titles and authors the service has seen. Register AddVolumes with
+// Catalog.OnAdd and RemoveVolumes with Catalog.OnEvict to keep it up to
+// date. It is safe for concurrent use.
+type Speller struct {
+	mu      sync.RWMutex
+	phrases map[string]*knownPhrase
+	words   map[string]int
+	// volumes holds the phrases each volume added.
+	volumes map[string][]string
+}
+
+func NewSpeller() *Speller {
+	return &Speller{phrases: map[string]*knownPhrase{}, words: map[string]int{}, volumes: map[string][]string{}}
+}
+
+// AddVolumes adds the title and authors of each volume to the dictionary. A
+// volume ID that has already been added is ignored.
+func (s *Speller) AddVolumes(items []model.GoogleBookItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		if _, ok := s.volumes[item.ID]; ok && item.ID != "" {
+			continue
+		}
+		keys := []string{}
+		for _, text := range append([]string{item.VolumeInfo.Title}, item.VolumeInfo.Authors...) {
+			words := tokenize(text)
+			if len(words) == 0 {
+				continue
+			}
+			key := strings.Join(words, " ")
+			if phrase, ok := s.phrases[key]; ok {
+				phrase.count++
+			} else {
+				s.phrases[key] = &knownPhrase{text: strings.TrimSpace(text), runes: utf8.RuneCountInString(key), count: 1}
+			}
+			for _, word := range words {
+				s.words[word]++
+			}
+			keys = append(keys, key)
+		}
+		if item.ID != "" {
+			s.volumes[item.ID] = keys
+		}
+	}
+}
+
+// RemoveVolumes takes the titles and authors of each volume ID back out of
+// the dictionary.
+func (s *Speller) RemoveVolumes(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		keys, ok := s.volumes[id]
+		if !ok {
+			continue
+		}
+		delete(s.volumes, id)
+		for _, key := range keys {
+			phrase := s.phrases[key]
+			if phrase.count--; phrase.count == 0 {
+				delete(s.phrases, key)
+			}
+			for _, word := range strings.Fields(key) {
+				if s.words[word]--; s.words[word] == 0 {
+					delete(s.words, word)
+				}
+			}
+		}
+	}
+}
+
+// Suggest returns up to limit corrections of query, best first. Known titles
+// and authors within a few edits of the whole query come first, followed by
+// the query with each unknown word replaced by its closest known word.
+// Queries longer than MaxSpellingQuery get no suggestions.
+func (s *Speller) Suggest(query string, limit int) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	words := tokenize(query)
+	normalized := strings.Join(words, " ")
+	length := utf8.RuneCountInString(normalized)
+	if normalized == "" || length > MaxSpellingQuery {
+		return []string{}
+	}
+
+	type candidate struct {
+		text     string
+		distance int
+		count    int
+	}
+	candidates := []candidate{}
+	maxEdits := min(max(length/4, 1), maxPhraseEdits)
+	for key, phrase := range s.phrases {
+		if abs(length-phrase.runes) > maxEdits {
+			continue
+		}
+		if distance := boundedEditDistance(normalized, key, maxEdits); distance > 0 && distance <= maxEdits {
+			candidates = append(candidates, candidate{text: phrase.text, distance: distance, count: phrase.count})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if c := cmp.Compare(a.distance, b.distance); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.text, b.text)
+	})
+
+	suggestions := []string{}
+	for _, c := range candidates {
+		suggestions = appendUnique(suggestions, c.text)
+	}
+	if corrected := s.correctWords(words); corrected != normalized && !slices.ContainsFunc(suggestions, func(text string) bool {
+		return strings.Join(tokenize(text), " ") == corrected
+	}) {
+		suggestions = append(suggestions, corrected)
+	}
+	return suggestions[:min(limit, len(suggestions))]
+}
+
+// correctWords replaces each word missing from the dictionary with the most
+// common known word one edit away, or two edits away for longer words.
+func (s *Speller) correctWords(words []string) string {
+	corrected := []string{}
+	for _, word := range words {
+		if s.words[word] == 0 {
+			candidates := s.known(edits(word))
+			// Generating every two-edit string is far slower than checking
+			// the dictionary directly
+			if length := utf8.RuneCountInString(word); len(candidates) == 0 && length >= minWordForTwoEdits {
+				for known := range s.words {
+					if abs(length-utf8.RuneCountInString(known)) <= 2 && boundedEditDistance(word, known, 2) == 2 {
+						candidates = append(candidates, known)
+					}
+				}
+			}
+			if best := s.mostCommon(candidates); best != "" {
+				word = best
+			}
+		}
+		corrected = append(corrected, word)
+	}
+	return strings.Join(corrected, " ")
+}
+
+func (s *Speller) known(words []string) []string {
+	known := []string{}
+	for _, word := range words {
+		if s.words[word] > 0 {
+			known = append(known, word)
+		}
+	}
+	return known
+}
+
+func (s *Speller) mostCommon(words []string) string {
+	best := ""
+	for _, word := range words {
+		if best == "" || s.words[word] > s.words[best] || (s.words[word] == s.words[best] && word < best) {
+			best = word
+		}
+	}
+	return best
+}
+
+// edits returns every string one deletion, transposition, substitution or
+// insertion away from word.
+func edits(word string) []string {
+	runes := []rune(word)
+	results := []string{}
+	for i := 0; i <= len(runes); i++ {
+		left, right := string(runes[:i]), runes[i:]
+		if len(right) > 0 {
+			results = append(results, left+string(right[1:]))
+		}
+		if len(right) > 1 {
+			results = append(results, left+string(right[1])+string(right[0])+string(right[2:]))
+		}
+		for _, r := range spellingAlphabet {
+			if len(right) > 0 {
+				results = append(results, left+string(r)+string(right[1:]))
+			}
+			results = append(results, left+string(r)+string(right))
+		}
+	}
+	return results
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and transpositions of adjacent runes
+// each cost one.
+func editDistance(a string, b string) int {
+	return boundedEditDistance(a, b, max(utf8.RuneCountInString(a), utf8.RuneCountInString(b)))
+}
+
+// boundedEditDistance is editDistance when it is at most limit and limit+1
+// otherwise, giving up as soon as every alignment costs more than limit.
+func boundedEditDistance(a string, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > limit {
+		return limit + 1
+	}
+	// Only the previous two rows are needed for transpositions
+	before, previous, current := make([]int, len(rb)+1), make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		best := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				current[j] = min(current[j], before[j-2]+1)
+			}
+			best = min(best, current[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		before, previous, current = previous, current, before
+	}
+	return min(previous[len(rb)], limit+1)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func pactSpeller(t *testing.T) *Speller {
+	catalog := client.NewCatalog()
+	speller := NewSpeller()
+	catalog.OnAdd(speller.AddVolumes)
+	bc := client.GoogleBookClient{PactMode: true, Catalog: catalog}
+	_, err := bc.ByAuthor(context.Background(), client.GoogleBookRequest{Author: "William Gibson"})
+	assert.NoError(t, err)
+	_, err = bc.ByTitle(context.Background(), client.GoogleBookRequest{Title: "Count Zero"})
+	assert.NoError(t, err)
+	return speller
+}
+
+func TestSpeller_Suggest(t *testing.T) {
+	speller := pactSpeller(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "transposed letters", query: "Cuont Zeor", expected: "Count Zero"},
+		{name: "missing letter", query: "Leters to My Son", expected: "Letters to My Son"},
+		{name: "extra letter", query: "Two for the Seesaww", expected: "Two for the Seesaw"},
+		{name: "british spelling", query: "Distrust that Particular Flavour", expected: "Distrust that Particular Flavor"},
+		{name: "wrong letter", query: "Goodly Creatores", expected: "Goodly Creatures"},
+		{name: "misspelt author", query: "Willaim Gibsen", expected: "William Gibson"},
+		{name: "misspelt word in a long title", query: "boyolgy", expected: "boyology"},
+		{name: "misspelt words among correct ones", query: "reminiscences of dolar", expected: "reminiscences of dollar"},
+		{name: "accented typo", query: "Coünt Zerro", expected: "Count Zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := speller.Suggest(tt.query, 3)
+			if assert.NotEmpty(t, got) {
+				assert.Equal(t, tt.expected, got[0])
+			}
+		})
+	}
+}
+
+func TestSpeller_Suggest_nothingToCorrect(t *testing.T) {
+	speller := pactSpeller(t)
+
+	assert.Empty(t, speller.Suggest("Count Zero", 3))
+	assert.Empty(t, speller.Suggest("neuromancer", 3))
+	assert.Empty(t, speller.Suggest("", 3))
+	assert.Len(t, speller.Suggest("Leters", 1), 1)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("zero", "zero"))
+	assert.Equal(t, 1, editDistance("zeor", "zero"))
+	assert.Equal(t, 1, editDistance("zer", "zero"))
+	assert.Equal(t, 2, editDistance("cuont zeor", "count zero"))
+	assert.Equal(t, 4, editDistance("", "zero"))
+}
+
+func TestBoundedEditDistance(t *testing.T) {
+	assert.Equal(t, 2, boundedEditDistance("cuont zeor", "count zero", 2))
+	assert.Equal(t, 2, boundedEditDistance("cuont zeor", "count zero", 1))
+	assert.Equal(t, 3, boundedEditDistance("neuromancer", "count zero", 2))
+	assert.Equal(t, 2, boundedEditDistance("zero", "zero history", 1))
+}
+
+func TestSpeller_Suggest_longQuery(t *testing.T) {
+	speller := pactSpeller(t)
+
+	assert.Empty(t, speller.Suggest(strings.Repeat("cuont zeor ", MaxSpellingQuery), 3))
+}
@@ -0,0 +1,420 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+)
+
+const (
+	KindTitle  = "title"
+	KindAuthor = "author"
+)
+
+// MaxSuggestions is the most suggestions Suggest returns. Each trie node
+// keeps only this many of the best suggestions below it, so a short prefix
+// is answered without walking its subtree.
+const MaxSuggestions = 20
+
+// Suggestion is a title or author completing a prefix.
+type Suggestion struct {
+	Text       string `json:"text"`
+	Kind       string `json:"kind"`
+	ID         string `json:"id,omitempty"`
+	Popularity int    `json:"popularity"`
+}
+
+type suggestion struct {
+	Suggestion
+	key string
+	// volumes counts the volumes offering the suggestion and languages the
+	// ones in each language.
+	volumes   int
+	languages map[string]int
+	// nodes are the trie nodes on every path to the suggestion, whose top
+	// lists must be updated when it becomes more popular.
+	nodes []*trieNode
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// entries are the suggestions whose path ends at the node.
+	entries []*suggestion
+	// top and topByLanguage hold the best suggestions in the node's subtree,
+	// in order, up to MaxSuggestions.
+	top           []*suggestion
+	topByLanguage map[string][]*suggestion
+}
+
+// Suggester completes prefixes of the titles and authors the service has
+// seen, most requested first. Every word of a title or name starts a path in
+// the trie, so "zero" completes "Count Zero". Register AddVolumes with
+// Catalog.OnAdd and RemoveVolumes with Catalog.OnEvict to keep it up to date
+// and wrap the book client with Track to count requests. It is safe for
+// concurrent use.
+type Suggester struct {
+	mu          sync.RWMutex
+	root        *trieNode
+	suggestions map[string]*suggestion
+	// volumes holds the suggestions and language each volume added.
+	volumes map[string]suggesterVolume
+}
+
+type suggesterVolume struct {
+	keys     []string
+	language string
+}
+
+func NewSuggester() *Suggester {
+	return &Suggester{root: &trieNode{}, suggestions: map[string]*suggestion{}, volumes: map[string]suggesterVolume{}}
+}
+
+// AddVolumes adds the title and authors of each volume as suggestions. A
+// volume ID that has already been added is ignored.
+func (s *Suggester) AddVolumes(items []model.GoogleBookItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		if _, ok := s.volumes[item.ID]; ok && item.ID != "" {
+			continue
+		}
+		volume := suggesterVolume{language: baseLanguage(item.VolumeInfo.Language)}
+		keys := []string{s.add(KindTitle, item.VolumeInfo.Title, item.ID, volume.language)}
+		for _, author := range item.VolumeInfo.Authors {
+			keys = append(keys, s.add(KindAuthor, author, "", volume.language))
+		}
+		volume.keys = slices.DeleteFunc(keys, func(key string) bool { return key == "" })
+		if item.ID != "" {
+			s.volumes[item.ID] = volume
+		}
+	}
+}
+
+// RemoveVolumes takes back the suggestions each volume ID added. Titles and
+// authors no remaining volume offers are dropped along with their prefixes.
+func (s *Suggester) RemoveVolumes(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		volume, ok := s.volumes[id]
+		if !ok {
+			continue
+		}
+		delete(s.volumes, id)
+		for _, key := range volume.keys {
+			s.remove(s.suggestions[key], volume.language)
+		}
+	}
+}
+
+func (s *Suggester) add(kind string, text string, id string, language string) string {
+	key := suggestionKey(kind, text)
+	if key == "" {
+		return ""
+	}
+	entry, ok := s.suggestions[key]
+	if !ok {
+		entry = &suggestion{
+			Suggestion: Suggestion{Text: strings.TrimSpace(text), Kind: kind, ID: id},
+			key:        key,
+			languages:  map[string]int{},
+		}
+		s.suggestions[key] = entry
+		for _, path := range suggestionPaths(text) {
+			s.root.insert(path, entry)
+		}
+		for _, node := range entry.nodes {
+			node.top = offer(node.top, entry)
+		}
+	}
+	entry.volumes++
+	if language != "" {
+		entry.languages[language]++
+		if entry.languages[language] == 1 {
+			for _, node := range entry.nodes {
+				if node.topByLanguage == nil {
+					node.topByLanguage = map[string][]*suggestion{}
+				}
+				node.topByLanguage[language] = offer(node.topByLanguage[language], entry)
+			}
+		}
+	}
+	return key
+}
+
+// remove takes one volume in language away from the entry, dropping it from
+// the language's top lists or from the trie when no volume is left.
+func (s *Suggester) remove(entry *suggestion, language string) {
+	if language != "" {
+		entry.languages[language]--
+		if entry.languages[language] == 0 {
+			delete(entry.languages, language)
+			for _, node := range entry.nodes {
+				node.topByLanguage[language] = node.withdraw(node.topByLanguage[language], entry, language)
+				if len(node.topByLanguage[language]) == 0 {
+					delete(node.topByLanguage, language)
+				}
+			}
+		}
+	}
+	entry.volumes--
+	if entry.volumes > 0 {
+		return
+	}
+	delete(s.suggestions, entry.key)
+	for _, node := range entry.nodes {
+		node.top = node.withdraw(node.top, entry, "")
+	}
+	for _, path := range suggestionPaths(entry.Text) {
+		s.root.prune(path, entry)
+	}
+}
+
+// suggestionPaths returns the text from each of its words to the end, the
+// paths under which the trie files it.
+func suggestionPaths(text string) []string {
+	words := tokenize(text)
+	paths := []string{}
+	for i := range words {
+		paths = append(paths, strings.Join(words[i:], " "))
+	}
+	return paths
+}
+
+// insert adds the nodes spelling text to the entry's nodes, creating them as
+// needed, and files the entry at the last one. The root matches no prefix
+// and is left out.
+func (n *trieNode) insert(text string, entry *suggestion) {
+	node := n
+	for _, r := range text {
+		if node.children == nil {
+			node.children = map[rune]*trieNode{}
+		}
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+		if !slices.Contains(entry.nodes, node) {
+			entry.nodes = append(entry.nodes, node)
+		}
+	}
+	if !slices.Contains(node.entries, entry) {
+		node.entries = append(node.entries, entry)
+	}
+}
+
+// prune unfiles the entry from the end of the path and deletes the nodes
+// along it that no longer lead to any suggestion. The entry must already be
+// out of the nodes' top lists.
+func (n *trieNode) prune(text string, entry *suggestion) {
+	path := []*trieNode{n}
+	for _, r := range text {
+		child := path[len(path)-1].children[r]
+		if child == nil {
+			return
+		}
+		path = append(path, child)
+	}
+	last := path[len(path)-1]
+	last.entries = slices.DeleteFunc(last.entries, func(other *suggestion) bool { return other == entry })
+	runes := []rune(text)
+	for i := len(path) - 1; i > 0; i-- {
+		if len(path[i].top) > 0 {
+			return
+		}
+		delete(path[i-1].children, runes[i-1])
+	}
+}
+
+// withdraw removes the entry from one of the node's top lists. A full list
+// may have left out suggestions that now belong on it, so it is rebuilt from
+// the subtree, keeping only suggestions in language when it is set.
+func (n *trieNode) withdraw(top []*suggestion, entry *suggestion, language string) []*suggestion {
+	i := slices.Index(top, entry)
+	if i < 0 {
+		return top
+	}
+	if len(top) < MaxSuggestions {
+		return slices.Delete(top, i, i+1)
+	}
+	top = nil
+	n.walk(func(other *suggestion) {
+		if other != entry && (language == "" || other.languages[language] > 0) && !slices.Contains(top, other) {
+			top = offer(top, other)
+		}
+	})
+	return top
+}
+
+// walk calls fn for every entry filed in the node's subtree.
+func (n *trieNode) walk(fn func(*suggestion)) {
+	for _, entry := range n.entries {
+		fn(entry)
+	}
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+// offer places the entry in a top list, moving it up if it is already
+// there. Popularity only grows, so an entry pushed off a list never needs to
+// come back until it is offered again or an entry above it is withdrawn.
+func offer(top []*suggestion, entry *suggestion) []*suggestion {
+	if i := slices.Index(top, entry); i >= 0 {
+		top = slices.Delete(top, i, i+1)
+	}
+	i, _ := slices.BinarySearchFunc(top, entry, compareSuggestions)
+	if i >= MaxSuggestions {
+		return top
+	}
+	top = slices.Insert(top, i, entry)
+	return top[:min(len(top), MaxSuggestions)]
+}
+
+// compareSuggestions orders the most popular first, then shorter and
+// alphabetically earlier text.
+func compareSuggestions(a, b *suggestion) int {
+	if c := cmp.Compare(b.Popularity, a.Popularity); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(utf8.RuneCountInString(a.Text), utf8.RuneCountInString(b.Text)); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Text, b.Text); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Kind, b.Kind)
+}
+
+// Record counts a request for each title and author in the volumes. Editions
+// of the same title in one response count once.
+func (s *Suggester) Record(items []model.GoogleBookItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counted := map[string]bool{}
+	for _, item := range items {
+		keys := []string{suggestionKey(KindTitle, item.VolumeInfo.Title)}
+		for _, author := range item.VolumeInfo.Authors {
+			keys = append(keys, suggestionKey(KindAuthor, author))
+		}
+		for _, key := range keys {
+			if entry, ok := s.suggestions[key]; ok && !counted[key] {
+				entry.Popularity++
+				counted[key] = true
+				for _, node := range entry.nodes {
+					node.top = offer(node.top, entry)
+					for language := range entry.languages {
+						node.topByLanguage[language] = offer(node.topByLanguage[language], entry)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Suggest returns up to limit titles and authors with a word starting with
+// prefix, most popular first, and never more than MaxSuggestions. When
+// language is set only suggestions from volumes in that language are
+// returned.
+func (s *Suggester) Suggest(prefix string, language string, limit int) []Suggestion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	prefix = normalizePrefix(prefix)
+	language = baseLanguage(language)
+	if prefix == "" {
+		return []Suggestion{}
+	}
+
+	node := s.root
+	for _, r := range prefix {
+		if node = node.children[r]; node == nil {
+			return []Suggestion{}
+		}
+	}
+	top := node.top
+	if language != "" {
+		top = node.topByLanguage[language]
+	}
+
+	suggestions := []Suggestion{}
+	for _, entry := range top[:min(limit, len(top))] {
+		suggestions = append(suggestions, entry.Suggestion)
+	}
+	return suggestions
+}
+
+// Track wraps a book client so that every volume it returns counts towards
+// the popularity of its title and authors.
+func (s *Suggester) Track(api client.BookClientInterface) client.BookClientInterface {
+	return trackingClient{api: api, suggester: s}
+}
+
+type trackingClient struct {
+	api       client.BookClientInterface
+	suggester *Suggester
+}
+
+func (c trackingClient) ByAuthor(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	resp, err := c.api.ByAuthor(ctx, request)
+	if err == nil {
+		c.suggester.Record(resp.Items)
+	}
+	return resp, err
+}
+
+func (c trackingClient) ByTitle(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	resp, err := c.api.ByTitle(ctx, request)
+	if err == nil {
+		c.suggester.Record(resp.Items)
+	}
+	return resp, err
+}
+
+func (c trackingClient) ByQuery(ctx context.Context, request client.GoogleBookRequest) (model.GoogleBookResponse, error) {
+	resp, err := c.api.ByQuery(ctx, request)
+	if err == nil {
+		c.suggester.Record(resp.Items)
+	}
+	return resp, err
+}
+
+func (c trackingClient) ByID(ctx context.Context, id string) (model.GoogleBookItem, error) {
+	book, err := c.api.ByID(ctx, id)
+	if err == nil {
+		c.suggester.Record([]model.GoogleBookItem{book})
+	}
+	return book, err
+}
+
+func suggestionKey(kind string, text string) string {
+	words := tokenize(text)
+	if len(words) == 0 {
+		return ""
+	}
+	return kind + "|" + strings.Join(words, " ")
+}
+
+// normalizePrefix lowercases text and collapses punctuation and whitespace
+// into single spaces, keeping a trailing space so "count " only completes
+// the whole word.
+func normalizePrefix(text string) string {
+	normalized := strings.Join(tokenize(text), " ")
+	if normalized != "" && strings.TrimRightFunc(text, isSeparator) != text {
+		normalized += " "
+	}
+	return normalized
+}
+
+// baseLanguage reduces a language tag such as "en-GB" to "en".
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	return base
+}
@@ -0,0 +1,150 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	client "example.com/book-learn/clients"
+	model "example.com/book-learn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func suggestionTexts(suggestions []Suggestion) []string {
+	texts := []string{}
+	for _, suggestion := range suggestions {
+		texts = append(texts, suggestion.Text)
+	}
+	return texts
+}
+
+func TestSuggester_Suggest(t *testing.T) {
+	suggester := NewSuggester()
+	suggester.AddVolumes([]model.GoogleBookItem{
+		{ID: "count-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Count Zero", Authors: []string{"William Gibson"}, Language: "en"}},
+		{ID: "comte-zero", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Comte Zéro", Authors: []string{"William Gibson"}, Language: "fr"}},
+		{ID: "counsel", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Good Counsel", Authors: []string{"William GIBSON (Quaker, of London.)"}, Language: "en-GB"}},
+		{ID: "neuromancer", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Neuromancer", Authors: []string{"William Gibson"}, Language: "en"}},
+	})
+
+	tests := []struct {
+		name     string
+		prefix   string
+		language string
+		expected []string
+	}{
+		{
+			name:     "prefix of the first word",
+			prefix:   "Cou",
+			expected: []string{"Count Zero", "Good Counsel"},
+		},
+		{
+			name:     "prefix of a later word",
+			prefix:   "zer",
+			expected: []string{"Comte Zéro", "Count Zero"},
+		},
+		{
+			name:     "authors and titles",
+			prefix:   "gib",
+			expected: []string{"William Gibson", "William GIBSON (Quaker, of London.)"},
+		},
+		{
+			name:     "trailing space completes the whole word only",
+			prefix:   "count ",
+			expected: []string{"Count Zero"},
+		},
+		{
+			name:     "language",
+			prefix:   "co",
+			language: "fr",
+			expected: []string{"Comte Zéro"},
+		},
+		{
+			name:     "regional language tag",
+			prefix:   "wil",
+			language: "en-US",
+			expected: []string{"William Gibson", "William GIBSON (Quaker, of London.)"},
+		},
+		{
+			name:     "no match",
+			prefix:   "idoru",
+			expected: []string{},
+		},
+		{
+			name:     "only punctuation",
+			prefix:   " - ",
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, suggestionTexts(suggester.Suggest(tt.prefix, tt.language, 10)))
+		})
+	}
+}
+
+func TestSuggester_Record(t *testing.T) {
+	suggester := NewSuggester()
+	catalog := client.NewCatalog()
+	catalog.OnAdd(suggester.AddVolumes)
+	api := suggester.Track(client.GoogleBookClient{PactMode: true, Catalog: catalog})
+
+	_, err := api.ByAuthor(context.Background(), client.GoogleBookRequest{Author: "William Gibson"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Letters to My Son", "Lectures and essays on various subjects, historical, topographical, and artistic"}, suggestionTexts(suggester.Suggest("le", "", 2)))
+
+	_, err = api.ByID(context.Background(), "rJICAAAAQAAJ")
+	assert.NoError(t, err)
+	got := suggester.Suggest("le", "", 2)
+	assert.Equal(t, []Suggestion{
+		{Text: "Lectures and essays on various subjects, historical, topographical, and artistic", Kind: KindTitle, ID: "rJICAAAAQAAJ", Popularity: 2},
+		{Text: "Letters to My Son", Kind: KindTitle, ID: "IYUQLS0SnpEC", Popularity: 1},
+	}, got)
+}
+
+func TestSuggester_Suggest_topList(t *testing.T) {
+	suggester := NewSuggester()
+	items := []model.GoogleBookItem{}
+	for i := 0; i < MaxSuggestions+5; i++ {
+		items = append(items, model.GoogleBookItem{ID: fmt.Sprint(i), VolumeInfo: model.GoogleBookVolumeInfo{Title: fmt.Sprintf("Volume %02d", i), Language: "en"}})
+	}
+	suggester.AddVolumes(items)
+
+	got := suggester.Suggest("vol", "", 100)
+	assert.Len(t, got, MaxSuggestions)
+	assert.Equal(t, "Volume 00", got[0].Text)
+	assert.Len(t, suggester.root.children['v'].top, MaxSuggestions)
+
+	// A volume outside the kept list moves in once it is requested
+	last := items[len(items)-1]
+	suggester.Record([]model.GoogleBookItem{last})
+	assert.Equal(t, "Volume 24", suggester.Suggest("vol", "", 1)[0].Text)
+	assert.Equal(t, "Volume 24", suggester.Suggest("24", "en", 1)[0].Text)
+	assert.Equal(t, "Volume 24", suggester.Suggest("v", "en", 1)[0].Text)
+	assert.Empty(t, suggester.Suggest("v", "fr", 1))
+}
+
+func TestSuggester_RemoveVolumes(t *testing.T) {
+	suggester := NewSuggester()
+	items := []model.GoogleBookItem{}
+	for i := 0; i < MaxSuggestions+1; i++ {
+		items = append(items, model.GoogleBookItem{ID: fmt.Sprint(i), VolumeInfo: model.GoogleBookVolumeInfo{Title: fmt.Sprintf("Volume %02d", i), Language: "en"}})
+	}
+	// A second edition keeps the title suggested when the first goes
+	items = append(items, model.GoogleBookItem{ID: "reissue", VolumeInfo: model.GoogleBookVolumeInfo{Title: "Volume 01", Language: "fr"}})
+	suggester.AddVolumes(items)
+
+	// The suggestion pushed off the full list comes back in place of the one removed
+	suggester.RemoveVolumes([]string{"0"})
+	got := suggester.Suggest("vol", "en", 100)
+	assert.Len(t, got, MaxSuggestions)
+	assert.Equal(t, "Volume 01", got[0].Text)
+	assert.Equal(t, "Volume 20", got[len(got)-1].Text)
+	assert.Empty(t, suggester.Suggest("00", "", 1))
+	assert.NotContains(t, suggester.root.children['0'].children, '0')
+
+	suggester.RemoveVolumes([]string{"1"})
+	assert.Equal(t, "Volume 01", suggester.Suggest("vol", "", 1)[0].Text)
+	assert.Equal(t, "Volume 02", suggester.Suggest("vol", "en", 1)[0].Text)
+	assert.Equal(t, "Volume 01", suggester.Suggest("vol", "fr", 1)[0].Text)
+}
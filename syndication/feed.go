@@ -0,0 +1,196 @@
+// Package syndication writes Atom 1.0 and RSS 2.0 feeds, for people who
+// follow new books in a feed reader.
+package syndication
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const (
+	AtomMediaType = "application/atom+xml"
+	RSSMediaType  = "application/rss+xml"
+
+	atomNamespace       = "http://www.w3.org/2005/Atom"
+	dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+)
+
+// Feed is what both formats have in common. Self is the feed's own URL and
+// Link the page it describes. TTL tells RSS readers how long to wait before
+// polling again.
+type Feed struct {
+	ID          string
+	Title       string
+	Description string
+	Author      string
+	Self        string
+	Link        string
+	Updated     time.Time
+	TTL         time.Duration
+	Entries     []Entry
+}
+
+// Entry is an item in a feed. ID must not change between fetches, or readers
+// show the entry as new again.
+type Entry struct {
+	ID         string
+	Title      string
+	Link       string
+	Updated    time.Time
+	Authors    []string
+	Summary    string
+	Categories []string
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   atomPerson  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomPerson   `xml:"author"`
+	Link       atomLink       `xml:"link"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom writes the feed as Atom 1.0. Feed.Author stands in for entries
+// that have no authors of their own, as the format requires one for each.
+func (f Feed) WriteAtom(w io.Writer) error {
+	feed := atomFeed{
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Author:   atomPerson{Name: f.Author},
+		Links: []atomLink{
+			{Rel: "self", Href: f.Self, Type: AtomMediaType},
+			{Rel: "alternate", Href: f.Link},
+		},
+	}
+	for _, entry := range f.Entries {
+		atom := atomEntry{
+			ID:      entry.ID,
+			Title:   entry.Title,
+			Updated: entry.Updated.UTC().Format(time.RFC3339),
+			Link:    atomLink{Rel: "alternate", Href: entry.Link, Type: "text/html"},
+		}
+		for _, author := range entry.Authors {
+			atom.Authors = append(atom.Authors, atomPerson{Name: author})
+		}
+		if entry.Summary != "" {
+			atom.Summary = &atomText{Type: "text", Value: entry.Summary}
+		}
+		for _, category := range entry.Categories {
+			atom.Categories = append(atom.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, atom)
+	}
+	return writeXML(w, feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl,omitempty"`
+	Self          rssSelf   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creators    []string `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes the feed as RSS 2.0. Authors go in dc:creator, because the
+// RSS author element must be an email address.
+func (f Feed) WriteRSS(w io.Writer) error {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		TTL:           int(f.TTL.Minutes()),
+		Self:          rssSelf{Href: f.Self, Rel: "self", Type: RSSMediaType},
+	}
+	for _, entry := range f.Entries {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Summary,
+			GUID:        rssGUID{Value: entry.ID},
+			PubDate:     entry.Updated.UTC().Format(time.RFC1123Z),
+			Creators:    entry.Authors,
+			Categories:  entry.Categories,
+		})
+	}
+	return writeXML(w, rssFeed{Version: "2.0", Atom: atomNamespace, DC: dublinCoreNamespace, Channel: channel})
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,108 @@
+package syndication
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed/atom"
+	"github.com/mmcdole/gofeed/rss"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFeed = Feed{
+	ID:          "http://localhost/api/authors/William%20Gibson/feed.atom",
+	Title:       "New books by William Gibson",
+	Description: "The newest books by William Gibson.",
+	Author:      "William Gibson",
+	Self:        "http://localhost/api/authors/William%20Gibson/feed.atom",
+	Link:        "http://localhost/api/authors/William%20Gibson",
+	Updated:     time.Date(2020, 1, 21, 0, 0, 0, 0, time.UTC),
+	TTL:         30 * time.Minute,
+	Entries: []Entry{
+		{
+			ID:         "https://books.google.com/books?id=agency",
+			Title:      "Agency",
+			Link:       "https://books.example/agency?id=1&hl=en",
+			Updated:    time.Date(2020, 1, 21, 0, 0, 0, 0, time.UTC),
+			Authors:    []string{"William Gibson"},
+			Summary:    "Verity Jane & an AI <called> Eunice.",
+			Categories: []string{"Fiction"},
+		},
+		{
+			ID:      "https://books.google.com/books?id=zero",
+			Title:   "Count Zero",
+			Link:    "https://books.example/zero",
+			Updated: time.Date(1986, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	},
+}
+
+func TestFeed_WriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testFeed.WriteAtom(&buf))
+
+	feed, err := (&atom.Parser{}).Parse(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, testFeed.ID, feed.ID)
+	assert.Equal(t, "New books by William Gibson", feed.Title)
+	assert.Equal(t, "2020-01-21T00:00:00Z", feed.Updated)
+	assert.Equal(t, "William Gibson", feed.Authors[0].Name)
+	assert.Equal(t, "self", feed.Links[0].Rel)
+	assert.Equal(t, testFeed.Self, feed.Links[0].Href)
+	assert.Equal(t, AtomMediaType, feed.Links[0].Type)
+
+	assert.Len(t, feed.Entries, 2)
+	entry := feed.Entries[0]
+	assert.Equal(t, "https://books.google.com/books?id=agency", entry.ID)
+	assert.Equal(t, "Agency", entry.Title)
+	assert.Equal(t, "2020-01-21T00:00:00Z", entry.Updated)
+	assert.Equal(t, "https://books.example/agency?id=1&hl=en", entry.Links[0].Href)
+	assert.Equal(t, "alternate", entry.Links[0].Rel)
+	assert.Equal(t, "Verity Jane & an AI <called> Eunice.", entry.Summary)
+	assert.Equal(t, "Fiction", entry.Categories[0].Term)
+	// Entries without authors fall back to the feed's
+	assert.Empty(t, feed.Entries[1].Authors)
+
+	// Every element lives in the Atom namespace
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Entries []struct {
+			ID string `xml:"http://www.w3.org/2005/Atom id"`
+		} `xml:"http://www.w3.org/2005/Atom entry"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	assert.Equal(t, "https://books.google.com/books?id=zero", parsed.Entries[1].ID)
+}
+
+func TestFeed_WriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testFeed.WriteRSS(&buf))
+
+	feed, err := (&rss.Parser{}).Parse(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0", feed.Version)
+	assert.Equal(t, "New books by William Gibson", feed.Title)
+	assert.Equal(t, testFeed.Link, feed.Link)
+	assert.Equal(t, "The newest books by William Gibson.", feed.Description)
+	assert.Equal(t, "Tue, 21 Jan 2020 00:00:00 +0000", feed.LastBuildDate)
+	assert.Equal(t, "30", feed.TTL)
+	assert.Equal(t, testFeed.Self, feed.Extensions["atom"]["link"][0].Attrs["href"])
+
+	assert.Len(t, feed.Items, 2)
+	item := feed.Items[0]
+	assert.Equal(t, "Agency", item.Title)
+	assert.Equal(t, "https://books.example/agency?id=1&hl=en", item.Link)
+	assert.Equal(t, "Verity Jane & an AI <called> Eunice.", item.Description)
+	assert.Equal(t, "https://books.google.com/books?id=agency", item.GUID.Value)
+	// gofeed misspells isPermaLink, so check the attribute on the raw item
+	assert.Contains(t, buf.String(), `<guid isPermaLink="false">https://books.google.com/books?id=agency</guid>`)
+	assert.Equal(t, "Tue, 21 Jan 2020 00:00:00 +0000", item.PubDate)
+	assert.Equal(t, []string{"William Gibson"}, item.DublinCoreExt.Creator)
+	assert.Equal(t, "Fiction", item.Categories[0].Value)
+
+	_, err = time.Parse(time.RFC1123Z, feed.Items[1].PubDate)
+	assert.NoError(t, err)
+	assert.Empty(t, feed.Items[1].Description)
+}